@@ -0,0 +1,452 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/samuel032khoury/gopherfeed/internal/store"
+	"github.com/samuel032khoury/gopherfeed/internal/utils"
+)
+
+// UnlockUser godoc
+//
+//	@Summary		Unlock a user account
+//	@Description	Clear failed login and MFA attempts so a locked out user can sign in again
+//	@Tags			admin
+//	@Produce		json
+//	@Param			userID	path		int	true	"User ID"
+//	@Success		204		{object}	nil	"Account unlocked"
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/admin/users/{userID}/unlock [post]
+func (app *application) unlockUserHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if err := app.unlockAccount(r.Context(), user); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.logger.Infow("account unlocked", "user_id", user.ID, "by", getCurrentUserFromContext(r).ID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// impersonatePayload represents the payload for starting an impersonation
+//
+//	@Description	Impersonation request; the reason is kept in the audit log
+type impersonatePayload struct {
+	Reason string `json:"reason" validate:"required,max=500" example:"Investigating support ticket #1234"`
+}
+
+// impersonationResponse represents a newly started impersonation session
+//
+//	@Description	Impersonation access token, to be sent as a bearer token
+type impersonationResponse struct {
+	Token     string `json:"token" example:"eyJhbGciOiJIUzI1NiIs..."`
+	SessionID string `json:"session_id" example:"7f9c2d4e-1b3a-4c5d-8e6f-0a1b2c3d4e5f"`
+	ExpiresAt string `json:"expires_at" example:"2026-01-06T07:52:18Z"`
+}
+
+// ImpersonateUser godoc
+//
+//	@Summary		Impersonate a user
+//	@Description	Start a short-lived session acting as the given user. Only users with a lower role can be impersonated. Responses to the returned token carry an X-Impersonated-By header, and sensitive account actions are refused.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			userID	path		int										true	"User ID"
+//	@Param			payload	body		impersonatePayload						true	"Impersonation payload"
+//	@Success		201		{object}	DataResponse[impersonationResponse]	"Impersonation started"
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/admin/users/{userID}/impersonate [post]
+func (app *application) impersonateUserHandler(w http.ResponseWriter, r *http.Request) {
+	var payload impersonatePayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	admin := getCurrentUserFromContext(r)
+	target := getUserFromContext(r)
+	if target.ID == admin.ID {
+		app.badRequestError(w, r, fmt.Errorf("cannot impersonate yourself"))
+		return
+	}
+	if !target.IsActive || target.DeactivatedAt != nil {
+		app.badRequestError(w, r, fmt.Errorf("user is not active"))
+		return
+	}
+	allowed, err := app.outranks(ctx, admin, target)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if !allowed {
+		app.forbiddenError(w, r)
+		return
+	}
+
+	// The refresh token is never handed out, so the session cannot outlive
+	// its access token.
+	session := &store.Session{
+		ID:             uuid.New().String(),
+		UserID:         target.ID,
+		UserAgent:      r.UserAgent(),
+		IP:             clientIP(r),
+		AccessJTI:      uuid.New().String(),
+		ImpersonatorID: &admin.ID,
+	}
+	metadata := map[string]any{
+		"session_id": session.ID,
+		"reason":     payload.Reason,
+	}
+	audit := func() *store.AuditEntry {
+		return auditEntry(r, admin.ID, "impersonation.start", "user", strconv.FormatInt(target.ID, 10), metadata)
+	}
+	if err := app.store.Sessions.Create(ctx, session, utils.Hash(uuid.New().String()), utils.ImpersonationExpiry, audit); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	token, err := app.generateImpersonationToken(target.ID, admin.ID, session.ID, session.AccessJTI, utils.ImpersonationExpiry)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	response := impersonationResponse{
+		Token:     token,
+		SessionID: session.ID,
+		ExpiresAt: session.ExpiresAt,
+	}
+	app.jsonResponse(w, response, http.StatusCreated)
+}
+
+// StopImpersonation godoc
+//
+//	@Summary		Stop an impersonation
+//	@Description	End an impersonation session started by the current admin before it expires
+//	@Tags			admin
+//	@Produce		json
+//	@Param			sessionID	path		string	true	"Impersonation session ID"
+//	@Success		204			{object}	nil		"Impersonation ended"
+//	@Failure		401			{object}	ErrorResponse
+//	@Failure		403			{object}	ErrorResponse
+//	@Failure		404			{object}	ErrorResponse
+//	@Failure		500			{object}	ErrorResponse
+//	@Router			/admin/impersonations/{sessionID} [delete]
+func (app *application) stopImpersonationHandler(w http.ResponseWriter, r *http.Request) {
+	admin := getCurrentUserFromContext(r)
+	sessionID := chi.URLParam(r, "sessionID")
+	audit := func() *store.AuditEntry {
+		return auditEntry(r, admin.ID, "impersonation.stop", "session", sessionID, nil)
+	}
+	if err := app.store.Sessions.RevokeImpersonation(r.Context(), sessionID, admin.ID, audit); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundError(w, r)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListUsers godoc
+//
+//	@Summary		List users
+//	@Description	List users, including inactive ones, with optional filters
+//	@Tags			admin
+//	@Produce		json
+//	@Param			limit	query		int		false	"Number of items per page (1-100)"		example(20)
+//	@Param			offset	query		int		false	"Number of items to skip"				example(0)
+//	@Param			role	query		string	false	"Only users with this role"				example(moderator)
+//	@Param			active	query		bool	false	"Only users who can or cannot sign in"	example(true)
+//	@Param			search	query		string	false	"Search in username and email"			example(john)
+//	@Success		200		{object}	DataResponse[[]store.User]
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/admin/users [get]
+func (app *application) listUsersHandler(w http.ResponseWriter, r *http.Request) {
+	filter := &store.UserFilter{
+		Limit:  20,
+		Offset: 0,
+	}
+	filter, err := filter.Parse(r)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(filter); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	users, err := app.store.Users.List(r.Context(), filter)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, users, http.StatusOK)
+}
+
+// assignRolePayload represents the payload for changing a user's role
+//
+//	@Description	Role assignment payload
+type assignRolePayload struct {
+	Role string `json:"role" validate:"required,max=255" example:"moderator"`
+}
+
+// AssignRole godoc
+//
+//	@Summary		Assign a role
+//	@Description	Change a user's role. Only users and roles less privileged than your own can be managed.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			userID	path		int					true	"User ID"
+//	@Param			payload	body		assignRolePayload	true	"Role to assign"
+//	@Success		204		{object}	nil					"Role assigned"
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/admin/users/{userID}/role [put]
+func (app *application) assignRoleHandler(w http.ResponseWriter, r *http.Request) {
+	var payload assignRolePayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	actor := getCurrentUserFromContext(r)
+	target := getUserFromContext(r)
+	if !app.canManageUser(w, r, actor, target) {
+		return
+	}
+	role, err := app.store.Roles.GetByName(ctx, payload.Role)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if role == nil {
+		app.badRequestError(w, r, fmt.Errorf("unknown role %q", payload.Role))
+		return
+	}
+	if !app.canManageRoleLevel(w, r, actor, role.Level) {
+		return
+	}
+
+	before := map[string]any{"role_id": target.RoleID}
+	after := map[string]any{"role_id": role.ID}
+	audit := func() *store.AuditEntry {
+		return auditChangeEntry(r, actor.ID, "user.role_change", "user", strconv.FormatInt(target.ID, 10), before, after)
+	}
+	if err := app.store.Users.SetRole(ctx, target.ID, role.ID, audit); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.invalidateUser(ctx, target.ID); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ActivateUser godoc
+//
+//	@Summary		Activate a user
+//	@Description	Lift a deactivation. An account whose invitation was never accepted is activated as well.
+//	@Tags			admin
+//	@Produce		json
+//	@Param			userID	path		int	true	"User ID"
+//	@Success		204		{object}	nil	"Account activated"
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/admin/users/{userID}/activate [post]
+func (app *application) adminActivateUserHandler(w http.ResponseWriter, r *http.Request) {
+	app.setUserActive(w, r, true)
+}
+
+// DeactivateUser godoc
+//
+//	@Summary		Deactivate a user
+//	@Description	Deactivate an account and sign it out everywhere. Only an administrator can lift it.
+//	@Tags			admin
+//	@Produce		json
+//	@Param			userID	path		int	true	"User ID"
+//	@Success		204		{object}	nil	"Account deactivated"
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/admin/users/{userID}/deactivate [post]
+func (app *application) adminDeactivateUserHandler(w http.ResponseWriter, r *http.Request) {
+	app.setUserActive(w, r, false)
+}
+
+func (app *application) setUserActive(w http.ResponseWriter, r *http.Request, active bool) {
+	ctx := r.Context()
+	actor := getCurrentUserFromContext(r)
+	target := getUserFromContext(r)
+	if !app.canManageUser(w, r, actor, target) {
+		return
+	}
+	action := "user.deactivate"
+	if active {
+		action = "user.activate"
+	}
+	before := map[string]any{"is_active": target.IsActive, "deactivated": target.DeactivatedAt != nil}
+	after := map[string]any{"is_active": target.IsActive || active, "deactivated": !active}
+	audit := func() *store.AuditEntry {
+		return auditChangeEntry(r, actor.ID, action, "user", strconv.FormatInt(target.ID, 10), before, after)
+	}
+	if active {
+		if err := app.store.Users.Reactivate(ctx, target.ID, audit); err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+	} else {
+		if err := app.store.Users.Deactivate(ctx, target.ID, audit); err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		if err := app.store.Sessions.RevokeAllForUser(ctx, target.ID); err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+	}
+	if err := app.invalidateUser(ctx, target.ID); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AdminUserParamMiddleware is UserParamMiddleware for the admin API, which
+// must also reach accounts that are not active.
+func (app *application) AdminUserParamMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
+		if err != nil {
+			app.badRequestError(w, r, err)
+			return
+		}
+		ctx := r.Context()
+		user, err := app.store.Users.GetByIDIncludingInactive(ctx, userID)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		if user == nil {
+			app.notFoundError(w, r)
+			return
+		}
+		ctx = context.WithValue(ctx, userKeyCtx, user)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// canManageUser writes an error response and returns false unless actor may
+// manage target: never themselves, and only users they outrank.
+func (app *application) canManageUser(w http.ResponseWriter, r *http.Request, actor, target *store.User) bool {
+	if actor.ID == target.ID {
+		app.badRequestError(w, r, fmt.Errorf("cannot manage your own account"))
+		return false
+	}
+	allowed, err := app.outranks(r.Context(), actor, target)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return false
+	}
+	if !allowed {
+		app.forbiddenError(w, r)
+		return false
+	}
+	return true
+}
+
+// canManageRoleLevel writes a 403 response and returns false unless level is
+// below the actor's own, so nobody can grant more than they hold.
+func (app *application) canManageRoleLevel(w http.ResponseWriter, r *http.Request, actor *store.User, level int) bool {
+	actorLevel, err := app.roleLevel(r.Context(), actor.RoleID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return false
+	}
+	if level >= actorLevel {
+		app.forbiddenError(w, r)
+		return false
+	}
+	return true
+}
+
+// canGrantPermissions writes a 403 response and returns false unless the
+// actor holds every one of permissions, so a role cannot be used to hand out
+// more than its editor has.
+func (app *application) canGrantPermissions(w http.ResponseWriter, r *http.Request, actor *store.User, permissions []string) bool {
+	held, err := app.rolePermissions(r.Context(), actor.RoleID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return false
+	}
+	for _, permission := range permissions {
+		if !slices.Contains(held, permission) {
+			app.forbiddenError(w, r)
+			return false
+		}
+	}
+	return true
+}
+
+// outranks reports whether actor's role is strictly more privileged than
+// target's, so admins cannot act as their peers.
+func (app *application) outranks(ctx context.Context, actor, target *store.User) (bool, error) {
+	actorLevel, err := app.roleLevel(ctx, actor.RoleID)
+	if err != nil {
+		return false, err
+	}
+	targetLevel, err := app.roleLevel(ctx, target.RoleID)
+	if err != nil {
+		return false, err
+	}
+	return actorLevel > targetLevel, nil
+}
+
+func (app *application) roleLevel(ctx context.Context, roleID int64) (int, error) {
+	role, err := app.store.Roles.GetByID(ctx, roleID)
+	if err != nil {
+		return 0, err
+	}
+	if role == nil {
+		return 0, fmt.Errorf("role %d not found", roleID)
+	}
+	return role.Level, nil
+}
@@ -0,0 +1,404 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/samuel032khoury/gopherfeed/internal/store"
+	"github.com/samuel032khoury/gopherfeed/internal/store/cache"
+)
+
+func TestAdminAPI(t *testing.T) {
+	app := newTestApplication(t)
+	mux := app.mount()
+
+	token, err := app.generateAccessToken(1, "test-session", "test-jti")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should require the user.manage permission", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/v1/admin/users", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusForbidden, rr.Code)
+	})
+
+	// The mock user has role 1
+	app.permissions.set(1, []string{permUserManage, permRoleManage})
+
+	t.Run("should list users", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/v1/admin/users?active=true&role=user", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("should reject malformed filters", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/v1/admin/users?active=maybe", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("should not let admins manage their own account", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/v1/admin/users/1/deactivate", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusBadRequest, rr.Code)
+	})
+
+	// Every mock user has the same role level, so no one outranks anyone
+	t.Run("should not let admins manage their peers", func(t *testing.T) {
+		body := strings.NewReader(`{"role":"moderator"}`)
+		req, err := http.NewRequest(http.MethodPut, "/v1/admin/users/2/role", body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("should not create roles at or above the admin's level", func(t *testing.T) {
+		body := strings.NewReader(`{"name":"superuser","level":1,"permissions":["user.ban"]}`)
+		req, err := http.NewRequest(http.MethodPost, "/v1/admin/roles", body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusForbidden, rr.Code)
+	})
+}
+
+// seniorRoleStore puts role 1, which every mock user has, at level 3 and
+// every other role at level 1, so they can be edited by the mock users
+type seniorRoleStore struct {
+	store.MockRoleStore
+}
+
+func (s *seniorRoleStore) GetByID(ctx context.Context, id int64) (*store.Role, error) {
+	if id == 1 {
+		return &store.Role{ID: id, Name: "admin", Level: 3}, nil
+	}
+	return &store.Role{ID: id, Name: "user", Level: 1}, nil
+}
+
+func TestRolePermissionEscalation(t *testing.T) {
+	app := newTestApplication(t)
+	mux := app.mount()
+	app.store.Roles = &seniorRoleStore{}
+	app.permissions.set(1, []string{permRoleManage, permUserBan})
+
+	token, err := app.generateAccessToken(1, "test-session", "test-jti")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodPost, "/v1/admin/roles"},
+		{http.MethodPut, "/v1/admin/roles/2"},
+	}
+	for _, tt := range tests {
+		t.Run("should not grant permissions the editor lacks on "+tt.method, func(t *testing.T) {
+			body := strings.NewReader(`{"name":"support","level":2,"permissions":["user.ban","audit.read"]}`)
+			req, err := http.NewRequest(tt.method, tt.path, body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+			rr := execRequest(req, mux)
+			checkResponseCode(t, http.StatusForbidden, rr.Code)
+		})
+	}
+
+	t.Run("should grant permissions the editor holds", func(t *testing.T) {
+		codes := map[string]int{http.MethodPost: http.StatusCreated, http.MethodPut: http.StatusOK}
+		for _, tt := range tests {
+			body := strings.NewReader(`{"name":"support","level":2,"permissions":["user.ban"]}`)
+			req, err := http.NewRequest(tt.method, tt.path, body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+			rr := execRequest(req, mux)
+			checkResponseCode(t, codes[tt.method], rr.Code)
+		}
+	})
+}
+
+// sharedRoleCache stands in for the Redis role cache every instance shares
+type sharedRoleCache struct {
+	cache.MockRoleCache
+	entries map[int64][]string
+}
+
+func (c *sharedRoleCache) GetPermissions(ctx context.Context, roleID int64) ([]string, error) {
+	return c.entries[roleID], nil
+}
+
+func (c *sharedRoleCache) SetPermissions(ctx context.Context, roleID int64, permissions []string) error {
+	c.entries[roleID] = permissions
+	return nil
+}
+
+func (c *sharedRoleCache) Delete(ctx context.Context, roleID int64) error {
+	delete(c.entries, roleID)
+	return nil
+}
+
+func TestSharedPermissionCache(t *testing.T) {
+	app := newTestApplication(t)
+	mux := app.mount()
+	app.store.Roles = &seniorRoleStore{}
+	app.config.cache.enabled = true
+	roles := &sharedRoleCache{entries: map[int64][]string{
+		1: {permRoleManage, permUserBan},
+		2: {permUserBan},
+	}}
+	app.cacheStorage.Roles = roles
+
+	token, err := app.generateAccessToken(1, "test-session", "test-jti")
+	if err != nil {
+		t.Fatal(err)
+	}
+	updateRole := func() int {
+		body := strings.NewReader(`{"name":"support","level":2,"permissions":["user.ban"]}`)
+		req, err := http.NewRequest(http.MethodPut, "/v1/admin/roles/2", body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		return execRequest(req, mux).Code
+	}
+
+	t.Run("should drop the shared entry of an updated role", func(t *testing.T) {
+		checkResponseCode(t, http.StatusOK, updateRole())
+		if _, ok := roles.entries[2]; ok {
+			t.Error("expected the permissions of role 2 to be dropped from the shared cache")
+		}
+	})
+
+	t.Run("should apply a role change made on another instance at once", func(t *testing.T) {
+		roles.entries[1] = []string{permUserBan}
+		checkResponseCode(t, http.StatusForbidden, updateRole())
+	})
+}
+
+func TestAuditLog(t *testing.T) {
+	app := newTestApplication(t)
+	mux := app.mount()
+
+	token, err := app.generateAccessToken(1, "test-session", "test-jti")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should require the audit.read permission", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/v1/admin/audit-log", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusForbidden, rr.Code)
+	})
+
+	app.permissions.set(1, []string{permAuditRead})
+
+	t.Run("should search the audit log", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/v1/admin/audit-log?actor_id=2&action=post.delete&since=2026-01-01T00:00:00Z", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("should reject malformed filters", func(t *testing.T) {
+		for _, query := range []string{"actor_id=admin", "limit=1000", "since=yesterday", "until=2026-13-01T00:00:00Z"} {
+			req, err := http.NewRequest(http.MethodGet, "/v1/admin/audit-log?"+query, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+			rr := execRequest(req, mux)
+			checkResponseCode(t, http.StatusBadRequest, rr.Code)
+		}
+	})
+
+	t.Run("should export JSON lines", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/v1/admin/audit-log/export?target_type=post", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		if contentType := rr.Header().Get("Content-Type"); contentType != "application/x-ndjson" {
+			t.Errorf("expected a JSON lines export; got %q", contentType)
+		}
+	})
+}
+
+// failingAuditStore cannot record audit entries
+type failingAuditStore struct {
+	store.MockAuditStore
+}
+
+func (s *failingAuditStore) Record(ctx context.Context, entry *store.AuditEntry) error {
+	return errors.New("audit log unavailable")
+}
+
+// auditingPostStore keeps the audit entries handed to it with a restore
+type auditingPostStore struct {
+	store.MockPostStore
+	entries []*store.AuditEntry
+}
+
+func (s *auditingPostStore) Restore(ctx context.Context, id int64, audit store.AuditFunc) error {
+	s.entries = append(s.entries, audit())
+	return nil
+}
+
+func TestUnauditedActions(t *testing.T) {
+	app := newTestApplication(t)
+	mux := app.mount()
+	app.store.Audit = &failingAuditStore{}
+	app.permissions.set(1, []string{permAuditRead})
+
+	token, err := app.generateAccessToken(1, "test-session", "test-jti")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should fail actions that cannot be audited", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/v1/admin/audit-log/export", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusInternalServerError, rr.Code)
+	})
+}
+
+func TestAuditedChanges(t *testing.T) {
+	app := newTestApplication(t)
+	mux := app.mount()
+	posts := &auditingPostStore{}
+	app.store.Posts = posts
+	app.permissions.set(1, []string{permTrashManage})
+
+	token, err := app.generateAccessToken(1, "test-session", "test-jti")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should hand the audit entry to the store with the change", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/v1/admin/trash/posts/7/restore", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusNoContent, rr.Code)
+
+		if len(posts.entries) != 1 {
+			t.Fatalf("expected 1 audit entry; got %d", len(posts.entries))
+		}
+		entry := posts.entries[0]
+		if entry.Action != "post.restore" || entry.TargetID != "7" || entry.ActorID == nil || *entry.ActorID != 1 {
+			t.Errorf("unexpected audit entry %+v", entry)
+		}
+		if entry.RequestID == "" {
+			t.Error("expected the entry to carry the request ID")
+		}
+	})
+}
+
+func TestTrash(t *testing.T) {
+	app := newTestApplication(t)
+	mux := app.mount()
+
+	token, err := app.generateAccessToken(1, "test-session", "test-jti")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should require the trash.manage permission", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/v1/admin/trash/posts", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusForbidden, rr.Code)
+	})
+
+	app.permissions.set(1, []string{permTrashManage})
+
+	t.Run("should list the trash", func(t *testing.T) {
+		for _, path := range []string{"/v1/admin/trash/posts", "/v1/admin/trash/comments?sort=asc"} {
+			req, err := http.NewRequest(http.MethodGet, path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+			rr := execRequest(req, mux)
+			checkResponseCode(t, http.StatusOK, rr.Code)
+		}
+	})
+
+	t.Run("should reject malformed pagination", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/v1/admin/trash/posts?sort=newest", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("should restore deleted items", func(t *testing.T) {
+		for _, path := range []string{"/v1/admin/trash/posts/1/restore", "/v1/admin/trash/comments/1/restore"} {
+			req, err := http.NewRequest(http.MethodPost, path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+			rr := execRequest(req, mux)
+			checkResponseCode(t, http.StatusNoContent, rr.Code)
+		}
+	})
+
+	t.Run("should reject malformed IDs", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/v1/admin/trash/posts/first/restore", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusBadRequest, rr.Code)
+	})
+}
@@ -6,17 +6,21 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
+	"github.com/go-webauthn/webauthn/webauthn"
 	"go.uber.org/zap"
 
 	"github.com/samuel032khoury/gopherfeed/docs" // import docs
 	"github.com/samuel032khoury/gopherfeed/internal/auth"
+	"github.com/samuel032khoury/gopherfeed/internal/lockout"
 	"github.com/samuel032khoury/gopherfeed/internal/mq/publisher"
+	"github.com/samuel032khoury/gopherfeed/internal/password"
 	"github.com/samuel032khoury/gopherfeed/internal/ratelimiter"
 	"github.com/samuel032khoury/gopherfeed/internal/store"
 	"github.com/samuel032khoury/gopherfeed/internal/store/cache"
@@ -24,13 +28,24 @@ import (
 )
 
 type application struct {
-	config         config
-	logger         *zap.SugaredLogger
-	store          *store.Storage
-	cacheStorage   *cache.CacheStorage
-	emailPublisher *publisher.EmailPublisher
-	authenticator  auth.Authenticator
-	ratelimiter    ratelimiter.Limiter
+	config            config
+	logger            *zap.SugaredLogger
+	store             *store.Storage
+	cacheStorage      *cache.CacheStorage
+	emailPublisher    publisher.Publisher
+	authenticator     auth.Authenticator
+	passwordHasher    password.Hasher
+	passwordPolicy    *password.Policy
+	ratelimiter       ratelimiter.Limiter
+	loginAttempts     loginTrackers
+	magicLinkLimiter  ratelimiter.Limiter
+	resetLimiter      ratelimiter.Limiter
+	lockNoticeLimiter ratelimiter.Limiter
+	identityProviders map[string]auth.IdentityProvider
+	webauthn          *webauthn.WebAuthn
+	permissions       *permissionCache
+	// tasks counts work started by requests that outlives them
+	tasks sync.WaitGroup
 }
 
 type config struct {
@@ -41,6 +56,8 @@ type config struct {
 	mq              mqConfig
 	auth            authConfig
 	ratelimiter     ratelimiterConfig
+	magicLink       ratelimiterConfig
+	jobs            jobsConfig
 	env             string
 }
 
@@ -68,8 +85,13 @@ type queueNames struct {
 }
 
 type authConfig struct {
-	basic basicAuthConfig
-	jwt   jwtConfig
+	basic    basicAuthConfig
+	jwt      jwtConfig
+	mfa      mfaConfig
+	lockout  lockoutConfig
+	oidc     oidcConfig
+	passkey  passkeyConfig
+	password passwordConfig
 }
 
 type basicAuthConfig struct {
@@ -82,6 +104,54 @@ type jwtConfig struct {
 	tokenDuration string
 	iss           string
 	aud           string
+	keysDir       string
+	activeKeyID   string
+}
+
+type mfaConfig struct {
+	issuer            string
+	requiredRoleLevel int
+}
+
+type oidcConfig struct {
+	providerName string
+	issuerURL    string
+	clientID     string
+	clientSecret string
+	redirectURL  string
+}
+
+// passwordConfig holds the argon2id cost parameters for new password hashes
+// and the strength requirements for new passwords
+type passwordConfig struct {
+	memory       int
+	iterations   int
+	parallelism  int
+	minEntropy   int
+	breachedList string
+}
+
+type passkeyConfig struct {
+	rpID    string
+	rpName  string
+	origins string
+}
+
+type lockoutConfig struct {
+	freeAttempts   int
+	ipFreeAttempts int
+	baseDelay      string
+	maxDelay       string
+	threshold      int
+	ipThreshold    int
+	duration       string
+	failureWindow  string
+}
+
+// loginTrackers throttle failed logins per account and per client IP
+type loginTrackers struct {
+	account lockout.Tracker
+	ip      lockout.Tracker
 }
 
 type ratelimiterConfig struct {
@@ -89,14 +159,24 @@ type ratelimiterConfig struct {
 	interval string
 }
 
+type jobsConfig struct {
+	invitationCleanupInterval string
+	suspensionExpiryInterval  string
+	postPublishInterval       string
+	postRerenderInterval      string
+	trashPurgeInterval        string
+	// trashRetention is how long deleted posts and comments can be restored
+	trashRetention string
+}
+
 func (app *application) mount() http.Handler {
 	r := chi.NewRouter()
 
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{app.config.frontendBaseURL},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "If-Match", "If-None-Match", csrfHeader, mfaChallengeHeader},
+		ExposedHeaders:   []string{"Link", "ETag", impersonatedByHeader},
 		AllowCredentials: true, // Allow cookies to be sent
 		MaxAge:           300,
 	}))
@@ -110,7 +190,11 @@ func (app *application) mount() http.Handler {
 
 	r.Use(app.RateLimitMiddleware)
 
+	r.Get("/.well-known/jwks.json", app.jwksHandler)
+
 	r.Route("/v1", func(r chi.Router) {
+		r.Use(app.CSRFMiddleware)
+
 		r.With(app.BasicAuthMiddleware).Get("/health", app.healthCheckHandler)
 		r.With(app.BasicAuthMiddleware).Get("/stats", expvar.Handler().ServeHTTP)
 
@@ -119,21 +203,69 @@ func (app *application) mount() http.Handler {
 		))
 		r.Route("/posts", func(r chi.Router) {
 			r.Use(app.TokenAuthMiddleware)
-			r.Post("/", app.createPostHandler)
+			r.With(app.RequireScope(scopePostsWrite)).Post("/", app.createPostHandler)
 			r.Route("/{postID}", func(r chi.Router) {
 				r.Use(app.PostParamMiddleware)
-				r.Get("/", app.getPostHandler)
-				r.Post("/comments", app.createCommentHandler)
-				r.With(app.RBACMiddleware("moderator")).Put("/", app.updatePostHandler)
-				r.With(app.RBACMiddleware("admin")).Delete("/", app.deletePostHandler)
+				r.With(app.RequireScope(scopePostsRead)).Get("/", app.getPostHandler)
+				r.With(app.RequireScope(scopeCommentsWrite)).Post("/comments", app.createCommentHandler)
+				r.With(
+					app.RequireScope(scopeCommentsWrite),
+					app.CommentParamMiddleware,
+					app.RequireOwnerOrPermission(permCommentDeleteAny, commentOwnerID),
+				).Delete("/comments/{commentID}", app.deleteCommentHandler)
+				r.With(app.RequireScope(scopePostsWrite), app.RequireOwnerOrPermission(permPostUpdateAny, postOwnerID)).Put("/", app.updatePostHandler)
+				r.With(app.RequireScope(scopePostsWrite), app.RequireOwnerOrPermission(permPostUpdateAny, postOwnerID)).Patch("/", app.patchPostHandler)
+				r.With(app.RequireScope(scopePostsWrite), app.RequireOwnerOrPermission(permPostDeleteAny, postOwnerID)).Delete("/", app.deletePostHandler)
+				r.Route("/revisions", func(r chi.Router) {
+					r.Use(app.RequireScope(scopePostsRead))
+					r.Use(app.RequireOwnerOrPermission(permPostUpdateAny, postOwnerID))
+					r.Get("/", app.listRevisionsHandler)
+					r.Get("/diff", app.diffRevisionsHandler)
+					r.Route("/{version}", func(r chi.Router) {
+						r.Use(app.RevisionParamMiddleware)
+						r.Get("/", app.getRevisionHandler)
+						r.With(app.RequireScope(scopePostsWrite)).Post("/restore", app.restoreRevisionHandler)
+					})
+				})
 			})
 		})
 		r.Route("/users", func(r chi.Router) {
+			r.Route("/me", func(r chi.Router) {
+				r.Route("/suspension", func(r chi.Router) {
+					r.Use(app.AllowWhileSuspended)
+					r.Use(app.TokenAuthMiddleware)
+					r.Use(app.DenyPersonalAccessTokens)
+					r.Use(app.DenyImpersonation)
+					r.Get("/", app.getOwnSuspensionHandler)
+					r.Put("/appeal", app.appealSuspensionHandler)
+				})
+				r.Group(func(r chi.Router) {
+					r.Use(app.TokenAuthMiddleware)
+					r.With(app.RequireScope(scopePostsRead)).Get("/drafts", app.listDraftsHandler)
+					r.With(app.DenyPersonalAccessTokens, app.DenyImpersonation).Put("/password", app.changePasswordHandler)
+					r.With(app.DenyPersonalAccessTokens, app.DenyImpersonation).Put("/email", app.changeEmailHandler)
+					r.Route("/sessions", func(r chi.Router) {
+						r.Use(app.DenyPersonalAccessTokens)
+						r.Use(app.DenyImpersonation)
+						r.Get("/", app.listSessionsHandler)
+						r.Delete("/", app.revokeOtherSessionsHandler)
+						r.Delete("/{sessionID}", app.revokeSessionHandler)
+					})
+					r.Route("/tokens", func(r chi.Router) {
+						r.Use(app.DenyPersonalAccessTokens)
+						r.Use(app.DenyImpersonation)
+						r.Get("/", app.listTokensHandler)
+						r.Post("/", app.createTokenHandler)
+						r.Delete("/{tokenID}", app.revokeTokenHandler)
+					})
+				})
+			})
 			r.Route("/{userID}", func(r chi.Router) {
 				r.Use(app.UserParamMiddleware)
 				r.Get("/", app.getUserHandler)
 				r.Group(func(r chi.Router) {
 					r.Use(app.TokenAuthMiddleware)
+					r.Use(app.RequireScope(scopeUsersWrite))
 					r.Put("/follow", app.followUserHandler)
 					r.Put("/unfollow", app.unfollowUserHandler)
 				})
@@ -142,14 +274,98 @@ func (app *application) mount() http.Handler {
 
 		r.Route("/feeds", func(r chi.Router) {
 			r.Use(app.TokenAuthMiddleware)
-			r.Get("/", app.getFeedHandler)
+			r.With(app.RequireScope(scopeFeedRead)).Get("/", app.getFeedHandler)
+		})
+
+		r.Route("/admin", func(r chi.Router) {
+			r.Use(app.TokenAuthMiddleware)
+			r.Use(app.DenyPersonalAccessTokens)
+			r.Use(app.DenyImpersonation)
+			r.With(app.RequirePermission(permUserManage)).Get("/users", app.listUsersHandler)
+			r.Route("/users/{userID}", func(r chi.Router) {
+				r.Use(app.AdminUserParamMiddleware)
+				r.With(app.RequirePermission(permUserUnlock)).Post("/unlock", app.unlockUserHandler)
+				r.With(app.RequirePermission(permUserImpersonate)).Post("/impersonate", app.impersonateUserHandler)
+				r.Route("/suspension", func(r chi.Router) {
+					r.Use(app.RequirePermission(permUserBan))
+					r.Post("/", app.suspendUserHandler)
+					r.Delete("/", app.liftSuspensionHandler)
+				})
+				r.Group(func(r chi.Router) {
+					r.Use(app.RequirePermission(permUserManage))
+					r.Put("/role", app.assignRoleHandler)
+					r.Post("/activate", app.adminActivateUserHandler)
+					r.Post("/deactivate", app.adminDeactivateUserHandler)
+				})
+			})
+			r.With(app.RequirePermission(permUserImpersonate)).Delete("/impersonations/{sessionID}", app.stopImpersonationHandler)
+			r.Route("/audit-log", func(r chi.Router) {
+				r.Use(app.RequirePermission(permAuditRead))
+				r.Get("/", app.listAuditLogHandler)
+				r.Get("/export", app.exportAuditLogHandler)
+			})
+			r.Route("/trash", func(r chi.Router) {
+				r.Use(app.RequirePermission(permTrashManage))
+				r.Get("/posts", app.listDeletedPostsHandler)
+				r.Post("/posts/{postID}/restore", app.restorePostHandler)
+				r.Get("/comments", app.listDeletedCommentsHandler)
+				r.Post("/comments/{commentID}/restore", app.restoreCommentHandler)
+			})
+			r.Route("/roles", func(r chi.Router) {
+				r.Use(app.RequirePermission(permRoleManage))
+				r.Get("/", app.listRolesHandler)
+				r.Post("/", app.createRoleHandler)
+				r.Put("/{roleID}", app.updateRoleHandler)
+			})
 		})
 
 		r.Route("/auth", func(r chi.Router) {
+			r.Get("/csrf", app.getCSRFTokenHandler)
 			r.Post("/register", app.registerUserHandler)
 			r.Post("/login", app.loginUserHandler)
 			r.Post("/activate", app.activateUserHandler)
+			r.Post("/activate/resend", app.resendActivationHandler)
+			r.Post("/refresh", app.refreshTokenHandler)
 			r.Post("/logout", app.logoutUserHandler)
+			r.Post("/password/forgot", app.forgotPasswordHandler)
+			r.Post("/password/reset", app.resetPasswordHandler)
+			r.Post("/magic-link", app.requestMagicLinkHandler)
+			r.Post("/magic-link/redeem", app.redeemMagicLinkHandler)
+			r.Post("/email/confirm", app.confirmEmailChangeHandler)
+			r.Route("/oidc/{provider}", func(r chi.Router) {
+				r.Get("/login", app.oidcLoginHandler)
+				r.Post("/callback", app.oidcCallbackHandler)
+			})
+			r.Route("/passkeys", func(r chi.Router) {
+				r.Post("/login/begin", app.beginPasskeyLoginHandler)
+				r.Post("/login/finish", app.finishPasskeyLoginHandler)
+				r.Group(func(r chi.Router) {
+					r.Use(app.TokenAuthMiddleware)
+					r.Use(app.DenyPersonalAccessTokens)
+					r.Use(app.DenyImpersonation)
+					r.Get("/", app.listPasskeysHandler)
+					r.Post("/register/begin", app.beginPasskeyRegistrationHandler)
+					r.Post("/register/finish", app.finishPasskeyRegistrationHandler)
+					r.Delete("/{passkeyID}", app.deletePasskeyHandler)
+				})
+			})
+			r.Route("/mfa", func(r chi.Router) {
+				r.Post("/verify", app.verifyMFAHandler)
+				r.Group(func(r chi.Router) {
+					r.Use(app.MFAEnrollmentAuthMiddleware)
+					r.Use(app.DenyPersonalAccessTokens)
+					r.Use(app.DenyImpersonation)
+					r.Post("/totp", app.enrollTOTPHandler)
+					r.Post("/totp/confirm", app.confirmTOTPHandler)
+				})
+				r.Group(func(r chi.Router) {
+					r.Use(app.TokenAuthMiddleware)
+					r.Use(app.DenyPersonalAccessTokens)
+					r.Use(app.DenyImpersonation)
+					r.Delete("/totp", app.disableTOTPHandler)
+					r.Post("/recovery-codes", app.regenerateRecoveryCodesHandler)
+				})
+			})
 		})
 	})
 	return r
@@ -168,16 +384,23 @@ func (app *application) run(mux http.Handler) error {
 		ReadTimeout:  time.Second * 10,
 		IdleTimeout:  time.Minute,
 	}
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	app.startBackgroundJobs(jobsCtx)
+
 	shutdown := make(chan error, 1)
 	go func() {
 		quit := make(chan os.Signal, 1)
 		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 		sig := <-quit
+		stopJobs()
 
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 		app.logger.Infow("Received signal, initiating shutdown...", "signal", sig)
-		shutdown <- srv.Shutdown(ctx)
+		err := srv.Shutdown(ctx)
+		app.tasks.Wait()
+		shutdown <- err
 	}()
 	app.logger.Infow("server has started", "address", app.config.addr, "env", app.config.env)
 	err := srv.ListenAndServe()
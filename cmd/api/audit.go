@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/samuel032khoury/gopherfeed/internal/store"
+)
+
+// auditExportBatchSize is how many entries an export reads per query
+const auditExportBatchSize = 500
+
+// audit records a security relevant action taken by actorID that changes
+// nothing else, such as an export of the audit log. Actions that change
+// state hand the store an AuditFunc instead, so their entry is recorded in
+// the same transaction. Either way callers fail the request when the entry
+// cannot be recorded, so an unaudited action never looks successful.
+func (app *application) audit(r *http.Request, actorID int64, action, targetType, targetID string, metadata map[string]any) error {
+	entry := auditEntry(r, actorID, action, targetType, targetID, metadata)
+	if err := app.store.Audit.Record(r.Context(), entry); err != nil {
+		return fmt.Errorf("failed to record audit entry %s: %w", entry.Action, err)
+	}
+	return nil
+}
+
+// auditEntry builds the entry for an action actorID takes with r.
+func auditEntry(r *http.Request, actorID int64, action, targetType, targetID string, metadata map[string]any) *store.AuditEntry {
+	return &store.AuditEntry{
+		ActorID:    &actorID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Metadata:   metadata,
+		RequestID:  middleware.GetReqID(r.Context()),
+		IP:         clientIP(r),
+	}
+}
+
+// auditChangeEntry is auditEntry for actions that modify the target, keeping
+// a snapshot of it from before and after the action. Either may be nil, e.g.
+// there is nothing after a deletion.
+func auditChangeEntry(r *http.Request, actorID int64, action, targetType, targetID string, before, after any) *store.AuditEntry {
+	entry := auditEntry(r, actorID, action, targetType, targetID, nil)
+	entry.Before = before
+	entry.After = after
+	return entry
+}
+
+// ListAuditLog godoc
+//
+//	@Summary		Search the audit log
+//	@Description	List audit log entries, newest first, with optional filters
+//	@Tags			admin
+//	@Produce		json
+//	@Param			limit		query		int		false	"Number of items per page (1-100)"	example(20)
+//	@Param			offset		query		int		false	"Number of items to skip"			example(0)
+//	@Param			actor_id	query		int		false	"Only actions by this user"			example(2)
+//	@Param			action		query		string	false	"Only this action"					example(post.delete)
+//	@Param			target_type	query		string	false	"Only targets of this type"			example(post)
+//	@Param			target_id	query		string	false	"Only this target"					example(17)
+//	@Param			since		query		string	false	"Only entries at or after this time"	example(2026-01-01T00:00:00Z)
+//	@Param			until		query		string	false	"Only entries at or before this time"	example(2026-01-31T23:59:59Z)
+//	@Success		200			{object}	DataResponse[[]store.AuditEntry]
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		401			{object}	ErrorResponse
+//	@Failure		403			{object}	ErrorResponse
+//	@Failure		500			{object}	ErrorResponse
+//	@Router			/admin/audit-log [get]
+func (app *application) listAuditLogHandler(w http.ResponseWriter, r *http.Request) {
+	filter, ok := app.readAuditFilter(w, r)
+	if !ok {
+		return
+	}
+	entries, err := app.store.Audit.List(r.Context(), filter)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, entries, http.StatusOK)
+}
+
+// ExportAuditLog godoc
+//
+//	@Summary		Export the audit log
+//	@Description	Download every audit log entry matching the filters as JSON lines, oldest first. The limit and offset parameters are ignored.
+//	@Tags			admin
+//	@Produce		application/x-ndjson
+//	@Param			actor_id	query		int		false	"Only actions by this user"			example(2)
+//	@Param			action		query		string	false	"Only this action"					example(post.delete)
+//	@Param			target_type	query		string	false	"Only targets of this type"			example(post)
+//	@Param			target_id	query		string	false	"Only this target"					example(17)
+//	@Param			since		query		string	false	"Only entries at or after this time"	example(2026-01-01T00:00:00Z)
+//	@Param			until		query		string	false	"Only entries at or before this time"	example(2026-01-31T23:59:59Z)
+//	@Success		200			{object}	store.AuditEntry	"One entry per line"
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		401			{object}	ErrorResponse
+//	@Failure		403			{object}	ErrorResponse
+//	@Failure		500			{object}	ErrorResponse
+//	@Router			/admin/audit-log/export [get]
+func (app *application) exportAuditLogHandler(w http.ResponseWriter, r *http.Request) {
+	filter, ok := app.readAuditFilter(w, r)
+	if !ok {
+		return
+	}
+	filter.Limit = auditExportBatchSize
+	filter.Offset = 0
+
+	ctx := r.Context()
+	entries, err := app.store.Audit.ListAfter(ctx, filter, 0)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	metadata := map[string]any{"query": r.URL.RawQuery}
+	if err := app.audit(r, getCurrentUserFromContext(r).ID, "audit.export", "audit_log", "", metadata); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", `attachment; filename="audit-log.jsonl"`)
+	w.WriteHeader(http.StatusOK)
+	encoder := json.NewEncoder(w)
+	for {
+		for _, entry := range entries {
+			if err := encoder.Encode(entry); err != nil {
+				app.logger.Warnw("audit log export aborted", "error", err)
+				return
+			}
+		}
+		if len(entries) < filter.Limit {
+			return
+		}
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+		// The status is already sent, so a failure can only cut the export short
+		entries, err = app.store.Audit.ListAfter(ctx, filter, entries[len(entries)-1].ID)
+		if err != nil {
+			app.logger.Errorw("audit log export failed", "error", err)
+			return
+		}
+	}
+}
+
+func (app *application) readAuditFilter(w http.ResponseWriter, r *http.Request) (*store.AuditFilter, bool) {
+	filter := &store.AuditFilter{
+		Limit:  20,
+		Offset: 0,
+	}
+	filter, err := filter.Parse(r)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return nil, false
+	}
+	if err := Validate.Struct(filter); err != nil {
+		app.badRequestError(w, r, err)
+		return nil, false
+	}
+	return filter, true
+}
@@ -1,9 +1,14 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/google/uuid"
+	"github.com/samuel032khoury/gopherfeed/internal/auth"
 	"github.com/samuel032khoury/gopherfeed/internal/email"
 	"github.com/samuel032khoury/gopherfeed/internal/store"
 	"github.com/samuel032khoury/gopherfeed/internal/utils"
@@ -15,7 +20,7 @@ import (
 type registerPayload struct {
 	Username string `json:"username" validate:"required,alphanum,min=3,max=30" example:"newuser"`
 	Email    string `json:"email" validate:"required,email" example:"newuser@example.com"`
-	Password string `json:"password" validate:"required,min=8,max=72" example:"password"`
+	Password string `json:"password" validate:"required,min=8,max=256" example:"plum-Orbit-71-lantern"`
 }
 
 // loginPayload represents the expected payload for authentication endpoints
@@ -23,7 +28,7 @@ type registerPayload struct {
 //	@Description	Login payload
 type loginPayload struct {
 	Email    string `json:"email" validate:"required,email" example:"user1@example.com"`
-	Password string `json:"password" validate:"required,min=8,max=72" example:"password"`
+	Password string `json:"password" validate:"required,min=8,max=256" example:"password"`
 }
 
 // tokenDTO represents the payload for token-based requests
@@ -33,6 +38,35 @@ type tokenDTO struct {
 	Token string `json:"token" validate:"required,uuid4" example:"123e4567-e89b-12d3-a456-426614174000"`
 }
 
+// emailDTO represents the payload for requests identified by email address
+//
+//	@Description	Email payload
+type emailDTO struct {
+	Email string `json:"email" validate:"required,email" example:"user1@example.com"`
+}
+
+// resetPasswordPayload represents the expected payload for resetting a password
+//
+//	@Description	Password reset payload
+type resetPasswordPayload struct {
+	Token    string `json:"token" validate:"required,uuid4" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Password string `json:"password" validate:"required,min=8,max=256" example:"quiet-Harbor-38-violin"`
+}
+
+// magicLinkPayload represents the expected payload for redeeming a login link
+//
+//	@Description	Magic link redemption payload
+type magicLinkPayload struct {
+	Token string `json:"token" validate:"required,uuid4" example:"123e4567-e89b-12d3-a456-426614174000"`
+}
+
+// messageResponse represents a response carrying a human-readable message
+//
+//	@Description	Message response
+type messageResponse struct {
+	Message string `json:"message" example:"Request processed successfully"`
+}
+
 // activateResponse represents the response payload for account activation
 //
 //	@Description	Account activation response
@@ -40,6 +74,19 @@ type activateResponse struct {
 	Message string `json:"message" example:"Account activated successfully"`
 }
 
+// jwksHandler publishes the public keys for verifying access tokens at the
+// well-known location outside /v1, so it is not part of the Swagger docs.
+// It is only available when asymmetric signing is configured.
+func (app *application) jwksHandler(w http.ResponseWriter, r *http.Request) {
+	provider, ok := app.authenticator.(auth.JWKSProvider)
+	if !ok {
+		app.notFoundError(w, r)
+		return
+	}
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	writeJSON(w, provider.JWKS(), http.StatusOK)
+}
+
 // RegisterUser godoc
 //
 //	@Summary		Register a new user
@@ -49,7 +96,7 @@ type activateResponse struct {
 //	@Produce		json
 //	@Param			user	body		registerPayload					true	"User registration payload"
 //	@Success		201		{object}	DataResponse[store.User]	"User registered successfully"
-//	@Failure		400		{object}	ErrorResponse
+//	@Failure		400		{object}	FieldErrorResponse			"Invalid payload, or password rejected by the password policy"
 //	@Failure		500		{object}	ErrorResponse
 //	@Router			/auth/register [post]
 func (app *application) registerUserHandler(w http.ResponseWriter, r *http.Request) {
@@ -62,7 +109,10 @@ func (app *application) registerUserHandler(w http.ResponseWriter, r *http.Reque
 		app.badRequestError(w, r, err)
 		return
 	}
-	encryptedPassword, err := utils.EncryptPassword(payload.Password)
+	if !app.checkPasswordPolicy(w, r, "password", payload.Password, nil, payload.Username, payload.Email) {
+		return
+	}
+	encryptedPassword, err := app.passwordHasher.Hash(payload.Password)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -88,16 +138,7 @@ func (app *application) registerUserHandler(w http.ResponseWriter, r *http.Reque
 		}
 		return
 	}
-	isProdEnv := app.config.env == "production"
-	vars := struct {
-		Username      string
-		ActivationURL string
-	}{
-		Username:      user.Username,
-		ActivationURL: utils.GenerateActivationURL(app.config.frontendBaseURL, token, isProdEnv),
-	}
-	err = app.emailPublisher.Publish(user.Email, email.UserInviteTemplate, vars)
-	if err != nil {
+	if err := app.sendActivationEmail(user, token); err != nil {
 		app.logger.Errorw("failed to send activation email", "email", user.Email, "error", err)
 		// saga
 		if err := app.store.Users.Delete(ctx, user.ID); err != nil {
@@ -110,17 +151,73 @@ func (app *application) registerUserHandler(w http.ResponseWriter, r *http.Reque
 	app.jsonResponse(w, user, http.StatusCreated)
 }
 
+// ResendActivation godoc
+//
+//	@Summary		Resend activation email
+//	@Description	Rotate the activation token of an inactive account and email a new link. The response is the same whether or not the email is registered.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			email	body		emailDTO						true	"Account email"
+//	@Success		202		{object}	DataResponse[messageResponse]	"Activation email queued if the account is pending"
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/auth/activate/resend [post]
+func (app *application) resendActivationHandler(w http.ResponseWriter, r *http.Request) {
+	payload := &emailDTO{}
+	if err := readJSON(w, r, payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	response := messageResponse{
+		Message: "If a pending account with that email exists, a new activation link has been sent",
+	}
+
+	token := uuid.New().String()
+	user, err := app.store.Users.RotateInvitation(r.Context(), payload.Email, utils.Hash(token), utils.InvitationTokenExpiry)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if user == nil {
+		app.jsonResponse(w, response, http.StatusAccepted)
+		return
+	}
+	if err := app.sendActivationEmail(user, token); err != nil {
+		app.logger.Errorw("failed to resend activation email", "email", user.Email, "error", err)
+	}
+	app.jsonResponse(w, response, http.StatusAccepted)
+}
+
+func (app *application) sendActivationEmail(user *store.User, token string) error {
+	isProdEnv := app.config.env == "production"
+	vars := struct {
+		Username      string
+		ActivationURL string
+	}{
+		Username:      user.Username,
+		ActivationURL: utils.GenerateActivationURL(app.config.frontendBaseURL, token, isProdEnv),
+	}
+	return app.emailPublisher.Publish(user.Email, email.UserInviteTemplate, vars)
+}
+
 // LoginUser godoc
 //
 //	@Summary		User login
-//	@Description	Authenticate a user and set authentication cookie
+//	@Description	Authenticate a user and set authentication cookie, or return a pending challenge when two-factor authentication is needed. Repeated failures are delayed progressively and eventually lock the account temporarily.
 //	@Tags			auth
 //	@Accept			json
 //	@Produce		json
 //	@Param			user	body		loginPayload						true	"User login payload"
 //	@Success		200		{object}	nil	"Login successful, cookie set"
+//	@Success		202		{object}	DataResponse[mfaChallengeResponse]	"Second factor required"
 //	@Failure		400		{object}	ErrorResponse
 //	@Failure		401		{object}	ErrorResponse
+//	@Failure		429		{object}	ErrorResponse	"Too many failed attempts, see Retry-After"
 //	@Failure		500		{object}	ErrorResponse
 //	@Router			/auth/login [post]
 func (app *application) loginUserHandler(w http.ResponseWriter, r *http.Request) {
@@ -135,30 +232,64 @@ func (app *application) loginUserHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	ctx := r.Context()
-	token, err := app.store.Users.Authenticate(ctx, payload.Email, payload.Password, app.authenticator)
+	accountKey := loginAccountKey(payload.Email)
+	if !app.allowLoginAttempt(w, r, accountKey) {
+		return
+	}
+	user, err := app.store.Users.Authenticate(ctx, payload.Email, payload.Password)
 	if err != nil {
 		switch err {
 		case store.ErrInvalidCredentials:
+			app.recordLoginFailure(ctx, r, accountKey, func() (*store.User, error) {
+				return app.store.Users.GetByEmail(ctx, payload.Email)
+			})
 			app.unauthorizedError(w, r, false, err)
 		default:
 			app.internalServerError(w, r, err)
 		}
 		return
 	}
+	app.resetLoginFailures(ctx, accountKey)
+	app.completeLogin(w, r, user)
+}
 
-	expDuration, _, _ := app.authenticator.GetMetadata()
-
-	// Set JWT as HTTPOnly cookie
-	http.SetCookie(w, &http.Cookie{
-		Name:     "jwt",
-		Value:    token,
-		Path:     "/",
-		MaxAge:   int(expDuration.Seconds()),
-		HttpOnly: true,
-		Secure:   app.config.env == "production",
-		SameSite: http.SameSiteStrictMode,
-	})
-
+// RefreshToken godoc
+//
+//	@Summary		Refresh access token
+//	@Description	Rotate the refresh token cookie and issue a new access token cookie
+//	@Tags			auth
+//	@Produce		json
+//	@Success		200	{object}	nil	"Tokens refreshed, cookies set"
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/auth/refresh [post]
+func (app *application) refreshTokenHandler(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie(refreshCookieName)
+	if err != nil {
+		app.unauthorizedError(w, r, false, fmt.Errorf("missing refresh token cookie"))
+		return
+	}
+	refreshToken := uuid.New().String()
+	jti := uuid.New().String()
+	session, err := app.store.Sessions.Rotate(r.Context(), utils.Hash(cookie.Value), utils.Hash(refreshToken), jti, utils.RefreshTokenExpiry)
+	if err != nil {
+		switch err {
+		case store.ErrTokenReused:
+			app.logger.Warnw("refresh token reuse detected, session revoked", "ip", r.RemoteAddr)
+			app.clearSessionCookies(w)
+			app.unauthorizedError(w, r, false, err)
+		case store.ErrInvalidToken:
+			app.clearSessionCookies(w)
+			app.unauthorizedError(w, r, false, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	if err := app.setSessionCookies(w, session.UserID, session.ID, jti, refreshToken); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
 	w.WriteHeader(http.StatusOK)
 }
 
@@ -200,24 +331,267 @@ func (app *application) activateUserHandler(w http.ResponseWriter, r *http.Reque
 	app.jsonResponse(w, response, http.StatusOK)
 }
 
+// ForgotPassword godoc
+//
+//	@Summary		Request a password reset
+//	@Description	Email a single-use password reset link. The response is the same whether or not the email is registered, and requests are limited per address.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			email	body		emailDTO						true	"Account email"
+//	@Success		202		{object}	DataResponse[messageResponse]	"Reset email queued if the account exists"
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		429		{object}	ErrorResponse	"Too many requests for this address, see Retry-After"
+//	@Router			/auth/password/forgot [post]
+func (app *application) forgotPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	payload := &emailDTO{}
+	if err := readJSON(w, r, payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	// Limited per address whether or not it exists, so the limit reveals nothing
+	if allow, retryAfter := app.resetLimiter.Allow(strings.ToLower(payload.Email)); !allow {
+		app.rateLimitExceededError(w, r, strconv.Itoa(int(retryAfter.Seconds())))
+		return
+	}
+	response := messageResponse{
+		Message: "If an account with that email exists, a password reset link has been sent",
+	}
+
+	// The lookup and the email happen after the response, so that neither
+	// its timing nor a failure tells the caller whether the account exists
+	address := payload.Email
+	app.background("password-reset", func(ctx context.Context) error {
+		return app.sendPasswordReset(ctx, address)
+	})
+	app.jsonResponse(w, response, http.StatusAccepted)
+}
+
+// sendPasswordReset emails a reset link to the account with the given email,
+// if there is one.
+func (app *application) sendPasswordReset(ctx context.Context, address string) error {
+	user, err := app.store.Users.GetByEmail(ctx, address)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return nil
+	}
+
+	token := uuid.New().String()
+	exp := utils.PasswordResetExpiry
+	if err := app.store.Users.CreatePasswordReset(ctx, user.ID, utils.Hash(token), exp); err != nil {
+		return err
+	}
+	isProdEnv := app.config.env == "production"
+	vars := struct {
+		Username  string
+		ResetURL  string
+		ExpiresIn string
+	}{
+		Username:  user.Username,
+		ResetURL:  utils.GeneratePasswordResetURL(app.config.frontendBaseURL, token, isProdEnv),
+		ExpiresIn: exp.String(),
+	}
+	return app.emailPublisher.Publish(user.Email, email.PasswordResetTemplate, vars)
+}
+
+// ResetPassword godoc
+//
+//	@Summary		Reset password
+//	@Description	Set a new password using a reset token and revoke all existing sessions
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		resetPasswordPayload			true	"Reset token and new password"
+//	@Success		200		{object}	DataResponse[messageResponse]	"Password reset successfully"
+//	@Failure		400		{object}	FieldErrorResponse				"Invalid token, or password rejected by the password policy"
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/auth/password/reset [post]
+func (app *application) resetPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	payload := &resetPasswordPayload{}
+	if err := readJSON(w, r, payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	ctx := r.Context()
+	user, err := app.store.Users.GetByPasswordReset(ctx, payload.Token)
+	if err != nil {
+		switch err {
+		case store.ErrInvalidToken:
+			app.badRequestError(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	if !app.checkPasswordPolicy(w, r, "password", payload.Password, user) {
+		return
+	}
+	encryptedPassword, err := app.passwordHasher.Hash(payload.Password)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	user, err = app.store.Users.ResetPassword(ctx, payload.Token, encryptedPassword)
+	if err != nil {
+		switch err {
+		case store.ErrInvalidToken:
+			app.badRequestError(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	if err := app.store.Sessions.RevokeAllForUser(ctx, user.ID); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.clearSessionCookies(w)
+	response := messageResponse{
+		Message: "Password reset successfully",
+	}
+	app.jsonResponse(w, response, http.StatusOK)
+}
+
+// RequestMagicLink godoc
+//
+//	@Summary		Request a login link
+//	@Description	Email a single-use login link. The response is the same whether or not the account exists.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			email	body		emailDTO						true	"Account email"
+//	@Success		202		{object}	DataResponse[messageResponse]	"Login link queued if the account exists"
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		429		{object}	ErrorResponse	"Too many links requested for this address, see Retry-After"
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/auth/magic-link [post]
+func (app *application) requestMagicLinkHandler(w http.ResponseWriter, r *http.Request) {
+	payload := &emailDTO{}
+	if err := readJSON(w, r, payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	// Limited per address whether or not it exists, so the limit reveals nothing
+	if allow, retryAfter := app.magicLinkLimiter.Allow(strings.ToLower(payload.Email)); !allow {
+		app.rateLimitExceededError(w, r, strconv.Itoa(int(retryAfter.Seconds())))
+		return
+	}
+	response := messageResponse{
+		Message: "If an account with that email exists, a login link has been sent",
+	}
+
+	ctx := r.Context()
+	user, err := app.store.Users.GetByEmail(ctx, payload.Email)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if user == nil {
+		app.jsonResponse(w, response, http.StatusAccepted)
+		return
+	}
+
+	token := uuid.New().String()
+	exp := utils.MagicLinkExpiry
+	if err := app.store.Users.CreateLoginToken(ctx, user, utils.Hash(token), exp); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	isProdEnv := app.config.env == "production"
+	vars := struct {
+		Username  string
+		LoginURL  string
+		ExpiresIn string
+	}{
+		Username:  user.Username,
+		LoginURL:  utils.GenerateMagicLinkURL(app.config.frontendBaseURL, token, isProdEnv),
+		ExpiresIn: exp.String(),
+	}
+	if err := app.emailPublisher.Publish(user.Email, email.MagicLinkTemplate, vars); err != nil {
+		app.logger.Errorw("failed to send magic link email", "email", user.Email, "error", err)
+	}
+	app.jsonResponse(w, response, http.StatusAccepted)
+}
+
+// RedeemMagicLink godoc
+//
+//	@Summary		Sign in with a login link
+//	@Description	Consume a login link token and set authentication cookie, or return a pending challenge when two-factor authentication is needed
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		magicLinkPayload					true	"Login link token"
+//	@Success		200		{object}	nil									"Login successful, cookie set"
+//	@Success		202		{object}	DataResponse[mfaChallengeResponse]	"Second factor required"
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/auth/magic-link/redeem [post]
+func (app *application) redeemMagicLinkHandler(w http.ResponseWriter, r *http.Request) {
+	payload := &magicLinkPayload{}
+	if err := readJSON(w, r, payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	user, err := app.store.Users.RedeemLoginToken(r.Context(), utils.Hash(payload.Token))
+	if err != nil {
+		switch err {
+		case store.ErrInvalidToken:
+			app.unauthorizedError(w, r, false, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	app.completeLogin(w, r, user)
+}
+
 // LogoutUser godoc
 //
 //	@Summary		User logout
-//	@Description	Clear JWT cookie and log out the user
+//	@Description	Revoke the current session and clear the authentication cookies
 //	@Tags			auth
 //	@Produce		json
 //	@Success		200	{object}	nil	"Logged out successfully"
+//	@Failure		500	{object}	ErrorResponse
 //	@Router			/auth/logout [post]
 func (app *application) logoutUserHandler(w http.ResponseWriter, r *http.Request) {
-	// Clear the JWT cookie
-	http.SetCookie(w, &http.Cookie{
-		Name:     "jwt",
-		Value:    "",
-		Path:     "/",
-		MaxAge:   -1, // expire immediately
-		HttpOnly: true,
-		Secure:   app.config.env == "production",
-		SameSite: http.SameSiteStrictMode,
-	})
+	ctx := r.Context()
+	if cookie, err := r.Cookie("jwt"); err == nil {
+		if claims, err := app.parseAccessToken(cookie.Value); err == nil {
+			audit := func() *store.AuditEntry {
+				return auditEntry(r, claims.userID, "auth.logout", "session", claims.sessionID, nil)
+			}
+			if err := app.store.Sessions.Revoke(ctx, claims.sessionID, audit); err != nil {
+				app.internalServerError(w, r, err)
+				return
+			}
+		}
+	}
+	if cookie, err := r.Cookie(refreshCookieName); err == nil {
+		if err := app.store.Sessions.RevokeByRefreshToken(ctx, utils.Hash(cookie.Value)); err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+	}
+	app.clearSessionCookies(w)
 	w.WriteHeader(http.StatusOK)
 }
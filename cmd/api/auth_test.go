@@ -0,0 +1,462 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samuel032khoury/gopherfeed/internal/email"
+	"github.com/samuel032khoury/gopherfeed/internal/lockout"
+	"github.com/samuel032khoury/gopherfeed/internal/mq/publisher"
+	"github.com/samuel032khoury/gopherfeed/internal/ratelimiter"
+	"github.com/samuel032khoury/gopherfeed/internal/store"
+	"github.com/samuel032khoury/gopherfeed/internal/utils"
+)
+
+func TestForgotPassword(t *testing.T) {
+	app := newTestApplication(t)
+	mux := app.mount()
+
+	t.Run("should not reveal whether the email exists", func(t *testing.T) {
+		body := strings.NewReader(`{"email":"nobody@example.com"}`)
+		req, err := http.NewRequest(http.MethodPost, "/v1/auth/password/forgot", body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusAccepted, rr.Code)
+		app.tasks.Wait()
+	})
+
+	t.Run("should reject malformed emails", func(t *testing.T) {
+		body := strings.NewReader(`{"email":"not-an-email"}`)
+		req, err := http.NewRequest(http.MethodPost, "/v1/auth/password/forgot", body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("should email accounts after answering", func(t *testing.T) {
+		// taken@example.com is the only registered address
+		app.store.Users = &emailChangeUserStore{}
+		emails := publisher.NewMockPublisher()
+		app.emailPublisher = emails
+
+		for _, address := range []string{"taken@example.com", "nobody@example.com"} {
+			body := strings.NewReader(`{"email":"` + address + `"}`)
+			req, err := http.NewRequest(http.MethodPost, "/v1/auth/password/forgot", body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rr := execRequest(req, mux)
+			checkResponseCode(t, http.StatusAccepted, rr.Code)
+		}
+		app.tasks.Wait()
+
+		sent := emails.Sent()
+		if len(sent) != 1 || sent[0].To != "taken@example.com" || sent[0].Template != email.PasswordResetTemplate {
+			t.Errorf("expected one reset email to taken@example.com; got %+v", sent)
+		}
+	})
+
+	t.Run("should limit requests per address", func(t *testing.T) {
+		limiter, err := ratelimiter.NewFixedWindowLimiter(1, "1m")
+		if err != nil {
+			t.Fatal(err)
+		}
+		app.resetLimiter = limiter
+
+		codes := []int{http.StatusAccepted, http.StatusTooManyRequests}
+		for _, code := range codes {
+			body := strings.NewReader(`{"email":"Victim@example.com"}`)
+			req, err := http.NewRequest(http.MethodPost, "/v1/auth/password/forgot", body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rr := execRequest(req, mux)
+			checkResponseCode(t, code, rr.Code)
+		}
+		app.tasks.Wait()
+	})
+}
+
+func TestPersonalAccessTokens(t *testing.T) {
+	app := newTestApplication(t)
+	mux := app.mount()
+
+	// The mock token store grants only the posts:read scope
+	t.Run("should reject routes outside the token scopes", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/v1/feeds", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer gfp_test")
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("should not allow tokens to manage tokens", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/v1/users/me/tokens", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer gfp_test")
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusForbidden, rr.Code)
+	})
+}
+
+func TestCSRFProtection(t *testing.T) {
+	app := newTestApplication(t)
+	mux := app.mount()
+
+	newLogoutRequest := func(t *testing.T) *http.Request {
+		req, err := http.NewRequest(http.MethodPost, "/v1/auth/logout", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.AddCookie(&http.Cookie{Name: "jwt", Value: "token"})
+		req.AddCookie(&http.Cookie{Name: csrfCookieName, Value: "csrf-token"})
+		return req
+	}
+
+	t.Run("should reject cookie requests without the header", func(t *testing.T) {
+		rr := execRequest(newLogoutRequest(t), mux)
+		checkResponseCode(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("should reject a mismatched header", func(t *testing.T) {
+		req := newLogoutRequest(t)
+		req.Header.Set(csrfHeader, "other-token")
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("should accept a matching header", func(t *testing.T) {
+		req := newLogoutRequest(t)
+		req.Header.Set(csrfHeader, "csrf-token")
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusOK, rr.Code)
+	})
+}
+
+func TestMagicLink(t *testing.T) {
+	app := newTestApplication(t)
+	mux := app.mount()
+
+	t.Run("should not reveal whether the email exists", func(t *testing.T) {
+		body := strings.NewReader(`{"email":"nobody@example.com"}`)
+		req, err := http.NewRequest(http.MethodPost, "/v1/auth/magic-link", body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusAccepted, rr.Code)
+	})
+
+	t.Run("should reject unknown tokens", func(t *testing.T) {
+		body := strings.NewReader(`{"token":"123e4567-e89b-42d3-a456-426614174000"}`)
+		req, err := http.NewRequest(http.MethodPost, "/v1/auth/magic-link/redeem", body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusUnauthorized, rr.Code)
+	})
+}
+
+func TestPasskeyLogin(t *testing.T) {
+	app := newTestApplication(t)
+	mux := app.mount()
+
+	t.Run("should issue a challenge with a ceremony cookie", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/v1/auth/passkeys/login/begin", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		if !strings.Contains(rr.Header().Get("Set-Cookie"), passkeyCeremonyCookieName+"=") {
+			t.Error("expected the ceremony cookie to be set")
+		}
+	})
+
+	t.Run("should reject assertions without a ceremony", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/v1/auth/passkeys/login/finish", strings.NewReader(`{}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("should consume the ceremony on the first answer", func(t *testing.T) {
+		ceremonies := &ceremonyPasskeyStore{ceremonies: map[string]*store.PasskeyCeremony{}}
+		app.store.Passkeys = ceremonies
+
+		req, err := http.NewRequest(http.MethodPost, "/v1/auth/passkeys/login/begin", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		cookies := rr.Result().Cookies()
+		if len(cookies) != 1 || len(ceremonies.ceremonies) != 1 {
+			t.Fatalf("expected one ceremony cookie and one stored ceremony")
+		}
+		if _, ok := ceremonies.ceremonies[utils.Hash(cookies[0].Value)]; !ok {
+			t.Fatal("expected the cookie to reference the stored ceremony")
+		}
+
+		req, err = http.NewRequest(http.MethodPost, "/v1/auth/passkeys/login/finish", strings.NewReader(`{}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.AddCookie(cookies[0])
+		rr = execRequest(req, mux)
+		checkResponseCode(t, http.StatusUnauthorized, rr.Code)
+		if len(ceremonies.ceremonies) != 0 {
+			t.Error("expected the ceremony to be consumed")
+		}
+	})
+}
+
+// ceremonyPasskeyStore keeps passkey ceremonies in memory
+type ceremonyPasskeyStore struct {
+	store.MockPasskeyStore
+	ceremonies map[string]*store.PasskeyCeremony
+}
+
+func (s *ceremonyPasskeyStore) CreateCeremony(ctx context.Context, ceremony *store.PasskeyCeremony, exp time.Duration) error {
+	s.ceremonies[ceremony.ID] = ceremony
+	return nil
+}
+
+func (s *ceremonyPasskeyStore) ConsumeCeremony(ctx context.Context, id, purpose string) (*store.PasskeyCeremony, error) {
+	ceremony, ok := s.ceremonies[id]
+	if !ok || ceremony.Purpose != purpose {
+		return nil, nil
+	}
+	delete(s.ceremonies, id)
+	return ceremony, nil
+}
+
+func TestImpersonation(t *testing.T) {
+	app := newTestApplication(t)
+	mux := app.mount()
+
+	// The mock session store only knows regular, non-impersonated sessions
+	t.Run("should reject an act claim the session does not carry", func(t *testing.T) {
+		token, err := app.generateImpersonationToken(1, 2, "test-session", "test-jti", utils.ImpersonationExpiry)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, err := http.NewRequest(http.MethodGet, "/v1/feeds", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("should not mark regular sessions as impersonated", func(t *testing.T) {
+		token, err := app.generateAccessToken(1, "test-session", "test-jti")
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, err := http.NewRequest(http.MethodGet, "/v1/feeds", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		if rr.Header().Get(impersonatedByHeader) != "" {
+			t.Errorf("unexpected %s header", impersonatedByHeader)
+		}
+	})
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	app := newTestApplication(t)
+	mux := app.mount()
+
+	t.Run("should reject weak passwords on registration with field errors", func(t *testing.T) {
+		body := strings.NewReader(`{"username":"gopher","email":"gopher@example.com","password":"gopher1234"}`)
+		req, err := http.NewRequest(http.MethodPost, "/v1/auth/register", body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusBadRequest, rr.Code)
+
+		var response FieldErrorResponse
+		if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		if len(response.Fields["password"]) == 0 {
+			t.Errorf("expected password field errors; got %+v", response)
+		}
+	})
+
+	t.Run("should reject weak passwords on reset", func(t *testing.T) {
+		body := strings.NewReader(`{"token":"123e4567-e89b-42d3-a456-426614174000","password":"aaaaaaaaaaaa"}`)
+		req, err := http.NewRequest(http.MethodPost, "/v1/auth/password/reset", body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestConfirmEmailChange(t *testing.T) {
+	app := newTestApplication(t)
+	mux := app.mount()
+
+	// The mock user store holds no pending email changes
+	t.Run("should reject unknown tokens", func(t *testing.T) {
+		body := strings.NewReader(`{"token":"123e4567-e89b-42d3-a456-426614174000"}`)
+		req, err := http.NewRequest(http.MethodPost, "/v1/auth/email/confirm", body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+// pendingUserStore has one inactive account, pending@example.com, and
+// reports how many accounts a purge removes
+type pendingUserStore struct {
+	store.MockUserStore
+	purged    int64
+	purgeErr  error
+	purgeRuns int
+}
+
+func (s *pendingUserStore) RotateInvitation(ctx context.Context, email, token string, exp time.Duration) (*store.User, error) {
+	if email != "pending@example.com" {
+		return nil, nil
+	}
+	return &store.User{ID: 4, Username: "pending", Email: email}, nil
+}
+
+func (s *pendingUserStore) PurgeUnactivated(ctx context.Context) (int64, error) {
+	s.purgeRuns++
+	return s.purged, s.purgeErr
+}
+
+func TestResendActivation(t *testing.T) {
+	tests := []struct {
+		name  string
+		email string
+		sent  int
+	}{
+		{"should email a new link to pending accounts", "pending@example.com", 1},
+		{"should not reveal unknown emails", "nobody@example.com", 0},
+		// The mock user test@example.com is already active
+		{"should not email active accounts", "test@example.com", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			mux := app.mount()
+			app.store.Users = &pendingUserStore{}
+			emails := publisher.NewMockPublisher()
+			app.emailPublisher = emails
+
+			body := strings.NewReader(`{"email":"` + tt.email + `"}`)
+			req, err := http.NewRequest(http.MethodPost, "/v1/auth/activate/resend", body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rr := execRequest(req, mux)
+			checkResponseCode(t, http.StatusAccepted, rr.Code)
+
+			sent := emails.Sent()
+			if len(sent) != tt.sent {
+				t.Fatalf("expected %d emails; got %d", tt.sent, len(sent))
+			}
+			if tt.sent > 0 && (sent[0].To != tt.email || sent[0].Template != email.UserInviteTemplate) {
+				t.Errorf("expected an invitation to %s; got %+v", tt.email, sent[0])
+			}
+		})
+	}
+}
+
+func TestPurgeUnactivatedUsers(t *testing.T) {
+	t.Run("should purge unactivated users", func(t *testing.T) {
+		app := newTestApplication(t)
+		users := &pendingUserStore{purged: 2}
+		app.store.Users = users
+
+		if err := app.purgeUnactivatedUsers(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if users.purgeRuns != 1 {
+			t.Errorf("expected one purge; got %d", users.purgeRuns)
+		}
+	})
+
+	t.Run("should report store failures", func(t *testing.T) {
+		app := newTestApplication(t)
+		app.store.Users = &pendingUserStore{purgeErr: errors.New("connection reset")}
+
+		if err := app.purgeUnactivatedUsers(context.Background()); err == nil {
+			t.Error("expected the purge to fail")
+		}
+	})
+}
+
+// lockingTracker locks the account on every failure
+type lockingTracker struct {
+	lockout.MockTracker
+}
+
+func (t *lockingTracker) Fail(ctx context.Context, key string) (lockout.Status, error) {
+	return lockout.Status{Failures: 5, Wait: 15 * time.Minute, Locked: true}, nil
+}
+
+func TestAccountLockedEmail(t *testing.T) {
+	app := newTestApplication(t)
+	mux := app.mount()
+	app.store.Users = &emailChangeUserStore{}
+	app.loginAttempts.account = &lockingTracker{}
+	limiter, err := ratelimiter.NewFixedWindowLimiter(1, "1m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.lockNoticeLimiter = limiter
+	emails := publisher.NewMockPublisher()
+	app.emailPublisher = emails
+
+	token, err := app.generateAccessToken(2, "test-session", "test-jti")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should email the owner once however often the account is locked", func(t *testing.T) {
+		for range 3 {
+			body := strings.NewReader(`{"current_password":"wrong","new_password":"quiet-Harbor-38-violin"}`)
+			req, err := http.NewRequest(http.MethodPut, "/v1/users/me/password", body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+			rr := execRequest(req, mux)
+			checkResponseCode(t, http.StatusUnauthorized, rr.Code)
+		}
+		sent := emails.Sent()
+		if len(sent) != 1 || sent[0].Template != email.AccountLockedTemplate {
+			t.Errorf("expected one account locked email; got %+v", sent)
+		}
+	})
+}
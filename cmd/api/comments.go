@@ -1,11 +1,19 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"strconv"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/samuel032khoury/gopherfeed/internal/store"
 )
 
+type commentKey string
+
+const commentKeyCtx commentKey = "comment"
+
 // CommentDTO represents the payload for creating a comment
 //
 //	@Description	Comment creation payload
@@ -29,7 +37,11 @@ type CommentDTO struct {
 //	@Failure		500		{object}	ErrorResponse
 //	@Router			/posts/{postID}/comments [post]
 func (app *application) createCommentHandler(w http.ResponseWriter, r *http.Request) {
-	postID := getPostFromContext(r).ID
+	post := getPostFromContext(r)
+	if post.Status != store.PostStatusPublished {
+		app.badRequestError(w, r, errors.New("cannot comment on an unpublished post"))
+		return
+	}
 	var payload CommentDTO
 	if err := readJSON(w, r, &payload); err != nil {
 		app.badRequestError(w, r, err)
@@ -39,10 +51,9 @@ func (app *application) createCommentHandler(w http.ResponseWriter, r *http.Requ
 		app.badRequestError(w, r, err)
 		return
 	}
-	userId := 1 // placeholder until we have authentication
 	comment := &store.Comment{
-		PostID:  postID,
-		UserID:  int64(userId),
+		PostID:  post.ID,
+		UserID:  getCurrentUserFromContext(r).ID,
 		Content: payload.Content,
 	}
 	ctx := r.Context()
@@ -52,3 +63,73 @@ func (app *application) createCommentHandler(w http.ResponseWriter, r *http.Requ
 	}
 	app.jsonResponse(w, comment, http.StatusCreated)
 }
+
+// DeleteComment godoc
+//
+//	@Summary		Delete a comment
+//	@Description	Move a comment to the trash. It can be restored until the retention period runs out. Comments by other users require the comment.delete.any permission.
+//	@Tags			comments
+//	@Produce		json
+//	@Param			postID		path		int	true	"Post ID"
+//	@Param			commentID	path		int	true	"Comment ID"
+//	@Success		204			{object}	nil	"Comment deleted successfully"
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		401			{object}	ErrorResponse	"Unauthorized - login required"
+//	@Failure		403			{object}	ErrorResponse
+//	@Failure		404			{object}	ErrorResponse
+//	@Failure		500			{object}	ErrorResponse
+//	@Router			/posts/{postID}/comments/{commentID} [delete]
+func (app *application) deleteCommentHandler(w http.ResponseWriter, r *http.Request) {
+	comment := getCommentFromContext(r)
+	actor := getCurrentUserFromContext(r)
+	before := map[string]any{
+		"post_id": comment.PostID,
+		"user_id": comment.UserID,
+		"content": comment.Content,
+	}
+	audit := func() *store.AuditEntry {
+		return auditChangeEntry(r, actor.ID, "comment.delete", "comment", strconv.FormatInt(comment.ID, 10), before, nil)
+	}
+	if err := app.store.Comments.Delete(r.Context(), comment.ID, actor.ID, audit); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundError(w, r)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CommentParamMiddleware loads the comment of the current post named by the
+// commentID URL parameter.
+func (app *application) CommentParamMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		commentID, err := strconv.ParseInt(chi.URLParam(r, "commentID"), 10, 64)
+		if err != nil {
+			app.badRequestError(w, r, err)
+			return
+		}
+		ctx := r.Context()
+		comment, err := app.store.Comments.GetByID(ctx, commentID)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		if comment == nil || comment.PostID != getPostFromContext(r).ID {
+			app.notFoundError(w, r)
+			return
+		}
+		ctx = context.WithValue(ctx, commentKeyCtx, comment)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func getCommentFromContext(r *http.Request) *store.Comment {
+	comment, ok := r.Context().Value(commentKeyCtx).(*store.Comment)
+	if !ok {
+		return nil
+	}
+	return comment
+}
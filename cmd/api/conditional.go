@@ -0,0 +1,65 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/samuel032khoury/gopherfeed/internal/store"
+)
+
+// postETag is the entity tag of a post. It only changes with the post's
+// version and the version of the renderer its HTML is from, so new comments
+// do not change it.
+func postETag(post *store.Post) string {
+	return `"` + strconv.Itoa(post.Version) + "." + strconv.Itoa(post.RenderVersion) + `"`
+}
+
+// etagMatches reports whether etag is in the list of entity tags of an
+// If-Match or If-None-Match header. Weak tags only match under the weak
+// comparison If-None-Match uses.
+func etagMatches(header, etag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified writes a 304 response and returns true if the request's
+// If-None-Match header matches etag.
+func notModified(w http.ResponseWriter, r *http.Request, etag string) bool {
+	header := r.Header.Get("If-None-Match")
+	if header == "" || !etagMatches(header, etag, true) {
+		return false
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// checkIfMatch writes a 412 response and returns false if the request has an
+// If-Match header that does not match the post's current ETag. Requests
+// without the header are let through unless required is set.
+func (app *application) checkIfMatch(w http.ResponseWriter, r *http.Request, post *store.Post, required bool) bool {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		if required {
+			app.preconditionRequiredError(w, r)
+			return false
+		}
+		return true
+	}
+	if !etagMatches(header, postETag(post), false) {
+		app.preconditionFailedError(w, r)
+		return false
+	}
+	return true
+}
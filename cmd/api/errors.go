@@ -2,6 +2,8 @@ package main
 
 import (
 	"net/http"
+
+	"github.com/samuel032khoury/gopherfeed/internal/store"
 )
 
 func (app *application) internalServerError(w http.ResponseWriter, r *http.Request, err error) {
@@ -14,6 +16,11 @@ func (app *application) badRequestError(w http.ResponseWriter, r *http.Request,
 	writeJSONError(w, err.Error(), http.StatusBadRequest)
 }
 
+func (app *application) fieldValidationError(w http.ResponseWriter, r *http.Request, message string, fields map[string][]string) {
+	app.logger.Warnw("field validation error", "method", r.Method, "path", r.URL.Path, "fields", fields)
+	writeJSON(w, &FieldErrorResponse{Error: message, Fields: fields}, http.StatusBadRequest)
+}
+
 func (app *application) notFoundError(w http.ResponseWriter, r *http.Request) {
 	app.logger.Warnw("not found", "method", r.Method, "path", r.URL.Path)
 	writeJSONError(w, "resource not found", http.StatusNotFound)
@@ -24,6 +31,21 @@ func (app *application) conflictError(w http.ResponseWriter, r *http.Request, er
 	writeJSONError(w, "edit conflict occurred", http.StatusConflict)
 }
 
+func (app *application) preconditionFailedError(w http.ResponseWriter, r *http.Request) {
+	app.logger.Warnw("precondition failed", "method", r.Method, "path", r.URL.Path, "if_match", r.Header.Get("If-Match"))
+	writeJSONError(w, "the resource has changed since it was fetched", http.StatusPreconditionFailed)
+}
+
+func (app *application) preconditionRequiredError(w http.ResponseWriter, r *http.Request) {
+	app.logger.Warnw("precondition required", "method", r.Method, "path", r.URL.Path)
+	writeJSONError(w, "this request requires an If-Match header", http.StatusPreconditionRequired)
+}
+
+func (app *application) unsupportedMediaTypeError(w http.ResponseWriter, r *http.Request, expected string) {
+	app.logger.Warnw("unsupported media type", "method", r.Method, "path", r.URL.Path, "content_type", r.Header.Get("Content-Type"))
+	writeJSONError(w, "the request body must be "+expected, http.StatusUnsupportedMediaType)
+}
+
 func (app *application) unauthorizedError(w http.ResponseWriter, r *http.Request, isBasicAuth bool, err error) {
 	app.logger.Warnw("unauthorized", "method", r.Method, "path", r.URL.Path, "error", err.Error())
 	if isBasicAuth {
@@ -36,6 +58,16 @@ func (app *application) forbiddenError(w http.ResponseWriter, r *http.Request) {
 	writeJSONError(w, "forbidden", http.StatusForbidden)
 }
 
+func (app *application) suspendedError(w http.ResponseWriter, r *http.Request, suspension *store.Suspension) {
+	app.logger.Warnw("account suspended", "method", r.Method, "path", r.URL.Path, "user_id", suspension.UserID, "scope", suspension.Scope)
+	writeJSON(w, &SuspendedErrorResponse{Error: "account suspended", Suspension: newSuspensionNotice(suspension)}, http.StatusForbidden)
+}
+
+func (app *application) csrfError(w http.ResponseWriter, r *http.Request, err error) {
+	app.logger.Warnw("csrf check failed", "method", r.Method, "path", r.URL.Path, "error", err.Error())
+	writeJSONError(w, "invalid or missing CSRF token", http.StatusForbidden)
+}
+
 func (app *application) rateLimitExceededError(w http.ResponseWriter, r *http.Request, retryAfter string) {
 	app.logger.Warnw("rate limit exceeded", "method", r.Method, "path", r.URL.Path)
 	w.Header().Set("Retry-After", retryAfter)
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+// publishBatchSize is how many scheduled posts are published per query
+const publishBatchSize = 100
+
+// rerenderBatchSize is how many posts are rendered again per transaction
+const rerenderBatchSize = 100
+
+func (app *application) startBackgroundJobs(ctx context.Context) {
+	app.schedule(ctx, "invitation-cleanup", app.config.jobs.invitationCleanupInterval, app.purgeUnactivatedUsers)
+	app.schedule(ctx, "suspension-expiry", app.config.jobs.suspensionExpiryInterval, app.liftExpiredSuspensions)
+	app.schedule(ctx, "post-publisher", app.config.jobs.postPublishInterval, app.publishScheduledPosts)
+	app.schedule(ctx, "post-rerender", app.config.jobs.postRerenderInterval, app.rerenderStalePosts)
+
+	retention, err := time.ParseDuration(app.config.jobs.trashRetention)
+	if err != nil || retention <= 0 {
+		app.logger.Warnw("background job disabled", "job", "trash-purge", "retention", app.config.jobs.trashRetention)
+		return
+	}
+	app.schedule(ctx, "trash-purge", app.config.jobs.trashPurgeInterval, func(ctx context.Context) error {
+		return app.purgeTrash(ctx, retention)
+	})
+}
+
+// schedule runs job every interval until ctx is cancelled. An invalid
+// interval disables the job instead of stopping the server.
+func (app *application) schedule(ctx context.Context, name, interval string, job func(context.Context) error) {
+	duration, err := time.ParseDuration(interval)
+	if err != nil || duration <= 0 {
+		app.logger.Warnw("background job disabled", "job", name, "interval", interval)
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(duration)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := job(ctx); err != nil {
+					app.logger.Errorw("background job failed", "job", name, "error", err)
+				}
+			}
+		}
+	}()
+	app.logger.Infow("background job scheduled", "job", name, "interval", duration)
+}
+
+// background runs task after the request that started it has been answered.
+// The server waits for started tasks before it shuts down.
+func (app *application) background(name string, task func(context.Context) error) {
+	app.tasks.Add(1)
+	go func() {
+		defer app.tasks.Done()
+		defer func() {
+			if err := recover(); err != nil {
+				app.logger.Errorw("background task panicked", "task", name, "error", err)
+			}
+		}()
+		if err := task(context.Background()); err != nil {
+			app.logger.Errorw("background task failed", "task", name, "error", err)
+		}
+	}()
+}
+
+func (app *application) purgeUnactivatedUsers(ctx context.Context) error {
+	count, err := app.store.Users.PurgeUnactivated(ctx)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		app.logger.Infow("purged unactivated users", "count", count)
+	}
+	return nil
+}
+
+// publishScheduledPosts publishes every scheduled post that is due. Several
+// instances may run it at once; the store makes sure each post is published
+// by only one of them.
+func (app *application) publishScheduledPosts(ctx context.Context) error {
+	var total int64
+	for {
+		count, err := app.store.Posts.PublishDue(ctx, publishBatchSize)
+		if err != nil {
+			return err
+		}
+		total += count
+		if count < publishBatchSize {
+			break
+		}
+	}
+	if total > 0 {
+		app.logger.Infow("published scheduled posts", "count", total)
+	}
+	return nil
+}
+
+// rerenderStalePosts renders again the HTML of every post rendered by an
+// older version of the markdown renderer, including the posts that existed
+// before content was rendered at all.
+func (app *application) rerenderStalePosts(ctx context.Context) error {
+	var total int64
+	for {
+		count, err := app.store.Posts.RerenderStale(ctx, rerenderBatchSize)
+		if err != nil {
+			return err
+		}
+		total += count
+		if count < rerenderBatchSize {
+			break
+		}
+	}
+	if total > 0 {
+		app.logger.Infow("rendered stale posts", "count", total)
+	}
+	return nil
+}
+
+// liftExpiredSuspensions tells users whose suspension has run out that they
+// have been reinstated. The suspensions stopped applying when they expired;
+// this only records that and sends the emails.
+func (app *application) liftExpiredSuspensions(ctx context.Context) error {
+	suspensions, err := app.store.Suspensions.LiftExpired(ctx)
+	if err != nil {
+		return err
+	}
+	for _, suspension := range suspensions {
+		// One failed lookup must not cost everyone after it their email
+		user, err := app.store.Users.GetByID(ctx, suspension.UserID)
+		if err != nil {
+			app.logger.Errorw("failed to load reinstated user", "user_id", suspension.UserID, "error", err)
+			continue
+		}
+		if user == nil {
+			continue
+		}
+		// The user may have been suspended again in the meantime
+		active, err := app.store.Suspensions.GetActive(ctx, user.ID)
+		if err != nil {
+			app.logger.Errorw("failed to check suspension of reinstated user", "user_id", user.ID, "error", err)
+			continue
+		}
+		if active == nil {
+			app.sendReinstatedEmail(user)
+		}
+	}
+	if len(suspensions) > 0 {
+		app.logger.Infow("lifted expired suspensions", "count", len(suspensions))
+	}
+	return nil
+}
+
+// purgeTrash permanently deletes the posts and comments that have been in
+// the trash longer than retention.
+func (app *application) purgeTrash(ctx context.Context, retention time.Duration) error {
+	posts, err := app.store.Posts.PurgeDeleted(ctx, retention)
+	if err != nil {
+		return err
+	}
+	comments, err := app.store.Comments.PurgeDeleted(ctx, retention)
+	if err != nil {
+		return err
+	}
+	if posts > 0 || comments > 0 {
+		app.logger.Infow("purged trash", "posts", posts, "comments", comments)
+	}
+	return nil
+}
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"mime"
 	"net/http"
 
 	"github.com/go-playground/validator/v10"
@@ -27,6 +30,23 @@ type ErrorResponse struct {
 	Error string `json:"error" example:"Something went wrong"`
 }
 
+// FieldErrorResponse represents a request rejected because of specific fields
+//
+//	@Description	Field-level validation error response
+type FieldErrorResponse struct {
+	Error  string              `json:"error" example:"invalid password"`
+	Fields map[string][]string `json:"fields"`
+}
+
+// SuspendedErrorResponse represents a request refused because the account is
+// suspended
+//
+//	@Description	Account suspended error response
+type SuspendedErrorResponse struct {
+	Error      string            `json:"error" example:"account suspended"`
+	Suspension *suspensionNotice `json:"suspension"`
+}
+
 func readJSON(w http.ResponseWriter, r *http.Request, data any) error {
 	maxBytes := 1_048_576 // 1 MB
 	r.Body = http.MaxBytesReader(w, r.Body, int64(maxBytes))
@@ -35,6 +55,64 @@ func readJSON(w http.ResponseWriter, r *http.Request, data any) error {
 	return decoder.Decode(data)
 }
 
+// mergePatchType is the media type of JSON Merge Patch documents
+const mergePatchType = "application/merge-patch+json"
+
+// isMergePatch reports whether the request body is declared as a JSON Merge
+// Patch, so that a full document sent to PATCH is not taken for a patch.
+func isMergePatch(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == mergePatchType
+}
+
+// readMergePatch applies the JSON Merge Patch (RFC 7396) in the request body
+// to original, which must encode to a JSON object, and decodes the result
+// into data. Members the patch sets to null are removed, so they decode to
+// their zero value.
+func readMergePatch(w http.ResponseWriter, r *http.Request, original, data any) error {
+	var patch map[string]any
+	if err := readJSON(w, r, &patch); err != nil {
+		return err
+	}
+	if patch == nil {
+		return errors.New("the patch must be a JSON object")
+	}
+	current, err := json.Marshal(original)
+	if err != nil {
+		return err
+	}
+	var target map[string]any
+	if err := json.Unmarshal(current, &target); err != nil {
+		return err
+	}
+	merged, err := json.Marshal(mergePatch(target, patch))
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(merged))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(data)
+}
+
+func mergePatch(target, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = map[string]any{}
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = mergePatch(targetObject[key], value)
+	}
+	return targetObject
+}
+
 func writeJSON(w http.ResponseWriter, data any, status int) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/samuel032khoury/gopherfeed/internal/email"
+	"github.com/samuel032khoury/gopherfeed/internal/store"
+)
+
+func loginAccountKey(email string) string {
+	return "login:" + strings.ToLower(email)
+}
+
+func mfaAccountKey(userID int64) string {
+	return "mfa:" + strconv.FormatInt(userID, 10)
+}
+
+// allowLoginAttempt writes a 429 response and returns false while the account
+// or the client IP has to wait before trying again.
+func (app *application) allowLoginAttempt(w http.ResponseWriter, r *http.Request, accountKey string) bool {
+	ctx := r.Context()
+	wait, err := app.loginAttempts.account.Check(ctx, accountKey)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return false
+	}
+	ipWait, err := app.loginAttempts.ip.Check(ctx, clientIP(r))
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return false
+	}
+	wait = max(wait, ipWait)
+	if wait > 0 {
+		app.rateLimitExceededError(w, r, strconv.Itoa(int(wait.Round(time.Second).Seconds())))
+		return false
+	}
+	return true
+}
+
+// recordLoginFailure counts a failed attempt against the account and the
+// client IP. The account owner is notified when the account gets locked, at
+// most once per failure window. Errors are only logged since the request has
+// failed already.
+func (app *application) recordLoginFailure(ctx context.Context, r *http.Request, accountKey string, lookup func() (*store.User, error)) {
+	if _, err := app.loginAttempts.ip.Fail(ctx, clientIP(r)); err != nil {
+		app.logger.Errorw("failed to record login failure", "ip", clientIP(r), "error", err)
+	}
+	status, err := app.loginAttempts.account.Fail(ctx, accountKey)
+	if err != nil {
+		app.logger.Errorw("failed to record login failure", "key", accountKey, "error", err)
+		return
+	}
+	if !status.Locked {
+		return
+	}
+	app.logger.Warnw("account locked", "key", accountKey, "failures", status.Failures, "ip", clientIP(r))
+	user, err := lookup()
+	if err != nil || user == nil {
+		return
+	}
+	if allow, _ := app.lockNoticeLimiter.Allow(strconv.FormatInt(user.ID, 10)); !allow {
+		return
+	}
+	vars := struct {
+		Username  string
+		LockedFor string
+	}{
+		Username:  user.Username,
+		LockedFor: status.Wait.String(),
+	}
+	if err := app.emailPublisher.Publish(user.Email, email.AccountLockedTemplate, vars); err != nil {
+		app.logger.Errorw("failed to send account locked email", "email", user.Email, "error", err)
+	}
+}
+
+// resetLoginFailures clears the account's failures after a successful login.
+// The client IP is left alone so that owning one account does not reset the
+// budget for guessing others.
+func (app *application) resetLoginFailures(ctx context.Context, accountKey string) {
+	if err := app.loginAttempts.account.Reset(ctx, accountKey); err != nil {
+		app.logger.Errorw("failed to reset login failures", "key", accountKey, "error", err)
+	}
+}
+
+// unlockAccount lifts a lockout on both the password and the MFA step.
+func (app *application) unlockAccount(ctx context.Context, user *store.User) error {
+	for _, key := range []string{loginAccountKey(user.Email), mfaAccountKey(user.ID)} {
+		if err := app.loginAttempts.account.Reset(ctx, key); err != nil {
+			return fmt.Errorf("failed to reset %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// clientIP returns the address set by middleware.RealIP without the port.
+func clientIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
@@ -1,14 +1,21 @@
 package main
 
 import (
+	"context"
 	"expvar"
 	"log"
 	"runtime"
+	"strings"
+	"time"
 
+	"github.com/go-redis/redis/v8"
+	"github.com/go-webauthn/webauthn/webauthn"
 	"github.com/samuel032khoury/gopherfeed/internal/auth"
 	"github.com/samuel032khoury/gopherfeed/internal/db"
 	"github.com/samuel032khoury/gopherfeed/internal/env"
+	"github.com/samuel032khoury/gopherfeed/internal/lockout"
 	"github.com/samuel032khoury/gopherfeed/internal/mq/publisher"
+	"github.com/samuel032khoury/gopherfeed/internal/password"
 	"github.com/samuel032khoury/gopherfeed/internal/ratelimiter"
 	"github.com/samuel032khoury/gopherfeed/internal/store"
 	"github.com/samuel032khoury/gopherfeed/internal/store/cache"
@@ -55,14 +62,35 @@ func main() {
 	}
 	defer db.Close()
 	logger.Info("database connection pool established")
-	store := store.NewPostgresStorage(db)
+
+	passwordHasher, err := password.NewArgon2idHasher(password.Params{
+		Memory:      uint32(cfg.auth.password.memory),
+		Iterations:  uint32(cfg.auth.password.iterations),
+		Parallelism: uint8(cfg.auth.password.parallelism),
+		SaltLength:  password.DefaultParams.SaltLength,
+		KeyLength:   password.DefaultParams.KeyLength,
+	})
+	if err != nil {
+		logger.Fatal("failed to configure password hashing:", err)
+	}
+	store := store.NewPostgresStorage(db, passwordHasher, logger)
+
+	passwordPolicy := &password.Policy{MinEntropy: float64(cfg.auth.password.minEntropy)}
+	if path := cfg.auth.password.breachedList; path != "" {
+		passwordPolicy.Breached, err = password.LoadBreachedList(path)
+		if err != nil {
+			logger.Fatal("failed to load breached password list:", err)
+		}
+		logger.Infow("breached password list loaded", "hashes", passwordPolicy.Breached.Len())
+	}
 
 	// =========================================================================
 	// Cache (Optional)
 	// =========================================================================
 	var cacheStorage *cache.CacheStorage
+	var redisClient *redis.Client
 	if cfg.cache.enabled {
-		redisClient := cache.NewRedisClient(
+		redisClient = cache.NewRedisClient(
 			cfg.cache.redisAddr,
 			cfg.cache.redisPassword,
 			cfg.cache.redisDB,
@@ -90,12 +118,60 @@ func main() {
 	// =========================================================================
 	// Authentication
 	// =========================================================================
-	jwtAuthenticator := auth.NewJWTAuthenticator(
-		cfg.auth.jwt.secretKey,
-		cfg.auth.jwt.tokenDuration,
-		cfg.auth.jwt.iss,
-		cfg.auth.jwt.aud,
-	)
+	var authenticator auth.Authenticator
+	if cfg.auth.jwt.keysDir != "" {
+		authenticator, err = auth.NewKeySetAuthenticator(
+			cfg.auth.jwt.keysDir,
+			cfg.auth.jwt.activeKeyID,
+			cfg.auth.jwt.tokenDuration,
+			cfg.auth.jwt.iss,
+			cfg.auth.jwt.aud,
+		)
+		if err != nil {
+			logger.Fatal("failed to load JWT signing keys:", err)
+		}
+		logger.Infow("asymmetric JWT signing enabled", "kid", cfg.auth.jwt.activeKeyID)
+	} else {
+		authenticator = auth.NewJWTAuthenticator(
+			cfg.auth.jwt.secretKey,
+			cfg.auth.jwt.tokenDuration,
+			cfg.auth.jwt.iss,
+			cfg.auth.jwt.aud,
+		)
+	}
+
+	// =========================================================================
+	// External Identity Providers (Optional)
+	// =========================================================================
+	identityProviders := make(map[string]auth.IdentityProvider)
+	if cfg.auth.oidc.issuerURL != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		provider, err := auth.NewOIDCProvider(ctx, auth.OIDCConfig{
+			Name:         cfg.auth.oidc.providerName,
+			IssuerURL:    cfg.auth.oidc.issuerURL,
+			ClientID:     cfg.auth.oidc.clientID,
+			ClientSecret: cfg.auth.oidc.clientSecret,
+			RedirectURL:  cfg.auth.oidc.redirectURL,
+		})
+		cancel()
+		if err != nil {
+			logger.Fatal("failed to set up OIDC provider:", err)
+		}
+		identityProviders[provider.Name()] = provider
+		logger.Infow("OIDC login enabled", "provider", provider.Name())
+	}
+
+	// =========================================================================
+	// Passkeys
+	// =========================================================================
+	webAuthn, err := webauthn.New(&webauthn.Config{
+		RPID:          cfg.auth.passkey.rpID,
+		RPDisplayName: cfg.auth.passkey.rpName,
+		RPOrigins:     strings.Split(cfg.auth.passkey.origins, ","),
+	})
+	if err != nil {
+		logger.Fatal("failed to configure passkeys:", err)
+	}
 
 	// =========================================================================
 	// Rate Limiter
@@ -107,18 +183,88 @@ func main() {
 	if err != nil {
 		logger.Fatal("failed to create rate limiter:", err)
 	}
+	magicLinkLimiter, err := ratelimiter.NewFixedWindowLimiter(
+		cfg.magicLink.quota,
+		cfg.magicLink.interval,
+	)
+	if err != nil {
+		logger.Fatal("failed to create magic link rate limiter:", err)
+	}
+	// Reset links are emailed to an address as often as login links
+	resetLimiter, err := ratelimiter.NewFixedWindowLimiter(
+		cfg.magicLink.quota,
+		cfg.magicLink.interval,
+	)
+	if err != nil {
+		logger.Fatal("failed to create password reset rate limiter:", err)
+	}
+
+	// =========================================================================
+	// Login Lockout
+	// =========================================================================
+	lockoutCfg := cfg.auth.lockout
+	accountPolicy, err := lockout.NewPolicy(
+		lockoutCfg.freeAttempts,
+		lockoutCfg.baseDelay,
+		lockoutCfg.maxDelay,
+		lockoutCfg.threshold,
+		lockoutCfg.duration,
+		lockoutCfg.failureWindow,
+	)
+	if err != nil {
+		logger.Fatal("failed to create account lockout policy:", err)
+	}
+	ipPolicy, err := lockout.NewPolicy(
+		lockoutCfg.ipFreeAttempts,
+		lockoutCfg.baseDelay,
+		lockoutCfg.maxDelay,
+		lockoutCfg.ipThreshold,
+		lockoutCfg.duration,
+		lockoutCfg.failureWindow,
+	)
+	if err != nil {
+		logger.Fatal("failed to create IP lockout policy:", err)
+	}
+	// One account locked email per account and failure window, however often
+	// someone locks it
+	lockNoticeLimiter, err := ratelimiter.NewFixedWindowLimiter(1, lockoutCfg.failureWindow)
+	if err != nil {
+		logger.Fatal("failed to create account locked email rate limiter:", err)
+	}
+	var loginAttempts loginTrackers
+	if redisClient != nil {
+		// Shared between instances so attempts cannot be spread across them
+		loginAttempts = loginTrackers{
+			account: lockout.NewRedisTracker(redisClient, "lockout:account", accountPolicy),
+			ip:      lockout.NewRedisTracker(redisClient, "lockout:ip", ipPolicy),
+		}
+	} else {
+		loginAttempts = loginTrackers{
+			account: lockout.NewMemoryTracker(accountPolicy),
+			ip:      lockout.NewMemoryTracker(ipPolicy),
+		}
+	}
 
 	// =========================================================================
 	// Application
 	// =========================================================================
 	app := &application{
-		config:         cfg,
-		store:          store,
-		cacheStorage:   cacheStorage,
-		logger:         logger,
-		emailPublisher: emailPublisher,
-		authenticator:  jwtAuthenticator,
-		ratelimiter:    limiter,
+		config:            cfg,
+		store:             store,
+		cacheStorage:      cacheStorage,
+		logger:            logger,
+		emailPublisher:    emailPublisher,
+		authenticator:     authenticator,
+		passwordHasher:    passwordHasher,
+		passwordPolicy:    passwordPolicy,
+		ratelimiter:       limiter,
+		loginAttempts:     loginAttempts,
+		magicLinkLimiter:  magicLinkLimiter,
+		resetLimiter:      resetLimiter,
+		lockNoticeLimiter: lockNoticeLimiter,
+		identityProviders: identityProviders,
+		webauthn:          webAuthn,
+		permissions:       newPermissionCache(permissionCacheTTL),
 	}
 
 	// =========================================================================
@@ -163,9 +309,44 @@ func loadConfig() config {
 			},
 			jwt: jwtConfig{
 				secretKey:     env.GetString("JWT_SECRET_KEY", "your-secret-key"),
-				tokenDuration: env.GetString("JWT_TOKEN_DURATION", "24h"),
+				tokenDuration: env.GetString("JWT_TOKEN_DURATION", "15m"),
 				iss:           env.GetString("JWT_ISSUER", "gopherfeed-api"),
 				aud:           env.GetString("JWT_AUDIENCE", "gopherfeed"),
+				keysDir:       env.GetString("JWT_KEYS_DIR", ""),
+				activeKeyID:   env.GetString("JWT_ACTIVE_KEY_ID", ""),
+			},
+			mfa: mfaConfig{
+				issuer:            env.GetString("MFA_ISSUER", "GopherFeed"),
+				requiredRoleLevel: env.GetInt("MFA_REQUIRED_ROLE_LEVEL", 0),
+			},
+			oidc: oidcConfig{
+				providerName: env.GetString("OIDC_PROVIDER_NAME", "oidc"),
+				issuerURL:    env.GetString("OIDC_ISSUER_URL", ""),
+				clientID:     env.GetString("OIDC_CLIENT_ID", ""),
+				clientSecret: env.GetString("OIDC_CLIENT_SECRET", ""),
+				redirectURL:  env.GetString("OIDC_REDIRECT_URL", "http://localhost:5173/oauth/callback"),
+			},
+			password: passwordConfig{
+				memory:       env.GetInt("PASSWORD_ARGON2_MEMORY_KIB", int(password.DefaultParams.Memory)),
+				iterations:   env.GetInt("PASSWORD_ARGON2_ITERATIONS", int(password.DefaultParams.Iterations)),
+				parallelism:  env.GetInt("PASSWORD_ARGON2_PARALLELISM", int(password.DefaultParams.Parallelism)),
+				minEntropy:   env.GetInt("PASSWORD_MIN_ENTROPY_BITS", 40),
+				breachedList: env.GetString("PASSWORD_BREACHED_LIST", ""),
+			},
+			passkey: passkeyConfig{
+				rpID:    env.GetString("WEBAUTHN_RP_ID", "localhost"),
+				rpName:  env.GetString("WEBAUTHN_RP_NAME", "GopherFeed"),
+				origins: env.GetString("WEBAUTHN_RP_ORIGINS", "http://localhost:5173"),
+			},
+			lockout: lockoutConfig{
+				freeAttempts:   env.GetInt("LOGIN_FREE_ATTEMPTS", 3),
+				ipFreeAttempts: env.GetInt("LOGIN_IP_FREE_ATTEMPTS", 10),
+				baseDelay:      env.GetString("LOGIN_BASE_DELAY", "1s"),
+				maxDelay:       env.GetString("LOGIN_MAX_DELAY", "30s"),
+				threshold:      env.GetInt("LOGIN_LOCKOUT_THRESHOLD", 10),
+				ipThreshold:    env.GetInt("LOGIN_IP_LOCKOUT_THRESHOLD", 50),
+				duration:       env.GetString("LOGIN_LOCKOUT_DURATION", "15m"),
+				failureWindow:  env.GetString("LOGIN_FAILURE_WINDOW", "1h"),
 			},
 		},
 		cache: cacheConfig{
@@ -178,6 +359,18 @@ func loadConfig() config {
 			quota:    env.GetInt("RATE_LIMITER_QUOTA", 100),
 			interval: env.GetString("RATE_LIMITER_INTERVAL", "5s"),
 		},
+		magicLink: ratelimiterConfig{
+			quota:    env.GetInt("MAGIC_LINK_QUOTA", 3),
+			interval: env.GetString("MAGIC_LINK_INTERVAL", "15m"),
+		},
+		jobs: jobsConfig{
+			invitationCleanupInterval: env.GetString("INVITATION_CLEANUP_INTERVAL", "1h"),
+			suspensionExpiryInterval:  env.GetString("SUSPENSION_EXPIRY_INTERVAL", "5m"),
+			postPublishInterval:       env.GetString("POST_PUBLISH_INTERVAL", "1m"),
+			postRerenderInterval:      env.GetString("POST_RERENDER_INTERVAL", "5m"),
+			trashPurgeInterval:        env.GetString("TRASH_PURGE_INTERVAL", "1h"),
+			trashRetention:            env.GetString("TRASH_RETENTION", "720h"),
+		},
 		env: env.GetString("ENV", "development"),
 	}
 }
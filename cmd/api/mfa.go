@@ -0,0 +1,440 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/samuel032khoury/gopherfeed/internal/auth"
+	"github.com/samuel032khoury/gopherfeed/internal/store"
+	"github.com/samuel032khoury/gopherfeed/internal/utils"
+)
+
+type mfaKey string
+
+const (
+	mfaChallengeKeyCtx  mfaKey = "mfa_challenge"
+	mfaChallengePurpose        = "mfa_challenge"
+	mfaChallengeHeader         = "X-MFA-Challenge"
+	recoveryCodeCount          = 10
+)
+
+// mfaChallengeResponse is returned by login when a second factor is needed
+//
+//	@Description	Pending two-factor authentication challenge
+type mfaChallengeResponse struct {
+	Challenge          string `json:"challenge" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	EnrollmentRequired bool   `json:"enrollment_required" example:"false"`
+}
+
+// mfaVerifyPayload represents the payload for completing a pending MFA login
+//
+//	@Description	MFA verification payload, either a TOTP code or a recovery code
+type mfaVerifyPayload struct {
+	Challenge    string `json:"challenge" validate:"required"`
+	Code         string `json:"code" validate:"required_without=RecoveryCode,omitempty,len=6,numeric" example:"123456"`
+	RecoveryCode string `json:"recovery_code" validate:"required_without=Code,omitempty,max=16" example:"abcd-efgh"`
+}
+
+// mfaCodePayload represents a payload carrying a TOTP code
+//
+//	@Description	TOTP code payload
+type mfaCodePayload struct {
+	Code string `json:"code" validate:"required,len=6,numeric" example:"123456"`
+}
+
+// totpEnrollmentResponse represents a pending TOTP enrollment
+//
+//	@Description	TOTP secret and provisioning URI for authenticator apps
+type totpEnrollmentResponse struct {
+	Secret string `json:"secret" example:"JBSWY3DPEHPK3PXP"`
+	URI    string `json:"otpauth_uri" example:"otpauth://totp/GopherFeed:user1@example.com?secret=JBSWY3DPEHPK3PXP"`
+}
+
+// recoveryCodesResponse represents freshly generated recovery codes
+//
+//	@Description	Single-use recovery codes, only shown once
+type recoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes" example:"abcd-efgh,ijkl-mnop"`
+}
+
+// VerifyMFA godoc
+//
+//	@Summary		Complete a two-factor login
+//	@Description	Exchange a pending MFA challenge and a TOTP or recovery code for a session
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		mfaVerifyPayload	true	"Challenge and code"
+//	@Success		200		{object}	nil					"Login successful, cookie set"
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		429		{object}	ErrorResponse	"Too many failed attempts, see Retry-After"
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/auth/mfa/verify [post]
+func (app *application) verifyMFAHandler(w http.ResponseWriter, r *http.Request) {
+	payload := &mfaVerifyPayload{}
+	if err := readJSON(w, r, payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	claims, err := app.parsePurposeToken(payload.Challenge, mfaChallengePurpose)
+	if err != nil {
+		app.unauthorizedError(w, r, false, err)
+		return
+	}
+	userID, err := userIDFromClaims(claims)
+	if err != nil {
+		app.unauthorizedError(w, r, false, err)
+		return
+	}
+
+	ctx := r.Context()
+	accountKey := mfaAccountKey(userID)
+	if !app.allowLoginAttempt(w, r, accountKey) {
+		return
+	}
+	mfa, err := app.store.MFA.Get(ctx, userID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if mfa == nil || !mfa.Confirmed {
+		app.badRequestError(w, r, fmt.Errorf("two-factor authentication is not enabled"))
+		return
+	}
+	if payload.Code != "" {
+		err = app.useTOTPCode(ctx, mfa, payload.Code)
+	} else {
+		err = app.store.MFA.UseRecoveryCode(ctx, userID, utils.Hash(normalizeRecoveryCode(payload.RecoveryCode)))
+	}
+	if err != nil {
+		switch err {
+		case store.ErrInvalidMFACode:
+			app.recordLoginFailure(ctx, r, accountKey, func() (*store.User, error) {
+				return app.store.Users.GetByID(ctx, userID)
+			})
+			app.unauthorizedError(w, r, false, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	app.resetLoginFailures(ctx, accountKey)
+	if !app.allowLogin(w, r, userID) {
+		return
+	}
+	if err := app.startSession(w, r, userID); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// EnrollTOTP godoc
+//
+//	@Summary		Start TOTP enrollment
+//	@Description	Generate a TOTP secret for the current user. Users whose role requires MFA can enroll during login by passing the pending challenge header.
+//	@Tags			auth
+//	@Produce		json
+//	@Param			X-MFA-Challenge	header		string									false	"Pending MFA challenge"
+//	@Success		200				{object}	DataResponse[totpEnrollmentResponse]	"Pending enrollment created"
+//	@Failure		400				{object}	ErrorResponse
+//	@Failure		401				{object}	ErrorResponse
+//	@Failure		500				{object}	ErrorResponse
+//	@Router			/auth/mfa/totp [post]
+func (app *application) enrollTOTPHandler(w http.ResponseWriter, r *http.Request) {
+	user := getCurrentUserFromContext(r)
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.store.MFA.Enroll(r.Context(), user.ID, secret); err != nil {
+		switch err {
+		case store.ErrMFAAlreadyEnabled:
+			app.badRequestError(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	response := totpEnrollmentResponse{
+		Secret: secret,
+		URI:    auth.TOTPURI(secret, app.config.auth.mfa.issuer, user.Email),
+	}
+	app.jsonResponse(w, response, http.StatusOK)
+}
+
+// ConfirmTOTP godoc
+//
+//	@Summary		Confirm TOTP enrollment
+//	@Description	Activate the pending TOTP secret with a valid code and receive recovery codes. When enrolling with a pending challenge, this also completes the login.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			X-MFA-Challenge	header		string								false	"Pending MFA challenge"
+//	@Param			payload			body		mfaCodePayload						true	"TOTP code"
+//	@Success		200				{object}	DataResponse[recoveryCodesResponse]	"Two-factor authentication enabled"
+//	@Failure		400				{object}	ErrorResponse
+//	@Failure		401				{object}	ErrorResponse
+//	@Failure		500				{object}	ErrorResponse
+//	@Router			/auth/mfa/totp/confirm [post]
+func (app *application) confirmTOTPHandler(w http.ResponseWriter, r *http.Request) {
+	payload := &mfaCodePayload{}
+	if err := readJSON(w, r, payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	user := getCurrentUserFromContext(r)
+	// Checked up front so a suspended user is not left with codes they
+	// never saw
+	if isMFAChallenge(r) && !app.allowLogin(w, r, user.ID) {
+		return
+	}
+	ctx := r.Context()
+	mfa, err := app.store.MFA.Get(ctx, user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if mfa == nil {
+		app.badRequestError(w, r, fmt.Errorf("no pending two-factor enrollment"))
+		return
+	}
+	if mfa.Confirmed {
+		app.badRequestError(w, r, store.ErrMFAAlreadyEnabled)
+		return
+	}
+	counter, ok := auth.ValidateTOTP(mfa.Secret, payload.Code, time.Now())
+	if !ok {
+		app.badRequestError(w, r, store.ErrInvalidMFACode)
+		return
+	}
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.store.MFA.Confirm(ctx, user.ID, counter, hashes); err != nil {
+		switch err {
+		case store.ErrMFAAlreadyEnabled:
+			app.badRequestError(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	if isMFAChallenge(r) {
+		if err := app.startSession(w, r, user.ID); err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+	}
+	app.jsonResponse(w, recoveryCodesResponse{RecoveryCodes: codes}, http.StatusOK)
+}
+
+// DisableTOTP godoc
+//
+//	@Summary		Disable TOTP
+//	@Description	Turn off two-factor authentication for the current user
+//	@Tags			auth
+//	@Accept			json
+//	@Param			payload	body		mfaCodePayload	true	"TOTP code"
+//	@Success		204		{object}	nil				"Two-factor authentication disabled"
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/auth/mfa/totp [delete]
+func (app *application) disableTOTPHandler(w http.ResponseWriter, r *http.Request) {
+	payload := &mfaCodePayload{}
+	if err := readJSON(w, r, payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	user := getCurrentUserFromContext(r)
+	ctx := r.Context()
+	required, err := app.mfaRequired(ctx, user)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if required {
+		app.badRequestError(w, r, fmt.Errorf("two-factor authentication is required for your role"))
+		return
+	}
+	if err := app.verifyCurrentTOTP(ctx, user.ID, payload.Code); err != nil {
+		switch err {
+		case store.ErrInvalidMFACode:
+			app.badRequestError(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	if err := app.store.MFA.Disable(ctx, user.ID); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RegenerateRecoveryCodes godoc
+//
+//	@Summary		Regenerate recovery codes
+//	@Description	Invalidate all recovery codes and issue a new set
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		mfaCodePayload						true	"TOTP code"
+//	@Success		200		{object}	DataResponse[recoveryCodesResponse]	"New recovery codes"
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/auth/mfa/recovery-codes [post]
+func (app *application) regenerateRecoveryCodesHandler(w http.ResponseWriter, r *http.Request) {
+	payload := &mfaCodePayload{}
+	if err := readJSON(w, r, payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	user := getCurrentUserFromContext(r)
+	ctx := r.Context()
+	if err := app.verifyCurrentTOTP(ctx, user.ID, payload.Code); err != nil {
+		switch err {
+		case store.ErrInvalidMFACode:
+			app.badRequestError(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.store.MFA.ReplaceRecoveryCodes(ctx, user.ID, hashes); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, recoveryCodesResponse{RecoveryCodes: codes}, http.StatusOK)
+}
+
+// completeLogin finishes a first-factor login: it either starts a session or,
+// when the user has MFA enabled or their role requires it, responds with a
+// pending challenge instead of setting the session cookies.
+func (app *application) completeLogin(w http.ResponseWriter, r *http.Request, user *store.User) {
+	if !app.allowLogin(w, r, user.ID) {
+		return
+	}
+	ctx := r.Context()
+	mfa, err := app.store.MFA.Get(ctx, user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	enrolled := mfa != nil && mfa.Confirmed
+	if !enrolled {
+		required, err := app.mfaRequired(ctx, user)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		if !required {
+			if err := app.startSession(w, r, user.ID); err != nil {
+				app.internalServerError(w, r, err)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+	}
+
+	challenge, err := app.generatePurposeToken(jwt.MapClaims{"sub": user.ID}, mfaChallengePurpose, utils.MFAChallengeExpiry)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	response := mfaChallengeResponse{
+		Challenge:          challenge,
+		EnrollmentRequired: !enrolled,
+	}
+	app.jsonResponse(w, response, http.StatusAccepted)
+}
+
+// mfaRequired reports whether the user's role is at or above the configured
+// MFA role level. A level of zero disables the requirement.
+func (app *application) mfaRequired(ctx context.Context, user *store.User) (bool, error) {
+	threshold := app.config.auth.mfa.requiredRoleLevel
+	if threshold <= 0 {
+		return false, nil
+	}
+	role, err := app.store.Roles.GetByID(ctx, user.RoleID)
+	if err != nil {
+		return false, err
+	}
+	return role.Level >= threshold, nil
+}
+
+func (app *application) useTOTPCode(ctx context.Context, mfa *store.MFA, code string) error {
+	counter, ok := auth.ValidateTOTP(mfa.Secret, code, time.Now())
+	if !ok {
+		return store.ErrInvalidMFACode
+	}
+	return app.store.MFA.UseCounter(ctx, mfa.UserID, counter)
+}
+
+func (app *application) verifyCurrentTOTP(ctx context.Context, userID int64, code string) error {
+	mfa, err := app.store.MFA.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if mfa == nil || !mfa.Confirmed {
+		return store.ErrInvalidMFACode
+	}
+	return app.useTOTPCode(ctx, mfa, code)
+}
+
+func generateRecoveryCodes() (codes []string, hashes []string, err error) {
+	codes, err = auth.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, nil, err
+	}
+	hashes = make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = utils.Hash(code)
+	}
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.TrimSpace(code))
+}
+
+func isMFAChallenge(r *http.Request) bool {
+	viaChallenge, _ := r.Context().Value(mfaChallengeKeyCtx).(bool)
+	return viaChallenge
+}
@@ -5,18 +5,31 @@ import (
 	"encoding/base64"
 	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/samuel032khoury/gopherfeed/internal/auth"
 	"github.com/samuel032khoury/gopherfeed/internal/store"
+	"github.com/samuel032khoury/gopherfeed/internal/utils"
 )
 
 type currUserKey string
 
 const currUserKeyCtx currUserKey = "curr_user"
 
+type tokenScopesKey string
+
+const tokenScopesKeyCtx tokenScopesKey = "token_scopes"
+
+type impersonatorKey string
+
+const impersonatorKeyCtx impersonatorKey = "impersonator"
+
+// impersonatedByHeader marks every response served to an impersonation token
+const impersonatedByHeader = "X-Impersonated-By"
+
 func (app *application) RateLimitMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if allow, retryAfter := app.ratelimiter.Allow(r.RemoteAddr); !allow {
@@ -45,6 +58,22 @@ func (app *application) PostParamMiddleware(next http.Handler) http.Handler {
 			app.notFoundError(w, r)
 			return
 		}
+		// Unpublished posts only exist for their author and for those who
+		// may edit any post
+		if post.Status != store.PostStatusPublished {
+			user := getCurrentUserFromContext(r)
+			visible := user.ID == post.UserID
+			if !visible {
+				if visible, err = app.hasPermission(ctx, user.RoleID, permPostUpdateAny); err != nil {
+					app.internalServerError(w, r, err)
+					return
+				}
+			}
+			if !visible {
+				app.notFoundError(w, r)
+				return
+			}
+		}
 		ctx = context.WithValue(ctx, postKeyCtx, post)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
@@ -104,74 +133,178 @@ func (app *application) BasicAuthMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// TokenAuthMiddleware authenticates the request with either the jwt session
+// cookie or an Authorization bearer header carrying a JWT or a personal access
+// token. Personal access tokens are limited to their scopes, see RequireScope.
+// Requests made with an admin's impersonation token are flagged in the
+// response and, unless read-only, recorded in the audit log. Suspended users
+// are limited to what their suspension allows, see suspensionAllows.
 func (app *application) TokenAuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Get JWT token from cookie
-		cookie, err := r.Cookie("jwt")
-		if err != nil {
-			app.unauthorizedError(w, r, false, fmt.Errorf("missing authentication cookie"))
-			return
-		}
-		token := cookie.Value
-		jwtToken, err := app.authenticator.ValidateToken(token)
-		if err != nil {
-			app.unauthorizedError(w, r, false, fmt.Errorf("invalid or expired token"))
-			return
-		}
-		claims, ok := jwtToken.Claims.(jwt.MapClaims)
-		if !ok || !jwtToken.Valid {
-			app.unauthorizedError(w, r, false, fmt.Errorf("invalid token claims"))
-			return
+		ctx := r.Context()
+		token, isBearer := bearerToken(r)
+		if !isBearer {
+			// Get JWT token from cookie
+			cookie, err := r.Cookie("jwt")
+			if err != nil {
+				app.unauthorizedError(w, r, false, fmt.Errorf("missing authentication cookie"))
+				return
+			}
+			token = cookie.Value
 		}
-		userID, err := strconv.ParseInt(fmt.Sprintf("%.f", claims["sub"]), 10, 64)
-		if err != nil || userID <= 0 {
-			app.unauthorizedError(w, r, false, fmt.Errorf("invalid user ID in token claims"))
-			return
+
+		var userID int64
+		if isBearer && strings.HasPrefix(token, auth.PersonalAccessTokenPrefix) {
+			pat, err := app.store.Tokens.GetByToken(ctx, utils.Hash(token))
+			if err != nil {
+				app.internalServerError(w, r, err)
+				return
+			}
+			if pat == nil {
+				app.unauthorizedError(w, r, false, fmt.Errorf("invalid or expired access token"))
+				return
+			}
+			userID = pat.UserID
+			ctx = context.WithValue(ctx, tokenScopesKeyCtx, pat.Scopes)
+		} else {
+			claims, err := app.parseAccessToken(token)
+			if err != nil {
+				app.unauthorizedError(w, r, false, err)
+				return
+			}
+			session, err := app.store.Sessions.GetActive(ctx, claims.sessionID)
+			if err != nil {
+				app.internalServerError(w, r, err)
+				return
+			}
+			if session == nil {
+				app.unauthorizedError(w, r, false, fmt.Errorf("session revoked or expired"))
+				return
+			}
+			if claims.jti != session.AccessJTI {
+				app.unauthorizedError(w, r, false, fmt.Errorf("access token has been superseded"))
+				return
+			}
+			// The act claim must agree with the session it was issued for
+			var impersonatorID int64
+			if session.ImpersonatorID != nil {
+				impersonatorID = *session.ImpersonatorID
+			}
+			if claims.actorID != impersonatorID {
+				app.unauthorizedError(w, r, false, fmt.Errorf("invalid actor claim"))
+				return
+			}
+			userID = claims.userID
+			ctx = context.WithValue(ctx, sessionKeyCtx, session)
+			if impersonatorID != 0 {
+				ctx = context.WithValue(ctx, impersonatorKeyCtx, impersonatorID)
+				w.Header().Set(impersonatedByHeader, strconv.FormatInt(impersonatorID, 10))
+			}
 		}
 
-		ctx := r.Context()
 		user, err := app.getUser(ctx, userID)
-		if err != nil {
+		if err != nil || user == nil {
 			app.unauthorizedError(w, r, false, fmt.Errorf("user not found"))
 			return
 		}
+		suspension, err := app.store.Suspensions.GetActive(ctx, user.ID)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		if suspension != nil {
+			if !suspensionAllows(r, suspension) {
+				app.suspendedError(w, r, suspension)
+				return
+			}
+			ctx = context.WithValue(ctx, suspensionKeyCtx, suspension)
+		}
 		ctx = context.WithValue(ctx, currUserKeyCtx, user)
-		next.ServeHTTP(w, r.WithContext(ctx))
+		r = r.WithContext(ctx)
+		if impersonatorID, ok := getImpersonatorFromContext(r); ok && r.Method != http.MethodGet {
+			metadata := map[string]any{
+				"method": r.Method,
+				"path":   r.URL.Path,
+			}
+			if err := app.audit(r, impersonatorID, "impersonation.request", "user", strconv.FormatInt(user.ID, 10), metadata); err != nil {
+				app.internalServerError(w, r, err)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
 	})
 }
 
-func (app *application) RBACMiddleware(requiredRole string) func(next http.Handler) http.Handler {
+// RequireScope restricts personal access tokens to routes covered by their
+// scopes. Session-authenticated requests are not scoped and always pass.
+func (app *application) RequireScope(scope string) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			user := getCurrentUserFromContext(r)
-			post := getPostFromContext(r)
-			if post.UserID != user.ID {
-				if allowed, err := app.checkRolePermissions(r.Context(), user.RoleID, requiredRole); err != nil {
-					app.internalServerError(w, r, err)
-					return
-				} else if !allowed {
-					app.forbiddenError(w, r)
-					return
-				}
+			scopes, scoped := getTokenScopesFromContext(r)
+			if scoped && !slices.Contains(scopes, scope) {
+				app.forbiddenError(w, r)
+				return
 			}
 			next.ServeHTTP(w, r)
 		})
 	}
 }
 
-func (app *application) checkRolePermissions(ctx context.Context, userRoleID int64, requiredRoleName string) (bool, error) {
-	requiredRole, err := app.store.Roles.GetByName(ctx, requiredRoleName)
-	if err != nil {
-		return false, err
-	}
-	if requiredRole == nil {
-		return false, fmt.Errorf("required role not found")
-	}
-	userRole, err := app.store.Roles.GetByID(ctx, userRoleID)
-	if err != nil {
-		return false, err
-	}
-	return userRole.Level >= requiredRole.Level, nil
+// DenyPersonalAccessTokens restricts a route to interactive sessions, e.g. so
+// that a leaked token cannot be used to mint further tokens.
+func (app *application) DenyPersonalAccessTokens(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, scoped := getTokenScopesFromContext(r); scoped {
+			app.forbiddenError(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// DenyImpersonation keeps sensitive account actions, such as changing
+// credentials or MFA, out of reach of an admin impersonating the user.
+func (app *application) DenyImpersonation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, impersonated := getImpersonatorFromContext(r); impersonated {
+			app.forbiddenError(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// MFAEnrollmentAuthMiddleware authenticates either a regular session or a
+// pending MFA challenge, so users whose role requires MFA can enroll before
+// their first session is issued.
+func (app *application) MFAEnrollmentAuthMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		challenge := r.Header.Get(mfaChallengeHeader)
+		if challenge == "" {
+			app.TokenAuthMiddleware(next).ServeHTTP(w, r)
+			return
+		}
+		claims, err := app.parsePurposeToken(challenge, mfaChallengePurpose)
+		if err != nil {
+			app.unauthorizedError(w, r, false, err)
+			return
+		}
+		userID, err := userIDFromClaims(claims)
+		if err != nil {
+			app.unauthorizedError(w, r, false, err)
+			return
+		}
+
+		ctx := r.Context()
+		user, err := app.getUser(ctx, userID)
+		if err != nil || user == nil {
+			app.unauthorizedError(w, r, false, fmt.Errorf("user not found"))
+			return
+		}
+		ctx = context.WithValue(ctx, currUserKeyCtx, user)
+		ctx = context.WithValue(ctx, mfaChallengeKeyCtx, true)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
 }
 
 func (app *application) getUser(ctx context.Context, userID int64) (*store.User, error) {
@@ -195,3 +328,31 @@ func (app *application) getUser(ctx context.Context, userID int64) (*store.User,
 	}
 	return user, nil
 }
+
+// invalidateUser drops the cached copy of a user whose record has changed.
+func (app *application) invalidateUser(ctx context.Context, userID int64) error {
+	if !app.config.cache.enabled {
+		return nil
+	}
+	return app.cacheStorage.Users.Delete(ctx, userID)
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	parts := strings.SplitN(r.Header.Get("Authorization"), " ", 2)
+	if len(parts) != 2 || parts[0] != "Bearer" || parts[1] == "" {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// getImpersonatorFromContext returns the ID of the admin impersonating the
+// current user, if any.
+func getImpersonatorFromContext(r *http.Request) (int64, bool) {
+	impersonatorID, ok := r.Context().Value(impersonatorKeyCtx).(int64)
+	return impersonatorID, ok
+}
+
+func getTokenScopesFromContext(r *http.Request) ([]string, bool) {
+	scopes, ok := r.Context().Value(tokenScopesKeyCtx).([]string)
+	return scopes, ok
+}
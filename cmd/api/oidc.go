@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/samuel032khoury/gopherfeed/internal/auth"
+	"github.com/samuel032khoury/gopherfeed/internal/store"
+	"github.com/samuel032khoury/gopherfeed/internal/utils"
+)
+
+const (
+	oidcFlowCookieName = "oidc_flow"
+	oidcFlowPurpose    = "oidc_flow"
+)
+
+var errUnverifiedIdentityEmail = errors.New("identity provider did not return a verified email")
+
+// oidcCallbackPayload represents the parameters the provider redirected with
+//
+//	@Description	Authorization code and state returned by the identity provider
+type oidcCallbackPayload struct {
+	Code  string `json:"code" validate:"required" example:"4/0AX4XfWh..."`
+	State string `json:"state" validate:"required" example:"kQ3n1n1ZlW3y6m4n..."`
+}
+
+// OIDCLogin godoc
+//
+//	@Summary		Start an external login
+//	@Description	Redirect to the identity provider. The flow state is kept in a short-lived cookie that the callback endpoint checks.
+//	@Tags			auth
+//	@Param			provider	path		string	true	"Identity provider name"
+//	@Success		302			{object}	nil		"Redirect to the identity provider"
+//	@Failure		404			{object}	ErrorResponse
+//	@Failure		500			{object}	ErrorResponse
+//	@Router			/auth/oidc/{provider}/login [get]
+func (app *application) oidcLoginHandler(w http.ResponseWriter, r *http.Request) {
+	provider, ok := app.identityProviders[chi.URLParam(r, "provider")]
+	if !ok {
+		app.notFoundError(w, r)
+		return
+	}
+	secrets := make([]string, 3)
+	for i := range secrets {
+		secret, err := auth.GenerateOAuthSecret()
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		secrets[i] = secret
+	}
+	state, nonce, verifier := secrets[0], secrets[1], secrets[2]
+	flow, err := app.generatePurposeToken(jwt.MapClaims{
+		"provider": provider.Name(),
+		"state":    state,
+		"nonce":    nonce,
+		"verifier": verifier,
+	}, oidcFlowPurpose, utils.OIDCFlowExpiry)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	// Lax rather than Strict, the user comes back from the provider's site
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcFlowCookieName,
+		Value:    flow,
+		Path:     "/v1/auth/oidc",
+		MaxAge:   int(utils.OIDCFlowExpiry.Seconds()),
+		HttpOnly: true,
+		Secure:   app.config.env == "production",
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, provider.AuthCodeURL(state, nonce, verifier), http.StatusFound)
+}
+
+// OIDCCallback godoc
+//
+//	@Summary		Complete an external login
+//	@Description	Exchange the authorization code for the provider's identity and sign in the linked user. Unknown identities are linked to the user with the same verified email, or get a new active account.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			provider	path		string								true	"Identity provider name"
+//	@Param			payload		body		oidcCallbackPayload					true	"Code and state from the provider redirect"
+//	@Success		200			{object}	nil									"Login successful, cookie set"
+//	@Success		202			{object}	DataResponse[mfaChallengeResponse]	"Second factor required"
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		401			{object}	ErrorResponse
+//	@Failure		404			{object}	ErrorResponse
+//	@Failure		500			{object}	ErrorResponse
+//	@Router			/auth/oidc/{provider}/callback [post]
+func (app *application) oidcCallbackHandler(w http.ResponseWriter, r *http.Request) {
+	provider, ok := app.identityProviders[chi.URLParam(r, "provider")]
+	if !ok {
+		app.notFoundError(w, r)
+		return
+	}
+	payload := &oidcCallbackPayload{}
+	if err := readJSON(w, r, payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	cookie, err := r.Cookie(oidcFlowCookieName)
+	if err != nil {
+		app.unauthorizedError(w, r, false, fmt.Errorf("missing login flow cookie"))
+		return
+	}
+	// The flow is single use, whatever the outcome
+	app.clearOIDCFlowCookie(w)
+	claims, err := app.parsePurposeToken(cookie.Value, oidcFlowPurpose)
+	if err != nil {
+		app.unauthorizedError(w, r, false, err)
+		return
+	}
+	state, _ := claims["state"].(string)
+	nonce, _ := claims["nonce"].(string)
+	verifier, _ := claims["verifier"].(string)
+	if claims["provider"] != provider.Name() || subtle.ConstantTimeCompare([]byte(state), []byte(payload.State)) != 1 {
+		app.unauthorizedError(w, r, false, fmt.Errorf("login flow state mismatch"))
+		return
+	}
+
+	ctx := r.Context()
+	identity, err := provider.Exchange(ctx, payload.Code, verifier, nonce)
+	if err != nil {
+		app.unauthorizedError(w, r, false, err)
+		return
+	}
+	user, err := app.userForIdentity(ctx, identity)
+	if err != nil {
+		switch err {
+		case errUnverifiedIdentityEmail:
+			app.unauthorizedError(w, r, false, err)
+		case store.ErrUserDeactivated:
+			app.forbiddenError(w, r)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	app.completeLogin(w, r, user)
+}
+
+// userForIdentity resolves the user signing in with an external identity,
+// linking or creating an account on the first login.
+func (app *application) userForIdentity(ctx context.Context, identity *auth.ExternalIdentity) (*store.User, error) {
+	user, err := app.store.Identities.GetUser(ctx, identity.Provider, identity.Subject)
+	if err != nil || user != nil {
+		return user, err
+	}
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, errUnverifiedIdentityEmail
+	}
+	link := &store.Identity{
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+	}
+	user, err = app.store.Identities.LinkByEmail(ctx, link)
+	if err != nil || user != nil {
+		return user, err
+	}
+
+	// The account has no password, one can be set with a password reset
+	for attempt := range 3 {
+		user = &store.User{
+			Username: usernameFromEmail(identity.Email, attempt > 0),
+			Email:    identity.Email,
+		}
+		err = app.store.Identities.CreateUser(ctx, user, link)
+		if err != store.ErrDuplicateUsername {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	app.logger.Infow("user created from external identity", "user_id", user.ID, "provider", identity.Provider)
+	return user, nil
+}
+
+func (app *application) clearOIDCFlowCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcFlowCookieName,
+		Value:    "",
+		Path:     "/v1/auth/oidc",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   app.config.env == "production",
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// usernameFromEmail derives a username satisfying the registration rules from
+// the local part of the email, optionally with a random suffix.
+func usernameFromEmail(email string, withSuffix bool) string {
+	local, _, _ := strings.Cut(email, "@")
+	var b strings.Builder
+	for _, r := range strings.ToLower(local) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+		}
+	}
+	username := b.String()
+	if len(username) > 24 {
+		username = username[:24]
+	}
+	if len(username) < 3 {
+		username = "user" + username
+	}
+	if withSuffix {
+		n, _ := rand.Int(rand.Reader, big.NewInt(1_000_000))
+		username = fmt.Sprintf("%s%06d", username, n)
+	}
+	return username
+}
@@ -0,0 +1,401 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+	"github.com/samuel032khoury/gopherfeed/internal/store"
+	"github.com/samuel032khoury/gopherfeed/internal/utils"
+)
+
+const (
+	passkeyCeremonyCookieName  = "passkey_ceremony"
+	passkeyRegistrationPurpose = "passkey_registration"
+	passkeyLoginPurpose        = "passkey_login"
+	passkeyCeremonyExpiry      = 5 * time.Minute
+)
+
+var errPasskeyCeremony = errors.New("passkey ceremony missing, expired or already used")
+
+// passkeyRegistrationPayload represents the payload for starting a registration
+//
+//	@Description	Passkey registration payload
+type passkeyRegistrationPayload struct {
+	Name string `json:"name" validate:"required,max=100" example:"MacBook Touch ID"`
+}
+
+// passkeyUser adapts a user and their registered passkeys to webauthn.User.
+type passkeyUser struct {
+	*store.User
+	passkeys    []*store.Passkey
+	credentials []webauthn.Credential
+}
+
+func (u *passkeyUser) WebAuthnID() []byte {
+	return passkeyUserHandle(u.ID)
+}
+
+func (u *passkeyUser) WebAuthnName() string {
+	return u.Email
+}
+
+func (u *passkeyUser) WebAuthnDisplayName() string {
+	return u.Username
+}
+
+func (u *passkeyUser) WebAuthnCredentials() []webauthn.Credential {
+	return u.credentials
+}
+
+// passkeyFor returns the stored passkey of a credential that was just used.
+func (u *passkeyUser) passkeyFor(credentialID []byte) *store.Passkey {
+	for _, passkey := range u.passkeys {
+		if bytes.Equal(passkey.CredentialID, credentialID) {
+			return passkey
+		}
+	}
+	return nil
+}
+
+// BeginPasskeyRegistration godoc
+//
+//	@Summary		Start passkey registration
+//	@Description	Return the options for navigator.credentials.create. The ceremony state is kept server-side, referenced by a short-lived cookie.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		passkeyRegistrationPayload	true	"Passkey name"
+//	@Success		200		{object}	object						"PublicKeyCredentialCreationOptions"
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/auth/passkeys/register/begin [post]
+func (app *application) beginPasskeyRegistrationHandler(w http.ResponseWriter, r *http.Request) {
+	payload := &passkeyRegistrationPayload{}
+	if err := readJSON(w, r, payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	user, err := app.loadPasskeyUser(r.Context(), getCurrentUserFromContext(r))
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	creation, session, err := app.webauthn.BeginRegistration(
+		user,
+		webauthn.WithExclusions(webauthn.Credentials(user.credentials).CredentialDescriptors()),
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementRequired),
+	)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	ceremony := &store.PasskeyCeremony{
+		Purpose: passkeyRegistrationPurpose,
+		UserID:  &user.ID,
+		Name:    payload.Name,
+	}
+	if err := app.startPasskeyCeremony(r.Context(), w, ceremony, session); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, creation, http.StatusOK)
+}
+
+// FinishPasskeyRegistration godoc
+//
+//	@Summary		Finish passkey registration
+//	@Description	Verify the attestation returned by navigator.credentials.create and store the passkey
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			credential	body		object					true	"PublicKeyCredential from navigator.credentials.create"
+//	@Success		201			{object}	DataResponse[store.Passkey]	"Passkey registered"
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		401			{object}	ErrorResponse
+//	@Failure		500			{object}	ErrorResponse
+//	@Router			/auth/passkeys/register/finish [post]
+func (app *application) finishPasskeyRegistrationHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, err := app.loadPasskeyUser(ctx, getCurrentUserFromContext(r))
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	ceremony, session, err := app.passkeyCeremony(w, r, passkeyRegistrationPurpose)
+	if err != nil {
+		switch err {
+		case errPasskeyCeremony:
+			app.unauthorizedError(w, r, false, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	if ceremony.UserID == nil || *ceremony.UserID != user.ID {
+		app.unauthorizedError(w, r, false, fmt.Errorf("registration was started by another user"))
+		return
+	}
+	credential, err := app.webauthn.FinishRegistration(user, *session, r)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	data, err := json.Marshal(credential)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	passkey := &store.Passkey{
+		UserID:       user.ID,
+		Name:         ceremony.Name,
+		CredentialID: credential.ID,
+		Credential:   data,
+		SignCount:    int64(credential.Authenticator.SignCount),
+	}
+	if err := app.store.Passkeys.Create(ctx, passkey); err != nil {
+		switch err {
+		case store.ErrDuplicatePasskey:
+			app.badRequestError(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	app.jsonResponse(w, passkey, http.StatusCreated)
+}
+
+// BeginPasskeyLogin godoc
+//
+//	@Summary		Start passkey login
+//	@Description	Return the options for navigator.credentials.get. Any passkey registered with this site can answer.
+//	@Tags			auth
+//	@Produce		json
+//	@Success		200	{object}	object	"PublicKeyCredentialRequestOptions"
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/auth/passkeys/login/begin [post]
+func (app *application) beginPasskeyLoginHandler(w http.ResponseWriter, r *http.Request) {
+	assertion, session, err := app.webauthn.BeginDiscoverableLogin(
+		webauthn.WithUserVerification(protocol.VerificationRequired),
+	)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	ceremony := &store.PasskeyCeremony{Purpose: passkeyLoginPurpose}
+	if err := app.startPasskeyCeremony(r.Context(), w, ceremony, session); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, assertion, http.StatusOK)
+}
+
+// FinishPasskeyLogin godoc
+//
+//	@Summary		Finish passkey login
+//	@Description	Verify the assertion returned by navigator.credentials.get and set authentication cookie
+//	@Tags			auth
+//	@Accept			json
+//	@Param			credential	body		object	true	"PublicKeyCredential from navigator.credentials.get"
+//	@Success		200			{object}	nil		"Login successful, cookie set"
+//	@Failure		401			{object}	ErrorResponse
+//	@Failure		500			{object}	ErrorResponse
+//	@Router			/auth/passkeys/login/finish [post]
+func (app *application) finishPasskeyLoginHandler(w http.ResponseWriter, r *http.Request) {
+	_, session, err := app.passkeyCeremony(w, r, passkeyLoginPurpose)
+	if err != nil {
+		switch err {
+		case errPasskeyCeremony:
+			app.unauthorizedError(w, r, false, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	ctx := r.Context()
+	lookup := func(rawID, userHandle []byte) (webauthn.User, error) {
+		userID, err := strconv.ParseInt(string(userHandle), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		user, err := app.store.Users.GetByID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, fmt.Errorf("user not found")
+		}
+		return app.loadPasskeyUser(ctx, user)
+	}
+	webauthnUser, credential, err := app.webauthn.FinishPasskeyLogin(lookup, *session, r)
+	if err != nil {
+		app.unauthorizedError(w, r, false, err)
+		return
+	}
+	user := webauthnUser.(*passkeyUser)
+	if credential.Authenticator.CloneWarning {
+		app.logger.Warnw("passkey sign counter went backwards", "user_id", user.ID)
+		app.unauthorizedError(w, r, false, fmt.Errorf("passkey may have been cloned"))
+		return
+	}
+	passkey := user.passkeyFor(credential.ID)
+	if passkey == nil {
+		app.unauthorizedError(w, r, false, fmt.Errorf("unknown passkey"))
+		return
+	}
+	if err := app.store.Passkeys.RecordUse(ctx, passkey.ID, int64(credential.Authenticator.SignCount)); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if !app.allowLogin(w, r, user.ID) {
+		return
+	}
+	// User verification is required, so the passkey already is a second
+	// factor and no MFA challenge follows.
+	if err := app.startSession(w, r, user.ID); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// ListPasskeys godoc
+//
+//	@Summary		List passkeys
+//	@Description	List the passkeys registered by the current user
+//	@Tags			auth
+//	@Produce		json
+//	@Success		200	{object}	DataResponse[[]store.Passkey]
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/auth/passkeys [get]
+func (app *application) listPasskeysHandler(w http.ResponseWriter, r *http.Request) {
+	passkeys, err := app.store.Passkeys.ListByUser(r.Context(), getCurrentUserFromContext(r).ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, passkeys, http.StatusOK)
+}
+
+// DeletePasskey godoc
+//
+//	@Summary		Delete a passkey
+//	@Description	Remove one of the current user's passkeys
+//	@Tags			auth
+//	@Param			passkeyID	path		int	true	"Passkey ID"
+//	@Success		204			{object}	nil	"Passkey deleted"
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		401			{object}	ErrorResponse
+//	@Failure		404			{object}	ErrorResponse
+//	@Failure		500			{object}	ErrorResponse
+//	@Router			/auth/passkeys/{passkeyID} [delete]
+func (app *application) deletePasskeyHandler(w http.ResponseWriter, r *http.Request) {
+	passkeyID, err := strconv.ParseInt(chi.URLParam(r, "passkeyID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := app.store.Passkeys.Delete(r.Context(), passkeyID, getCurrentUserFromContext(r).ID); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundError(w, r)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (app *application) loadPasskeyUser(ctx context.Context, user *store.User) (*passkeyUser, error) {
+	passkeys, err := app.store.Passkeys.ListByUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	credentials := make([]webauthn.Credential, 0, len(passkeys))
+	for _, passkey := range passkeys {
+		var credential webauthn.Credential
+		if err := json.Unmarshal(passkey.Credential, &credential); err != nil {
+			return nil, fmt.Errorf("failed to decode passkey %d: %w", passkey.ID, err)
+		}
+		// The counter is kept up to date in its own column
+		credential.Authenticator.SignCount = uint32(passkey.SignCount)
+		credentials = append(credentials, credential)
+	}
+	return &passkeyUser{User: user, passkeys: passkeys, credentials: credentials}, nil
+}
+
+// startPasskeyCeremony stores the ceremony state server-side, keyed by a
+// random ID that is handed to the client in a short-lived cookie.
+func (app *application) startPasskeyCeremony(ctx context.Context, w http.ResponseWriter, ceremony *store.PasskeyCeremony, session *webauthn.SessionData) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	id := uuid.New().String()
+	ceremony.ID = utils.Hash(id)
+	ceremony.SessionData = data
+	if err := app.store.Passkeys.CreateCeremony(ctx, ceremony, passkeyCeremonyExpiry); err != nil {
+		return err
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     passkeyCeremonyCookieName,
+		Value:    id,
+		Path:     "/v1/auth/passkeys",
+		MaxAge:   int(passkeyCeremonyExpiry.Seconds()),
+		HttpOnly: true,
+		Secure:   app.config.env == "production",
+		SameSite: http.SameSiteStrictMode,
+	})
+	return nil
+}
+
+// passkeyCeremony clears the ceremony cookie and consumes the state it
+// points to, so every challenge can be answered only once.
+func (app *application) passkeyCeremony(w http.ResponseWriter, r *http.Request, purpose string) (*store.PasskeyCeremony, *webauthn.SessionData, error) {
+	cookie, err := r.Cookie(passkeyCeremonyCookieName)
+	if err != nil {
+		return nil, nil, errPasskeyCeremony
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     passkeyCeremonyCookieName,
+		Value:    "",
+		Path:     "/v1/auth/passkeys",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   app.config.env == "production",
+		SameSite: http.SameSiteStrictMode,
+	})
+	ceremony, err := app.store.Passkeys.ConsumeCeremony(r.Context(), utils.Hash(cookie.Value), purpose)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ceremony == nil {
+		return nil, nil, errPasskeyCeremony
+	}
+	session := &webauthn.SessionData{}
+	if err := json.Unmarshal(ceremony.SessionData, session); err != nil {
+		return nil, nil, err
+	}
+	return ceremony, session, nil
+}
+
+func passkeyUserHandle(userID int64) []byte {
+	return []byte(strconv.FormatInt(userID, 10))
+}
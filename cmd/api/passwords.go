@@ -0,0 +1,97 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/samuel032khoury/gopherfeed/internal/store"
+)
+
+// changePasswordPayload represents the payload for changing the password
+//
+//	@Description	Password change payload
+type changePasswordPayload struct {
+	CurrentPassword string `json:"current_password" validate:"required,max=256" example:"plum-Orbit-71-lantern"`
+	NewPassword     string `json:"new_password" validate:"required,min=8,max=256" example:"quiet-Harbor-38-violin"`
+}
+
+// ChangePassword godoc
+//
+//	@Summary		Change password
+//	@Description	Replace the current user's password. Other sessions are logged out; the current one stays signed in. Wrong current passwords count towards the login lockout.
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		changePasswordPayload	true	"Current and new password"
+//	@Success		204		{object}	nil						"Password changed"
+//	@Failure		400		{object}	FieldErrorResponse		"New password rejected by the password policy"
+//	@Failure		401		{object}	ErrorResponse			"Wrong current password"
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		429		{object}	ErrorResponse	"Too many failed attempts, see Retry-After"
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/users/me/password [put]
+func (app *application) changePasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var payload changePasswordPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	user := getCurrentUserFromContext(r)
+	accountKey := loginAccountKey(user.Email)
+	if !app.allowLoginAttempt(w, r, accountKey) {
+		return
+	}
+	if _, err := app.store.Users.Authenticate(ctx, user.Email, payload.CurrentPassword); err != nil {
+		switch err {
+		case store.ErrInvalidCredentials:
+			app.recordLoginFailure(ctx, r, accountKey, func() (*store.User, error) {
+				return user, nil
+			})
+			app.unauthorizedError(w, r, false, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	app.resetLoginFailures(ctx, accountKey)
+
+	if !app.checkPasswordPolicy(w, r, "new_password", payload.NewPassword, user) {
+		return
+	}
+	passwordHash, err := app.passwordHasher.Hash(payload.NewPassword)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.store.Users.ChangePassword(ctx, user.ID, passwordHash); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err := app.store.Sessions.RevokeOthers(ctx, user.ID, getSessionFromContext(r).ID); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// checkPasswordPolicy writes a field-level 400 response and returns false if
+// password is rejected by the password policy. user may be nil for accounts
+// that do not exist yet, in which case identifying values are passed along.
+func (app *application) checkPasswordPolicy(w http.ResponseWriter, r *http.Request, field, password string, user *store.User, userInputs ...string) bool {
+	if user != nil {
+		userInputs = append(userInputs, user.Username, user.Email)
+	}
+	violations := app.passwordPolicy.Check(password, userInputs...)
+	if len(violations) == 0 {
+		return true
+	}
+	app.fieldValidationError(w, r, "password does not meet the password policy", map[string][]string{
+		field: violations,
+	})
+	return false
+}
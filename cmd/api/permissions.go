@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Permissions a role can be granted, see the role_permissions table
+const (
+	permPostUpdateAny    = "post.update.any"
+	permPostDeleteAny    = "post.delete.any"
+	permCommentDeleteAny = "comment.delete.any"
+	permUserBan          = "user.ban"
+	permUserUnlock       = "user.unlock"
+	permUserImpersonate  = "user.impersonate"
+	permUserManage       = "user.manage"
+	permRoleManage       = "role.manage"
+	permAuditRead        = "audit.read"
+	permTrashManage      = "trash.manage"
+)
+
+// permissionCacheTTL bounds how long a role change takes to reach other
+// instances when there is no Redis cache to share. Keep it short: revoking a
+// permission is not complete until then.
+const permissionCacheTTL = 30 * time.Second
+
+// permissionCache keeps the permissions of each role in memory, so checking
+// a permission does not cost a query per request. It is only used without
+// the Redis cache. Each instance then has its own cache: a role update drops
+// the entry on the instance that served it, and the others pick up the
+// change once their entry expires, within permissionCacheTTL.
+type permissionCache struct {
+	sync.Mutex
+	ttl     time.Duration
+	entries map[int64]permissionEntry
+}
+
+type permissionEntry struct {
+	permissions []string
+	expiresAt   time.Time
+}
+
+func newPermissionCache(ttl time.Duration) *permissionCache {
+	return &permissionCache{
+		ttl:     ttl,
+		entries: make(map[int64]permissionEntry),
+	}
+}
+
+func (c *permissionCache) get(roleID int64) ([]string, bool) {
+	c.Lock()
+	defer c.Unlock()
+	entry, ok := c.entries[roleID]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.permissions, true
+}
+
+func (c *permissionCache) set(roleID int64, permissions []string) {
+	c.Lock()
+	defer c.Unlock()
+	c.entries[roleID] = permissionEntry{
+		permissions: permissions,
+		expiresAt:   time.Now().Add(c.ttl),
+	}
+}
+
+func (c *permissionCache) delete(roleID int64) {
+	c.Lock()
+	defer c.Unlock()
+	delete(c.entries, roleID)
+}
+
+// hasPermission reports whether the role has been granted permission.
+func (app *application) hasPermission(ctx context.Context, roleID int64, permission string) (bool, error) {
+	permissions, err := app.rolePermissions(ctx, roleID)
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(permissions, permission), nil
+}
+
+// rolePermissions returns every permission granted to the role.
+func (app *application) rolePermissions(ctx context.Context, roleID int64) ([]string, error) {
+	if app.config.cache.enabled {
+		return app.sharedRolePermissions(ctx, roleID)
+	}
+	permissions, ok := app.permissions.get(roleID)
+	if !ok {
+		var err error
+		if permissions, err = app.store.Roles.GetPermissions(ctx, roleID); err != nil {
+			return nil, err
+		}
+		app.permissions.set(roleID, permissions)
+	}
+	return permissions, nil
+}
+
+// sharedRolePermissions reads the role's permissions through the Redis cache
+// every instance uses.
+func (app *application) sharedRolePermissions(ctx context.Context, roleID int64) ([]string, error) {
+	permissions, err := app.cacheStorage.Roles.GetPermissions(ctx, roleID)
+	if err != nil {
+		return nil, err
+	}
+	if permissions != nil {
+		return permissions, nil
+	}
+	if permissions, err = app.store.Roles.GetPermissions(ctx, roleID); err != nil {
+		return nil, err
+	}
+	if err := app.cacheStorage.Roles.SetPermissions(ctx, roleID, permissions); err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+// invalidateRole drops the cached permissions of a role that has changed.
+func (app *application) invalidateRole(ctx context.Context, roleID int64) error {
+	app.permissions.delete(roleID)
+	if !app.config.cache.enabled {
+		return nil
+	}
+	return app.cacheStorage.Roles.Delete(ctx, roleID)
+}
+
+// RequirePermission restricts a route to users whose role grants permission.
+func (app *application) RequirePermission(permission string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user := getCurrentUserFromContext(r)
+			if allowed, err := app.hasPermission(r.Context(), user.RoleID, permission); err != nil {
+				app.internalServerError(w, r, err)
+				return
+			} else if !allowed {
+				app.forbiddenError(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireOwnerOrPermission lets the owner of a resource through, and anyone
+// else only if their role grants permission, e.g. post.update.any.
+func (app *application) RequireOwnerOrPermission(permission string, ownerID func(*http.Request) int64) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		requirePermission := app.RequirePermission(permission)(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ownerID(r) == getCurrentUserFromContext(r).ID {
+				next.ServeHTTP(w, r)
+				return
+			}
+			requirePermission.ServeHTTP(w, r)
+		})
+	}
+}
+
+func postOwnerID(r *http.Request) int64 {
+	return getPostFromContext(r).UserID
+}
+
+func commentOwnerID(r *http.Request) int64 {
+	return getCommentFromContext(r).UserID
+}
@@ -2,7 +2,10 @@ package main
 
 import (
 	"database/sql"
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/samuel032khoury/gopherfeed/internal/store"
 )
@@ -15,15 +18,54 @@ const postKeyCtx postKey = "post"
 //
 //	@Description	Post creation/update payload
 type PostDTO struct {
-	Title   string   `json:"title" validate:"required,max=100" example:"My First Post"`
-	Content string   `json:"content" validate:"required,max=2000" example:"This is the content of my post"`
+	Title string `json:"title" validate:"required,max=100" example:"My First Post"`
+	// Content is CommonMark. Posts are returned with it rendered as
+	// sanitized HTML in content_html.
+	Content string   `json:"content" validate:"required,max=2000" example:"This is the *content* of my post"`
 	Tags    []string `json:"tags" example:"golang,api"`
+	// Status defaults to published for new posts and is left unchanged by
+	// updates that omit it
+	Status    string     `json:"status" validate:"omitempty,oneof=draft scheduled published" example:"scheduled"`
+	PublishAt *time.Time `json:"publish_at" example:"2026-01-07T09:00:00Z"`
+}
+
+// applyStatus moves post to the status requested by the payload. Scheduled
+// posts need a publish time in the future, and published posts cannot be
+// turned back into drafts.
+func (payload *PostDTO) applyStatus(post *store.Post) error {
+	if payload.Status == "" {
+		if payload.PublishAt != nil {
+			return errors.New("publish_at is only allowed for scheduled posts")
+		}
+		return nil
+	}
+	if post.Status == store.PostStatusPublished && payload.Status != store.PostStatusPublished {
+		return errors.New("a published post cannot be unpublished")
+	}
+	switch payload.Status {
+	case store.PostStatusScheduled:
+		if payload.PublishAt == nil {
+			return errors.New("scheduled posts need a publish_at time")
+		}
+		if !payload.PublishAt.After(time.Now()) {
+			return errors.New("publish_at must be in the future")
+		}
+		publishAt := payload.PublishAt.UTC().Format(time.RFC3339)
+		post.PublishAt = &publishAt
+	default:
+		if payload.PublishAt != nil {
+			return errors.New("publish_at is only allowed for scheduled posts")
+		}
+		post.PublishAt = nil
+	}
+	post.Status = payload.Status
+	return nil
 }
 
 // CreatePost godoc
 //
 //	@Summary		Create a post
-//	@Description	Create a new post
+//	@Description	Create a new post. It is published right away unless it is saved as a draft or scheduled for later.
 //	@Tags			posts
 //	@Accept			json
 //	@Produce		json
@@ -50,6 +92,13 @@ func (app *application) createPostHandler(w http.ResponseWriter, r *http.Request
 		Tags:    payload.Tags,
 		UserID:  int64(currentUserID),
 	}
+	if payload.Status == "" {
+		payload.Status = store.PostStatusPublished
+	}
+	if err := payload.applyStatus(post); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
 	ctx := r.Context()
 	if err := app.store.Posts.Create(ctx, post); err != nil {
 		app.internalServerError(w, r, err)
@@ -62,18 +111,26 @@ func (app *application) createPostHandler(w http.ResponseWriter, r *http.Request
 // GetPost godoc
 //
 //	@Summary		Get a post
-//	@Description	Get a post by its unique ID with comments
+//	@Description	Get a post by its unique ID with comments. The ETag follows the post's version and the renderer version of its HTML, so new comments do not change it.
 //	@Tags			posts
 //	@Accept			json
 //	@Produce		json
-//	@Param			postID	path		int	true	"Post ID"
-//	@Success		200		{object}	DataResponse[store.Post]
-//	@Failure		400		{object}	ErrorResponse
-//	@Failure		404		{object}	ErrorResponse
-//	@Failure		500		{object}	ErrorResponse
+//	@Param			postID			path		int		true	"Post ID"
+//	@Param			If-None-Match	header		string	false	"ETag of a copy the client already has"
+//	@Success		200				{object}	DataResponse[store.Post]
+//	@Header			200				{string}	ETag	"Current version of the post"
+//	@Success		304				{object}	nil		"The client's copy is current"
+//	@Failure		400				{object}	ErrorResponse
+//	@Failure		404				{object}	ErrorResponse
+//	@Failure		500				{object}	ErrorResponse
 //	@Router			/posts/{postID} [get]
 func (app *application) getPostHandler(w http.ResponseWriter, r *http.Request) {
 	post := getPostFromContext(r)
+	etag := postETag(post)
+	w.Header().Set("ETag", etag)
+	if notModified(w, r, etag) {
+		return
+	}
 	comments, err := app.store.Comments.GetByPostID(r.Context(), post.ID)
 	if err != nil {
 		app.internalServerError(w, r, err)
@@ -86,21 +143,35 @@ func (app *application) getPostHandler(w http.ResponseWriter, r *http.Request) {
 // DeletePost godoc
 //
 //	@Summary		Delete a post
-//	@Description	Delete a post by its unique ID
+//	@Description	Move a post to the trash by its unique ID. It can be restored until the retention period runs out. Posts by other users require the post.delete.any permission.
 //	@Tags			posts
 //	@Accept			json
 //	@Produce		json
-//	@Param			postID	path		int	true	"Post ID"
-//	@Success		204		{object}	nil	"Post deleted successfully"
-//	@Failure		400		{object}	ErrorResponse
-//	@Failure		401		{object}	ErrorResponse	"Unauthorized - login required"
-//	@Failure		404		{object}	ErrorResponse
-//	@Failure		500		{object}	ErrorResponse
+//	@Param			postID		path		int		true	"Post ID"
+//	@Param			If-Match	header		string	false	"Only delete the post if it is still at this ETag"
+//	@Success		204			{object}	nil		"Post deleted successfully"
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		401			{object}	ErrorResponse	"Unauthorized - login required"
+//	@Failure		404			{object}	ErrorResponse
+//	@Failure		412			{object}	ErrorResponse	"The post has changed"
+//	@Failure		500			{object}	ErrorResponse
 //	@Router			/posts/{postID} [delete]
 func (app *application) deletePostHandler(w http.ResponseWriter, r *http.Request) {
-	postID := getPostFromContext(r).ID
-	if err := app.store.Posts.Delete(r.Context(), postID); err != nil {
-		app.internalServerError(w, r, err)
+	post := getPostFromContext(r)
+	if !app.checkIfMatch(w, r, post, false) {
+		return
+	}
+	actor := getCurrentUserFromContext(r)
+	audit := func() *store.AuditEntry {
+		return auditChangeEntry(r, actor.ID, "post.delete", "post", strconv.FormatInt(post.ID, 10), postSnapshot(post), nil)
+	}
+	if err := app.store.Posts.Delete(r.Context(), post.ID, actor.ID, audit); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundError(w, r)
+		default:
+			app.internalServerError(w, r, err)
+		}
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
@@ -109,42 +180,182 @@ func (app *application) deletePostHandler(w http.ResponseWriter, r *http.Request
 // UpdatePost godoc
 //
 //	@Summary		Update a post
-//	@Description	Update a post by its unique ID
+//	@Description	Update a post by its unique ID. Posts by other users require the post.update.any permission.
 //	@Tags			posts
 //	@Accept			json
 //	@Produce		json
-//	@Param			postID	path		int		true	"Post ID"
-//	@Param			post	body		PostDTO	true	"Post payload"
-//	@Success		200		{object}	nil		"Post updated successfully"
-//	@Failure		400		{object}	ErrorResponse
-//	@Failure		401		{object}	ErrorResponse	"Unauthorized - login required"
-//	@Failure		404		{object}	ErrorResponse
-//	@Failure		409		{object}	ErrorResponse	"Edit conflict"
-//	@Failure		500		{object}	ErrorResponse
+//	@Param			postID		path		int		true	"Post ID"
+//	@Param			If-Match	header		string	false	"Only update the post if it is still at this ETag"
+//	@Param			post		body		PostDTO	true	"Post payload"
+//	@Success		200			{object}	nil		"Post updated successfully"
+//	@Header			200			{string}	ETag	"New version of the post"
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		401			{object}	ErrorResponse	"Unauthorized - login required"
+//	@Failure		404			{object}	ErrorResponse
+//	@Failure		409			{object}	ErrorResponse	"Edit conflict"
+//	@Failure		412			{object}	ErrorResponse	"The post has changed"
+//	@Failure		500			{object}	ErrorResponse
 //	@Router			/posts/{postID} [put]
 func (app *application) updatePostHandler(w http.ResponseWriter, r *http.Request) {
 	post := getPostFromContext(r)
+	if !app.checkIfMatch(w, r, post, false) {
+		return
+	}
 	var payload PostDTO
 	if err := readJSON(w, r, &payload); err != nil {
 		app.badRequestError(w, r, err)
 		return
 	}
-	if err := Validate.Struct(payload); err != nil {
+	if !app.savePost(w, r, post, &payload) {
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// PatchPost godoc
+//
+//	@Summary		Partially update a post
+//	@Description	Update some fields of a post with a JSON Merge Patch (RFC 7396) sent as application/merge-patch+json. Fields left out keep their value and fields set to null are cleared. The If-Match header is required. Posts by other users require the post.update.any permission.
+//	@Tags			posts
+//	@Accept			application/merge-patch+json
+//	@Produce		json
+//	@Param			postID		path		int							true	"Post ID"
+//	@Param			If-Match	header		string						true	"Only update the post if it is still at this ETag"
+//	@Param			patch		body		PostDTO						true	"Fields to change"
+//	@Success		200			{object}	DataResponse[store.Post]	"Post updated successfully"
+//	@Header			200			{string}	ETag						"New version of the post"
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		401			{object}	ErrorResponse	"Unauthorized - login required"
+//	@Failure		404			{object}	ErrorResponse
+//	@Failure		409			{object}	ErrorResponse	"Edit conflict"
+//	@Failure		412			{object}	ErrorResponse	"The post has changed"
+//	@Failure		415			{object}	ErrorResponse	"Body is not a merge patch"
+//	@Failure		428			{object}	ErrorResponse	"Missing If-Match header"
+//	@Failure		500			{object}	ErrorResponse
+//	@Router			/posts/{postID} [patch]
+func (app *application) patchPostHandler(w http.ResponseWriter, r *http.Request) {
+	if !isMergePatch(r) {
+		app.unsupportedMediaTypeError(w, r, mergePatchType)
+		return
+	}
+	post := getPostFromContext(r)
+	if !app.checkIfMatch(w, r, post, true) {
+		return
+	}
+	original := PostDTO{
+		Title:   post.Title,
+		Content: post.Content,
+		Tags:    post.Tags,
+		Status:  post.Status,
+	}
+	if post.Status == store.PostStatusScheduled && post.PublishAt != nil {
+		publishAt, err := time.Parse(time.RFC3339, *post.PublishAt)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		original.PublishAt = &publishAt
+	}
+	var payload PostDTO
+	if err := readMergePatch(w, r, original, &payload); err != nil {
 		app.badRequestError(w, r, err)
 		return
 	}
+	// A patch moving the post out of scheduled drops the schedule it kept
+	if payload.Status != store.PostStatusScheduled && original.PublishAt != nil &&
+		payload.PublishAt != nil && payload.PublishAt.Equal(*original.PublishAt) {
+		payload.PublishAt = nil
+	}
+	if !app.savePost(w, r, post, &payload) {
+		return
+	}
+	app.jsonResponse(w, post, http.StatusOK)
+}
+
+// savePost applies the payload of an update to post and saves it, setting
+// the ETag of the new version. It writes an error response and returns false
+// if the update is invalid or fails.
+func (app *application) savePost(w http.ResponseWriter, r *http.Request, post *store.Post, payload *PostDTO) bool {
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestError(w, r, err)
+		return false
+	}
+	before := postSnapshot(post)
 	post.Title = payload.Title
 	post.Content = payload.Content
 	post.Tags = payload.Tags
-	if err := app.store.Posts.Update(r.Context(), post); err != nil {
+	if err := payload.applyStatus(post); err != nil {
+		app.badRequestError(w, r, err)
+		return false
+	}
+	editor := getCurrentUserFromContext(r)
+	// Authors editing their own posts are not privileged actions
+	var audit store.AuditFunc
+	if editor.ID != post.UserID {
+		audit = func() *store.AuditEntry {
+			return auditChangeEntry(r, editor.ID, "post.update", "post", strconv.FormatInt(post.ID, 10), before, postSnapshot(post))
+		}
+	}
+	if err := app.store.Posts.Update(r.Context(), post, editor.ID, audit); err != nil {
 		if err == sql.ErrNoRows {
 			app.conflictError(w, r, err)
-			return
+			return false
 		}
+		app.internalServerError(w, r, err)
+		return false
+	}
+	w.Header().Set("ETag", postETag(post))
+	return true
+}
+
+// postSnapshot is the part of a post kept in the audit log
+func postSnapshot(post *store.Post) map[string]any {
+	return map[string]any{
+		"user_id": post.UserID,
+		"title":   post.Title,
+		"content": post.Content,
+		"tags":    post.Tags,
+		"version": post.Version,
+		"status":  post.Status,
+	}
+}
+
+// ListDrafts godoc
+//
+//	@Summary		List your drafts
+//	@Description	List the current user's drafts and scheduled posts, most recently edited first by default
+//	@Tags			posts
+//	@Produce		json
+//	@Param			limit	query		int		false	"Number of items per page (1-100)"	example(20)
+//	@Param			offset	query		int		false	"Number of items to skip"			example(0)
+//	@Param			sort	query		string	false	"Sort order by last edit"			Enums(asc, desc)
+//	@Success		200		{object}	DataResponse[[]store.Post]
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/users/me/drafts [get]
+func (app *application) listDraftsHandler(w http.ResponseWriter, r *http.Request) {
+	params := &store.PaginationParams{
+		Limit:  20,
+		Offset: 0,
+		Sort:   "desc",
+	}
+	params, err := params.Parse(r)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(params); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	drafts, err := app.store.Posts.ListDrafts(r.Context(), getCurrentUserFromContext(r).ID, params)
+	if err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
-	w.WriteHeader(http.StatusOK)
+	app.jsonResponse(w, drafts, http.StatusOK)
 }
 
 func getPostFromContext(r *http.Request) *store.Post {
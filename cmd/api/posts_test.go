@@ -1,13 +1,19 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"net/http"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/samuel032khoury/gopherfeed/internal/auth"
+	"github.com/samuel032khoury/gopherfeed/internal/markdown"
 	"github.com/samuel032khoury/gopherfeed/internal/store"
+	"github.com/samuel032khoury/gopherfeed/internal/textdiff"
 )
 
 func TestGetFeed(t *testing.T) {
@@ -39,6 +45,8 @@ func TestGetFeed(t *testing.T) {
 		exp, iss, aud := authenticator.GetMetadata()
 		claims := jwt.MapClaims{
 			"sub": user.ID,
+			"sid": "test-session",
+			"jti": "test-jti",
 			"exp": time.Now().Add(exp).Unix(),
 			"iat": time.Now().Unix(),
 			"nbf": time.Now().Unix(),
@@ -67,4 +75,586 @@ func TestGetFeed(t *testing.T) {
 		rr := execRequest(req, mux)
 		checkResponseCode(t, http.StatusOK, rr.Code)
 	})
+
+	t.Run("should not allow tokens without a session", func(t *testing.T) {
+		exp, iss, aud := app.authenticator.GetMetadata()
+		claims := jwt.MapClaims{
+			"sub": 1,
+			"exp": time.Now().Add(exp).Unix(),
+			"iat": time.Now().Unix(),
+			"nbf": time.Now().Unix(),
+			"iss": iss,
+			"aud": aud,
+		}
+		token, err := app.authenticator.GenerateToken(claims)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req, err := http.NewRequest(http.MethodGet, "/v1/feeds", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.AddCookie(&http.Cookie{Name: "jwt", Value: token})
+
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusUnauthorized, rr.Code)
+	})
+}
+
+func TestDeleteComment(t *testing.T) {
+	app := newTestApplication(t)
+	mux := app.mount()
+
+	token, err := app.generateAccessToken(1, "test-session", "test-jti")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The mock comment belongs to user 2 and the mock role has no permissions
+	t.Run("should not allow deleting other users' comments", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodDelete, "/v1/posts/0/comments/1", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("should allow roles with the comment.delete.any permission", func(t *testing.T) {
+		// The mock user has role 1
+		app.permissions.set(1, []string{permCommentDeleteAny})
+		defer app.permissions.set(1, []string{})
+
+		req, err := http.NewRequest(http.MethodDelete, "/v1/posts/0/comments/1", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusNoContent, rr.Code)
+	})
+}
+
+func TestCreateComment(t *testing.T) {
+	app := newTestApplication(t)
+	mux := app.mount()
+	app.store.Posts = &authoredPostStore{}
+
+	token, err := app.generateAccessToken(2, "test-session", "test-jti")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should attribute comments to the signed-in user", func(t *testing.T) {
+		body := strings.NewReader(`{"content":"first!"}`)
+		req, err := http.NewRequest(http.MethodPost, "/v1/posts/1/comments", body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusCreated, rr.Code)
+
+		var response struct {
+			Data store.Comment `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		if response.Data.UserID != 2 {
+			t.Errorf("expected the comment to be by user 2; got %d", response.Data.UserID)
+		}
+	})
+}
+
+// draftPostStore serves every post as a draft written by user 2
+type draftPostStore struct {
+	store.MockPostStore
+}
+
+func (s *draftPostStore) GetByID(ctx context.Context, id int64) (*store.Post, error) {
+	return &store.Post{ID: id, UserID: 2, Status: store.PostStatusDraft}, nil
+}
+
+func TestPostStatus(t *testing.T) {
+	app := newTestApplication(t)
+	mux := app.mount()
+
+	token, err := app.generateAccessToken(1, "test-session", "test-jti")
+	if err != nil {
+		t.Fatal(err)
+	}
+	authorToken, err := app.generateAccessToken(2, "test-session", "test-jti")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tomorrow := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	yesterday := time.Now().Add(-24 * time.Hour).UTC().Format(time.RFC3339)
+
+	t.Run("should create drafts and scheduled posts", func(t *testing.T) {
+		for _, payload := range []string{
+			`{"title":"title","content":"content","status":"draft"}`,
+			`{"title":"title","content":"content","status":"scheduled","publish_at":"` + tomorrow + `"}`,
+		} {
+			req, err := http.NewRequest(http.MethodPost, "/v1/posts", strings.NewReader(payload))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+			rr := execRequest(req, mux)
+			checkResponseCode(t, http.StatusCreated, rr.Code)
+		}
+	})
+
+	t.Run("should reject invalid schedules", func(t *testing.T) {
+		for _, payload := range []string{
+			`{"title":"title","content":"content","status":"scheduled"}`,
+			`{"title":"title","content":"content","status":"scheduled","publish_at":"` + yesterday + `"}`,
+			`{"title":"title","content":"content","status":"published","publish_at":"` + tomorrow + `"}`,
+			`{"title":"title","content":"content","status":"archived"}`,
+		} {
+			req, err := http.NewRequest(http.MethodPost, "/v1/posts", strings.NewReader(payload))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+			rr := execRequest(req, mux)
+			checkResponseCode(t, http.StatusBadRequest, rr.Code)
+		}
+	})
+
+	t.Run("should not unpublish posts", func(t *testing.T) {
+		body := strings.NewReader(`{"title":"title","content":"content","status":"draft"}`)
+		req, err := http.NewRequest(http.MethodPut, "/v1/posts/1", body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The mock post is published and belongs to user 0
+		app.permissions.set(1, []string{permPostUpdateAny})
+		defer app.permissions.set(1, []string{})
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("should list drafts", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/v1/users/me/drafts", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusOK, rr.Code)
+	})
+
+	app.store.Posts = &draftPostStore{}
+
+	t.Run("should hide drafts from other users", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/v1/posts/1", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusNotFound, rr.Code)
+
+		req.Header.Set("Authorization", "Bearer "+authorToken)
+		rr = execRequest(req, mux)
+		checkResponseCode(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("should not allow comments on drafts", func(t *testing.T) {
+		body := strings.NewReader(`{"content":"first!"}`)
+		req, err := http.NewRequest(http.MethodPost, "/v1/posts/1/comments", body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+authorToken)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+// authoredPostStore serves every post as published by user 2 at version 2
+type authoredPostStore struct {
+	store.MockPostStore
+}
+
+func (s *authoredPostStore) GetByID(ctx context.Context, id int64) (*store.Post, error) {
+	return &store.Post{
+		ID:      id,
+		UserID:  2,
+		Title:   "title",
+		Content: "first line\nversion 2",
+		Tags:    []string{"tag2"},
+		Version: 2,
+		Status:  store.PostStatusPublished,
+		// Rendered by the current renderer
+		ContentHTML:   "<p>first line\nversion 2</p>\n",
+		RenderVersion: markdown.Version,
+	}, nil
+}
+
+// scheduledPostStore returns the authored post with the given status and
+// publish_at
+type scheduledPostStore struct {
+	authoredPostStore
+	status    string
+	publishAt string
+}
+
+func (s *scheduledPostStore) GetByID(ctx context.Context, id int64) (*store.Post, error) {
+	post, err := s.authoredPostStore.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	post.Status = s.status
+	post.PublishAt = &s.publishAt
+	return post, nil
+}
+
+func TestPostRevisions(t *testing.T) {
+	app := newTestApplication(t)
+	mux := app.mount()
+
+	// The mock store keeps revisions 1 and 2 of every post
+	app.store.Posts = &authoredPostStore{}
+
+	ownerToken, err := app.generateAccessToken(2, "test-session", "test-jti")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := app.generateAccessToken(1, "test-session", "test-jti")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should hide the history from other users", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/v1/posts/1/revisions", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("should list revisions", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/v1/posts/1/revisions", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+ownerToken)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("should reject out of range pages", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/v1/posts/1/revisions?limit=500", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+ownerToken)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("should diff two revisions", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/v1/posts/1/revisions/diff?from=1", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+ownerToken)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusOK, rr.Code)
+
+		var response struct {
+			Data revisionDiff `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		diff := response.Data
+		if diff.Title != nil {
+			t.Errorf("expected no title change; got %+v", diff.Title)
+		}
+		if len(diff.TagsAdded) != 1 || diff.TagsAdded[0] != "tag2" {
+			t.Errorf("expected tag2 to be added; got %v", diff.TagsAdded)
+		}
+		want := []textdiff.Line{
+			{Op: textdiff.Equal, Text: "first line"},
+			{Op: textdiff.Delete, Text: "version 1"},
+			{Op: textdiff.Insert, Text: "version 2"},
+		}
+		if !slices.Equal(diff.Content, want) {
+			t.Errorf("expected content diff %v; got %v", want, diff.Content)
+		}
+	})
+
+	t.Run("should reject unknown versions", func(t *testing.T) {
+		tests := []struct {
+			path string
+			code int
+		}{
+			{"/v1/posts/1/revisions/diff?from=7&to=2", http.StatusNotFound},
+			{"/v1/posts/1/revisions/diff?from=first", http.StatusBadRequest},
+			{"/v1/posts/1/revisions/3", http.StatusNotFound},
+		}
+		for _, tt := range tests {
+			req, err := http.NewRequest(http.MethodGet, tt.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", "Bearer "+ownerToken)
+			rr := execRequest(req, mux)
+			checkResponseCode(t, tt.code, rr.Code)
+		}
+	})
+
+	t.Run("should only let the author restore", func(t *testing.T) {
+		app.permissions.set(1, []string{permPostUpdateAny})
+		defer app.permissions.set(1, []string{})
+
+		req, err := http.NewRequest(http.MethodPost, "/v1/posts/1/revisions/1/restore", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusForbidden, rr.Code)
+
+		req.Header.Set("Authorization", "Bearer "+ownerToken)
+		rr = execRequest(req, mux)
+		checkResponseCode(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("should restore under the same preconditions as updates", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/v1/posts/1/revisions/1/restore", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+ownerToken)
+		req.Header.Set("If-Match", `"1.1"`)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusPreconditionFailed, rr.Code)
+
+		req.Header.Set("If-Match", `"2.1"`)
+		rr = execRequest(req, mux)
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		if etag := rr.Header().Get("ETag"); etag != `"2.1"` {
+			t.Errorf("expected the ETag of the saved post; got %q", etag)
+		}
+	})
+}
+
+func TestConditionalPostRequests(t *testing.T) {
+	app := newTestApplication(t)
+	mux := app.mount()
+	app.store.Posts = &authoredPostStore{}
+
+	token, err := app.generateAccessToken(2, "test-session", "test-jti")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should tag posts with their version", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/v1/posts/1", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		if etag := rr.Header().Get("ETag"); etag != `"2.1"` {
+			t.Errorf(`expected ETag "2.1"; got %s`, etag)
+		}
+	})
+
+	t.Run("should return the rendered content", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/v1/posts/1", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusOK, rr.Code)
+
+		var response struct {
+			Data store.Post `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		if want := "<p>first line\nversion 2</p>\n"; response.Data.ContentHTML != want {
+			t.Errorf("expected content_html %q; got %q", want, response.Data.ContentHTML)
+		}
+	})
+
+	t.Run("should honour If-None-Match", func(t *testing.T) {
+		tests := []struct {
+			ifNoneMatch string
+			code        int
+		}{
+			{`"2.1"`, http.StatusNotModified},
+			{`W/"2.1"`, http.StatusNotModified},
+			{`"1.1", "2.1"`, http.StatusNotModified},
+			{`"1.1"`, http.StatusOK},
+			// Rendered by an older renderer
+			{`"2.0"`, http.StatusOK},
+		}
+		for _, tt := range tests {
+			req, err := http.NewRequest(http.MethodGet, "/v1/posts/1", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+			req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			rr := execRequest(req, mux)
+			checkResponseCode(t, tt.code, rr.Code)
+		}
+	})
+
+	t.Run("should refuse writes to a stale version", func(t *testing.T) {
+		tests := []struct {
+			method      string
+			contentType string
+			body        string
+		}{
+			{http.MethodPut, "application/json", `{"title":"title","content":"content"}`},
+			{http.MethodPatch, mergePatchType, `{"title":"new title"}`},
+			{http.MethodDelete, "", ""},
+		}
+		for _, tt := range tests {
+			req, err := http.NewRequest(tt.method, "/v1/posts/1", strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+			req.Header.Set("Content-Type", tt.contentType)
+			req.Header.Set("If-Match", `"1.1"`)
+			rr := execRequest(req, mux)
+			checkResponseCode(t, http.StatusPreconditionFailed, rr.Code)
+
+			// A weak tag never satisfies If-Match
+			req.Header.Set("If-Match", `W/"2.1"`)
+			rr = execRequest(req, mux)
+			checkResponseCode(t, http.StatusPreconditionFailed, rr.Code)
+		}
+	})
+
+	t.Run("should update the current version", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPut, "/v1/posts/1", strings.NewReader(`{"title":"title","content":"content"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("If-Match", `"2.1"`)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("should require If-Match to patch", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPatch, "/v1/posts/1", strings.NewReader(`{"title":"new title"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", mergePatchType)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusPreconditionRequired, rr.Code)
+	})
+
+	t.Run("should merge patches into the post", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPatch, "/v1/posts/1", strings.NewReader(`{"title":"new title","tags":null}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", mergePatchType)
+		req.Header.Set("If-Match", `"2.1"`)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusOK, rr.Code)
+
+		var response struct {
+			Data store.Post `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		post := response.Data
+		if post.Title != "new title" || post.Content != "first line\nversion 2" || len(post.Tags) != 0 {
+			t.Errorf("expected only the title to change and the tags to be cleared; got %+v", post)
+		}
+	})
+
+	t.Run("should patch posts that kept an old publish_at", func(t *testing.T) {
+		past := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+		future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+		tests := []struct {
+			name   string
+			status string
+			body   string
+		}{
+			{"published", store.PostStatusPublished, `{"title":"new title"}`},
+			{"scheduled", store.PostStatusScheduled, `{"status":"published"}`},
+		}
+		for _, tt := range tests {
+			publishAt := past
+			if tt.status == store.PostStatusScheduled {
+				publishAt = future
+			}
+			app.store.Posts = &scheduledPostStore{status: tt.status, publishAt: publishAt}
+			req, err := http.NewRequest(http.MethodPatch, "/v1/posts/1", strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+			req.Header.Set("Content-Type", mergePatchType)
+			req.Header.Set("If-Match", `"2.1"`)
+			rr := execRequest(req, mux)
+			checkResponseCode(t, http.StatusOK, rr.Code)
+
+			var response struct {
+				Data store.Post `json:"data"`
+			}
+			if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+				t.Fatal(err)
+			}
+			if response.Data.PublishAt != nil {
+				t.Errorf("%s: expected publish_at to be dropped; got %s", tt.name, *response.Data.PublishAt)
+			}
+		}
+		app.store.Posts = &authoredPostStore{}
+	})
+
+	t.Run("should only take merge patches", func(t *testing.T) {
+		for _, contentType := range []string{"", "application/json", "application/json-patch+json"} {
+			req, err := http.NewRequest(http.MethodPatch, "/v1/posts/1", strings.NewReader(`{"title":"title","content":"content"}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+			req.Header.Set("Content-Type", contentType)
+			req.Header.Set("If-Match", `"2.1"`)
+			rr := execRequest(req, mux)
+			checkResponseCode(t, http.StatusUnsupportedMediaType, rr.Code)
+		}
+	})
+
+	t.Run("should reject invalid patches", func(t *testing.T) {
+		for _, body := range []string{`{"content":null}`, `{"views":10}`, `["title"]`, `null`} {
+			req, err := http.NewRequest(http.MethodPatch, "/v1/posts/1", strings.NewReader(body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+			req.Header.Set("Content-Type", mergePatchType)
+			req.Header.Set("If-Match", `"2.1"`)
+			rr := execRequest(req, mux)
+			checkResponseCode(t, http.StatusBadRequest, rr.Code)
+		}
+	})
 }
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/samuel032khoury/gopherfeed/internal/store"
+	"github.com/samuel032khoury/gopherfeed/internal/textdiff"
+)
+
+type revisionKey string
+
+const revisionKeyCtx revisionKey = "revision"
+
+// revisionDiff describes what changed between two revisions of a post
+//
+//	@Description	Changes between two versions of a post
+type revisionDiff struct {
+	From        int             `json:"from" example:"1"`
+	To          int             `json:"to" example:"3"`
+	Title       *titleChange    `json:"title,omitempty"`
+	TagsAdded   []string        `json:"tags_added" example:"golang"`
+	TagsRemoved []string        `json:"tags_removed" example:"api"`
+	Content     []textdiff.Line `json:"content"`
+}
+
+// titleChange is a post title before and after an edit
+type titleChange struct {
+	From string `json:"from" example:"My First Post"`
+	To   string `json:"to" example:"My First Go Post"`
+}
+
+// ListRevisions godoc
+//
+//	@Summary		List post revisions
+//	@Description	List the saved versions of a post, newest first by default. Only the author and users with the post.update.any permission can see a post's history.
+//	@Tags			posts
+//	@Produce		json
+//	@Param			postID	path		int		true	"Post ID"
+//	@Param			limit	query		int		false	"Number of items per page (1-100)"	example(20)
+//	@Param			offset	query		int		false	"Number of items to skip"			example(0)
+//	@Param			sort	query		string	false	"Sort order by version"				Enums(asc, desc)
+//	@Success		200		{object}	DataResponse[[]store.PostRevision]
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/posts/{postID}/revisions [get]
+func (app *application) listRevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	filter := &store.RevisionFilter{
+		Limit:  20,
+		Offset: 0,
+		Sort:   "desc",
+	}
+	filter, err := filter.Parse(r)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(filter); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	revisions, err := app.store.Posts.ListRevisions(r.Context(), getPostFromContext(r).ID, filter)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, revisions, http.StatusOK)
+}
+
+// GetRevision godoc
+//
+//	@Summary		Get a post revision
+//	@Description	Get a post as it was saved at one version
+//	@Tags			posts
+//	@Produce		json
+//	@Param			postID	path		int	true	"Post ID"
+//	@Param			version	path		int	true	"Version"
+//	@Success		200		{object}	DataResponse[store.PostRevision]
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/posts/{postID}/revisions/{version} [get]
+func (app *application) getRevisionHandler(w http.ResponseWriter, r *http.Request) {
+	app.jsonResponse(w, getRevisionFromContext(r), http.StatusOK)
+}
+
+// DiffRevisions godoc
+//
+//	@Summary		Compare post revisions
+//	@Description	Show what changed between two versions of a post. The content is compared line by line.
+//	@Tags			posts
+//	@Produce		json
+//	@Param			postID	path		int	true	"Post ID"
+//	@Param			from	query		int	true	"Older version"						example(1)
+//	@Param			to		query		int	false	"Newer version, the current one by default"	example(3)
+//	@Success		200		{object}	DataResponse[revisionDiff]
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/posts/{postID}/revisions/diff [get]
+func (app *application) diffRevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	post := getPostFromContext(r)
+	query := r.URL.Query()
+	from, err := strconv.Atoi(query.Get("from"))
+	if err != nil {
+		app.badRequestError(w, r, errors.New("from must be a version number"))
+		return
+	}
+	to := post.Version
+	if toStr := query.Get("to"); toStr != "" {
+		if to, err = strconv.Atoi(toStr); err != nil {
+			app.badRequestError(w, r, errors.New("to must be a version number"))
+			return
+		}
+	}
+
+	ctx := r.Context()
+	older, err := app.store.Posts.GetRevision(ctx, post.ID, from)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	newer, err := app.store.Posts.GetRevision(ctx, post.ID, to)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if older == nil || newer == nil {
+		app.notFoundError(w, r)
+		return
+	}
+	app.jsonResponse(w, diffRevisions(older, newer), http.StatusOK)
+}
+
+func diffRevisions(older, newer *store.PostRevision) *revisionDiff {
+	diff := &revisionDiff{
+		From:        older.Version,
+		To:          newer.Version,
+		TagsAdded:   []string{},
+		TagsRemoved: []string{},
+		Content:     textdiff.Lines(older.Content, newer.Content),
+	}
+	if older.Title != newer.Title {
+		diff.Title = &titleChange{From: older.Title, To: newer.Title}
+	}
+	for _, tag := range newer.Tags {
+		if !slices.Contains(older.Tags, tag) {
+			diff.TagsAdded = append(diff.TagsAdded, tag)
+		}
+	}
+	for _, tag := range older.Tags {
+		if !slices.Contains(newer.Tags, tag) {
+			diff.TagsRemoved = append(diff.TagsRemoved, tag)
+		}
+	}
+	return diff
+}
+
+// RestoreRevision godoc
+//
+//	@Summary		Restore a post revision
+//	@Description	Save an earlier version's title, content and tags as the newest version of the post, like an update that changes only those. Only the author can restore.
+//	@Tags			posts
+//	@Produce		json
+//	@Param			postID		path		int							true	"Post ID"
+//	@Param			version		path		int							true	"Version to restore"
+//	@Param			If-Match	header		string						false	"Only restore if the post is still at this ETag"
+//	@Success		200			{object}	DataResponse[store.Post]	"Revision restored"
+//	@Header			200			{string}	ETag						"New version of the post"
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		401			{object}	ErrorResponse
+//	@Failure		403			{object}	ErrorResponse
+//	@Failure		404			{object}	ErrorResponse
+//	@Failure		409			{object}	ErrorResponse	"Edit conflict"
+//	@Failure		412			{object}	ErrorResponse	"The post has changed"
+//	@Failure		500			{object}	ErrorResponse
+//	@Router			/posts/{postID}/revisions/{version}/restore [post]
+func (app *application) restoreRevisionHandler(w http.ResponseWriter, r *http.Request) {
+	post := getPostFromContext(r)
+	user := getCurrentUserFromContext(r)
+	if post.UserID != user.ID {
+		app.forbiddenError(w, r)
+		return
+	}
+	revision := getRevisionFromContext(r)
+	if revision.Version == post.Version {
+		app.badRequestError(w, r, errors.New("this is already the current version"))
+		return
+	}
+
+	if !app.checkIfMatch(w, r, post, false) {
+		return
+	}
+
+	// Without a status the post keeps its status and schedule
+	payload := PostDTO{
+		Title:   revision.Title,
+		Content: revision.Content,
+		Tags:    revision.Tags,
+	}
+	if !app.savePost(w, r, post, &payload) {
+		return
+	}
+	app.jsonResponse(w, post, http.StatusOK)
+}
+
+// RevisionParamMiddleware loads the revision of the current post named by
+// the version URL parameter.
+func (app *application) RevisionParamMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		version, err := strconv.Atoi(chi.URLParam(r, "version"))
+		if err != nil {
+			app.badRequestError(w, r, err)
+			return
+		}
+		ctx := r.Context()
+		revision, err := app.store.Posts.GetRevision(ctx, getPostFromContext(r).ID, version)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		if revision == nil {
+			app.notFoundError(w, r)
+			return
+		}
+		ctx = context.WithValue(ctx, revisionKeyCtx, revision)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func getRevisionFromContext(r *http.Request) *store.PostRevision {
+	revision, ok := r.Context().Value(revisionKeyCtx).(*store.PostRevision)
+	if !ok {
+		return nil
+	}
+	return revision
+}
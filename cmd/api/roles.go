@@ -0,0 +1,175 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/samuel032khoury/gopherfeed/internal/store"
+)
+
+// rolePayload represents the payload for creating or replacing a role
+//
+//	@Description	Role payload; permissions replace the role's current ones
+type rolePayload struct {
+	Name        string   `json:"name" validate:"required,max=50" example:"support"`
+	Level       int      `json:"level" validate:"min=1" example:"3"`
+	Description string   `json:"description" validate:"max=255" example:"Support staff"`
+	Permissions []string `json:"permissions" validate:"unique,dive,max=100" example:"comment.delete.any,user.unlock"`
+}
+
+// ListRoles godoc
+//
+//	@Summary		List roles
+//	@Description	List every role with its permissions
+//	@Tags			admin
+//	@Produce		json
+//	@Success		200	{object}	DataResponse[[]store.Role]
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		403	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/admin/roles [get]
+func (app *application) listRolesHandler(w http.ResponseWriter, r *http.Request) {
+	roles, err := app.store.Roles.List(r.Context())
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, roles, http.StatusOK)
+}
+
+// CreateRole godoc
+//
+//	@Summary		Create a role
+//	@Description	Create a custom role. Its level must be below your own and it can only hold permissions you hold.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		rolePayload					true	"Role payload"
+//	@Success		201		{object}	DataResponse[store.Role]	"Role created"
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/admin/roles [post]
+func (app *application) createRoleHandler(w http.ResponseWriter, r *http.Request) {
+	var payload rolePayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	actor := getCurrentUserFromContext(r)
+	if !app.canManageRoleLevel(w, r, actor, payload.Level) || !app.canGrantPermissions(w, r, actor, payload.Permissions) {
+		return
+	}
+
+	role := payload.role()
+	audit := func() *store.AuditEntry {
+		return auditChangeEntry(r, actor.ID, "role.create", "role", strconv.FormatInt(role.ID, 10), nil, role)
+	}
+	if err := app.store.Roles.Create(r.Context(), role, audit); err != nil {
+		switch err {
+		case store.ErrDuplicateRole, store.ErrUnknownPermission:
+			app.badRequestError(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	app.jsonResponse(w, role, http.StatusCreated)
+}
+
+// UpdateRole godoc
+//
+//	@Summary		Update a role
+//	@Description	Replace a role's name, level, description and permissions. Only roles below your own level can be edited, only to a level below your own, and only with permissions you hold. Permission changes apply at once on every instance with the Redis cache enabled, and otherwise take up to 30 seconds to reach other instances.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			roleID	path		int							true	"Role ID"
+//	@Param			payload	body		rolePayload					true	"Role payload"
+//	@Success		200		{object}	DataResponse[store.Role]	"Role updated"
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/admin/roles/{roleID} [put]
+func (app *application) updateRoleHandler(w http.ResponseWriter, r *http.Request) {
+	roleID, err := strconv.ParseInt(chi.URLParam(r, "roleID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	var payload rolePayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	actor := getCurrentUserFromContext(r)
+	existing, err := app.store.Roles.GetByID(ctx, roleID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if existing == nil {
+		app.notFoundError(w, r)
+		return
+	}
+	if !app.canManageRoleLevel(w, r, actor, existing.Level) || !app.canManageRoleLevel(w, r, actor, payload.Level) {
+		return
+	}
+	if !app.canGrantPermissions(w, r, actor, payload.Permissions) {
+		return
+	}
+	existing.Permissions, err = app.store.Roles.GetPermissions(ctx, roleID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	role := payload.role()
+	role.ID = roleID
+	audit := func() *store.AuditEntry {
+		return auditChangeEntry(r, actor.ID, "role.update", "role", strconv.FormatInt(role.ID, 10), existing, role)
+	}
+	if err := app.store.Roles.Update(ctx, role, audit); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundError(w, r)
+		case store.ErrDuplicateRole, store.ErrUnknownPermission:
+			app.badRequestError(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	if err := app.invalidateRole(ctx, roleID); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, role, http.StatusOK)
+}
+
+func (payload *rolePayload) role() *store.Role {
+	permissions := payload.Permissions
+	if permissions == nil {
+		permissions = []string{}
+	}
+	return &store.Role{
+		Name:        payload.Name,
+		Level:       payload.Level,
+		Description: payload.Description,
+		Permissions: permissions,
+	}
+}
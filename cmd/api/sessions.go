@@ -0,0 +1,340 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/samuel032khoury/gopherfeed/internal/email"
+	"github.com/samuel032khoury/gopherfeed/internal/store"
+	"github.com/samuel032khoury/gopherfeed/internal/utils"
+)
+
+const refreshCookieName = "refresh_token"
+
+type sessionKey string
+
+const sessionKeyCtx sessionKey = "session"
+
+// sessionResponse represents a session as listed to its owner
+//
+//	@Description	Active login session
+type sessionResponse struct {
+	*store.Session
+	Current bool `json:"current" example:"true"`
+}
+
+// ListSessions godoc
+//
+//	@Summary		List active sessions
+//	@Description	List the devices the current user is logged in on
+//	@Tags			users
+//	@Produce		json
+//	@Success		200	{object}	DataResponse[[]sessionResponse]
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		403	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/users/me/sessions [get]
+func (app *application) listSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	current := getSessionFromContext(r)
+	sessions, err := app.store.Sessions.ListActive(r.Context(), current.UserID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	response := make([]sessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		response = append(response, sessionResponse{Session: session, Current: session.ID == current.ID})
+	}
+	app.jsonResponse(w, response, http.StatusOK)
+}
+
+// RevokeSession godoc
+//
+//	@Summary		Revoke a session
+//	@Description	Log out one of the current user's sessions
+//	@Tags			users
+//	@Param			sessionID	path		string	true	"Session ID"
+//	@Success		204			{object}	nil		"Session revoked"
+//	@Failure		401			{object}	ErrorResponse
+//	@Failure		403			{object}	ErrorResponse
+//	@Failure		404			{object}	ErrorResponse
+//	@Failure		500			{object}	ErrorResponse
+//	@Router			/users/me/sessions/{sessionID} [delete]
+func (app *application) revokeSessionHandler(w http.ResponseWriter, r *http.Request) {
+	current := getSessionFromContext(r)
+	sessionID := chi.URLParam(r, "sessionID")
+	if err := app.store.Sessions.RevokeForUser(r.Context(), sessionID, current.UserID); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundError(w, r)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	if sessionID == current.ID {
+		app.clearSessionCookies(w)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RevokeOtherSessions godoc
+//
+//	@Summary		Revoke all other sessions
+//	@Description	Log out every session of the current user except the one making the request
+//	@Tags			users
+//	@Success		204	{object}	nil	"Other sessions revoked"
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		403	{object}	ErrorResponse
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/users/me/sessions [delete]
+func (app *application) revokeOtherSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	current := getSessionFromContext(r)
+	if err := app.store.Sessions.RevokeOthers(r.Context(), current.UserID, current.ID); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// accessClaims holds the claims TokenAuthMiddleware relies on. actorID is
+// the admin behind an impersonation token, zero otherwise. jti must match the
+// session's latest access token, so a token replaced by a refresh is dead.
+type accessClaims struct {
+	userID    int64
+	sessionID string
+	jti       string
+	actorID   int64
+}
+
+// startSession creates a new session for the user and sets the access,
+// refresh token and CSRF cookies on the response. The user is emailed when
+// the login comes from a device they have not used before.
+func (app *application) startSession(w http.ResponseWriter, r *http.Request, userID int64) error {
+	ctx := r.Context()
+	refreshToken := uuid.New().String()
+	session := &store.Session{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		UserAgent: r.UserAgent(),
+		IP:        clientIP(r),
+		AccessJTI: uuid.New().String(),
+	}
+	newDevice, err := app.store.Sessions.IsNewDevice(ctx, userID, session.UserAgent)
+	if err != nil {
+		return err
+	}
+	metadata := map[string]any{
+		"user_agent": session.UserAgent,
+		"new_device": newDevice,
+	}
+	audit := func() *store.AuditEntry {
+		return auditEntry(r, userID, "auth.login", "session", session.ID, metadata)
+	}
+	if err := app.store.Sessions.Create(ctx, session, utils.Hash(refreshToken), utils.RefreshTokenExpiry, audit); err != nil {
+		return err
+	}
+	// A fresh CSRF token per login, so a token planted before it is useless
+	if _, err := app.setCSRFCookie(w); err != nil {
+		return err
+	}
+	if err := app.setSessionCookies(w, userID, session.ID, session.AccessJTI, refreshToken); err != nil {
+		return err
+	}
+	if newDevice {
+		app.notifyNewDevice(ctx, session)
+	}
+	return nil
+}
+
+// notifyNewDevice emails the user about a login from an unknown device.
+// Failures are only logged, they must not fail the login.
+func (app *application) notifyNewDevice(ctx context.Context, session *store.Session) {
+	user, err := app.getUser(ctx, session.UserID)
+	if err != nil || user == nil {
+		app.logger.Errorw("failed to load user for new device notification", "user_id", session.UserID, "error", err)
+		return
+	}
+	vars := struct {
+		Username  string
+		UserAgent string
+		IP        string
+		LoginTime string
+	}{
+		Username:  user.Username,
+		UserAgent: session.UserAgent,
+		IP:        session.IP,
+		LoginTime: session.CreatedAt,
+	}
+	if err := app.emailPublisher.Publish(user.Email, email.NewDeviceTemplate, vars); err != nil {
+		app.logger.Errorw("failed to send new device email", "email", user.Email, "error", err)
+	}
+}
+
+func (app *application) setSessionCookies(w http.ResponseWriter, userID int64, sessionID, jti, refreshToken string) error {
+	token, err := app.generateAccessToken(userID, sessionID, jti)
+	if err != nil {
+		return err
+	}
+	expDuration, _, _ := app.authenticator.GetMetadata()
+
+	// Set JWT as HTTPOnly cookie
+	http.SetCookie(w, &http.Cookie{
+		Name:     "jwt",
+		Value:    token,
+		Path:     "/",
+		MaxAge:   int(expDuration.Seconds()),
+		HttpOnly: true,
+		Secure:   app.config.env == "production",
+		SameSite: http.SameSiteStrictMode,
+	})
+	// The refresh token is only ever needed by the auth endpoints
+	http.SetCookie(w, &http.Cookie{
+		Name:     refreshCookieName,
+		Value:    refreshToken,
+		Path:     "/v1/auth",
+		MaxAge:   int(utils.RefreshTokenExpiry.Seconds()),
+		HttpOnly: true,
+		Secure:   app.config.env == "production",
+		SameSite: http.SameSiteStrictMode,
+	})
+	return nil
+}
+
+func (app *application) clearSessionCookies(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     "jwt",
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1, // expire immediately
+		HttpOnly: true,
+		Secure:   app.config.env == "production",
+		SameSite: http.SameSiteStrictMode,
+	})
+	http.SetCookie(w, &http.Cookie{
+		Name:     refreshCookieName,
+		Value:    "",
+		Path:     "/v1/auth",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   app.config.env == "production",
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+func (app *application) generateAccessToken(userID int64, sessionID, jti string) (string, error) {
+	exp, _, _ := app.authenticator.GetMetadata()
+	return app.authenticator.GenerateToken(app.accessTokenClaims(userID, sessionID, jti, exp))
+}
+
+// generateImpersonationToken signs an access token for userID on behalf of
+// the admin actorID. The act claim (RFC 8693) marks it as impersonated.
+func (app *application) generateImpersonationToken(userID, actorID int64, sessionID, jti string, ttl time.Duration) (string, error) {
+	claims := app.accessTokenClaims(userID, sessionID, jti, ttl)
+	claims["act"] = map[string]any{"sub": actorID}
+	return app.authenticator.GenerateToken(claims)
+}
+
+func (app *application) accessTokenClaims(userID int64, sessionID, jti string, ttl time.Duration) jwt.MapClaims {
+	_, iss, aud := app.authenticator.GetMetadata()
+	return jwt.MapClaims{
+		"sub": userID,
+		"sid": sessionID,
+		"jti": jti,
+		"exp": time.Now().Add(ttl).Unix(),
+		"iat": time.Now().Unix(),
+		"nbf": time.Now().Unix(),
+		"iss": iss,
+		"aud": aud,
+	}
+}
+
+func (app *application) parseAccessToken(token string) (*accessClaims, error) {
+	jwtToken, err := app.authenticator.ValidateToken(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid or expired token")
+	}
+	claims, ok := jwtToken.Claims.(jwt.MapClaims)
+	if !ok || !jwtToken.Valid {
+		return nil, fmt.Errorf("invalid token claims")
+	}
+	// Purpose tokens such as MFA challenges are signed with the same key
+	// and must never be accepted as access tokens.
+	if _, ok := claims["typ"]; ok {
+		return nil, fmt.Errorf("invalid token type")
+	}
+	userID, err := userIDFromClaims(claims)
+	if err != nil {
+		return nil, err
+	}
+	sessionID, ok := claims["sid"].(string)
+	if !ok || sessionID == "" {
+		return nil, fmt.Errorf("missing session ID in token claims")
+	}
+	jti, ok := claims["jti"].(string)
+	if !ok || jti == "" {
+		return nil, fmt.Errorf("missing token ID in token claims")
+	}
+	var actorID int64
+	if act, ok := claims["act"]; ok {
+		actClaims, ok := act.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("invalid actor claim")
+		}
+		if actorID, err = userIDFromClaims(actClaims); err != nil {
+			return nil, err
+		}
+	}
+	return &accessClaims{
+		userID:    userID,
+		sessionID: sessionID,
+		jti:       jti,
+		actorID:   actorID,
+	}, nil
+}
+
+// generatePurposeToken signs a short-lived token that is only valid for the
+// given purpose, e.g. completing a pending MFA login.
+func (app *application) generatePurposeToken(claims jwt.MapClaims, purpose string, ttl time.Duration) (string, error) {
+	_, iss, aud := app.authenticator.GetMetadata()
+	claims["typ"] = purpose
+	claims["exp"] = time.Now().Add(ttl).Unix()
+	claims["iat"] = time.Now().Unix()
+	claims["nbf"] = time.Now().Unix()
+	claims["iss"] = iss
+	claims["aud"] = aud
+	return app.authenticator.GenerateToken(claims)
+}
+
+func (app *application) parsePurposeToken(token, purpose string) (jwt.MapClaims, error) {
+	jwtToken, err := app.authenticator.ValidateToken(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid or expired token")
+	}
+	claims, ok := jwtToken.Claims.(jwt.MapClaims)
+	if !ok || !jwtToken.Valid || claims["typ"] != purpose {
+		return nil, fmt.Errorf("invalid token claims")
+	}
+	return claims, nil
+}
+
+func userIDFromClaims(claims jwt.MapClaims) (int64, error) {
+	userID, err := strconv.ParseInt(fmt.Sprintf("%.f", claims["sub"]), 10, 64)
+	if err != nil || userID <= 0 {
+		return 0, fmt.Errorf("invalid user ID in token claims")
+	}
+	return userID, nil
+}
+
+// getSessionFromContext returns the session of a request authenticated by
+// TokenAuthMiddleware, or nil for personal access tokens.
+func getSessionFromContext(r *http.Request) *store.Session {
+	session, _ := r.Context().Value(sessionKeyCtx).(*store.Session)
+	return session
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/samuel032khoury/gopherfeed/internal/email"
+	"github.com/samuel032khoury/gopherfeed/internal/mq/publisher"
+	"github.com/samuel032khoury/gopherfeed/internal/store"
+)
+
+// deviceSessionStore gives user 2 a second session on another device and
+// records what gets revoked
+type deviceSessionStore struct {
+	store.MockSessionStore
+	newDevice     bool
+	revoked       []string
+	revokedOthers string
+}
+
+func (s *deviceSessionStore) ListActive(ctx context.Context, userID int64) ([]*store.Session, error) {
+	return []*store.Session{
+		{ID: "test-session", UserID: userID, UserAgent: "Firefox"},
+		{ID: "other-session", UserID: userID, UserAgent: "Safari"},
+	}, nil
+}
+
+func (s *deviceSessionStore) IsNewDevice(ctx context.Context, userID int64, userAgent string) (bool, error) {
+	return s.newDevice, nil
+}
+
+func (s *deviceSessionStore) RevokeForUser(ctx context.Context, id string, userID int64) error {
+	if id != "test-session" && id != "other-session" {
+		return store.ErrNotFound
+	}
+	s.revoked = append(s.revoked, id)
+	return nil
+}
+
+func (s *deviceSessionStore) RevokeOthers(ctx context.Context, userID int64, keepID string) error {
+	s.revokedOthers = keepID
+	return nil
+}
+
+func TestSessions(t *testing.T) {
+	app := newTestApplication(t)
+	mux := app.mount()
+	sessions := &deviceSessionStore{}
+	app.store.Sessions = sessions
+
+	token, err := app.generateAccessToken(2, "test-session", "test-jti")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should reject access tokens replaced by a refresh", func(t *testing.T) {
+		staleToken, err := app.generateAccessToken(2, "test-session", "stale-jti")
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, err := http.NewRequest(http.MethodGet, "/v1/users/me/sessions", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+staleToken)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("should list sessions and mark the current one", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/v1/users/me/sessions", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusOK, rr.Code)
+
+		var response struct {
+			Data []struct {
+				ID      string `json:"id"`
+				Current bool   `json:"current"`
+			} `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		if len(response.Data) != 2 {
+			t.Fatalf("expected 2 sessions; got %d", len(response.Data))
+		}
+		for _, session := range response.Data {
+			if session.Current != (session.ID == "test-session") {
+				t.Errorf("session %s: expected current to be %t", session.ID, !session.Current)
+			}
+		}
+	})
+
+	t.Run("should revoke one session", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodDelete, "/v1/users/me/sessions/other-session", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusNoContent, rr.Code)
+		if len(sessions.revoked) != 1 || sessions.revoked[0] != "other-session" {
+			t.Errorf("expected other-session to be revoked; got %v", sessions.revoked)
+		}
+	})
+
+	t.Run("should not revoke unknown sessions", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodDelete, "/v1/users/me/sessions/unknown", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("should revoke all other sessions", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodDelete, "/v1/users/me/sessions", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusNoContent, rr.Code)
+		if sessions.revokedOthers != "test-session" {
+			t.Errorf("expected test-session to be kept; got %q", sessions.revokedOthers)
+		}
+	})
+}
+
+func TestNewDeviceNotification(t *testing.T) {
+	tests := []struct {
+		name      string
+		newDevice bool
+		sent      int
+	}{
+		{"should email the user about a new device", true, 1},
+		{"should not email the user about a known device", false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			mux := app.mount()
+			app.store.Sessions = &deviceSessionStore{newDevice: tt.newDevice}
+			emails := publisher.NewMockPublisher()
+			app.emailPublisher = emails
+
+			body := strings.NewReader(`{"email":"test@example.com","password":"password"}`)
+			req, err := http.NewRequest(http.MethodPost, "/v1/auth/login", body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("User-Agent", "Firefox")
+			rr := execRequest(req, mux)
+			checkResponseCode(t, http.StatusOK, rr.Code)
+
+			sent := emails.Sent()
+			if len(sent) != tt.sent {
+				t.Fatalf("expected %d emails; got %d", tt.sent, len(sent))
+			}
+			if tt.sent > 0 && sent[0].Template != email.NewDeviceTemplate {
+				t.Errorf("expected the new device template; got %s", sent[0].Template)
+			}
+		})
+	}
+}
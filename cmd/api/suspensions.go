@@ -0,0 +1,307 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/samuel032khoury/gopherfeed/internal/email"
+	"github.com/samuel032khoury/gopherfeed/internal/store"
+)
+
+type suspensionExemptKey string
+
+const suspensionExemptKeyCtx suspensionExemptKey = "suspension_exempt"
+
+type suspensionKey string
+
+const suspensionKeyCtx suspensionKey = "suspension"
+
+// suspensionNotice is what a suspended user is told about their suspension
+//
+//	@Description	The current user's suspension
+type suspensionNotice struct {
+	Scope      string  `json:"scope" example:"read_only" enums:"read_only,full"`
+	Reason     string  `json:"reason" example:"Repeated spam in comments"`
+	AppealNote string  `json:"appeal_note" example:"Those links were to my own blog"`
+	ExpiresAt  *string `json:"expires_at" example:"2026-01-13T07:22:18Z"`
+	CreatedAt  string  `json:"created_at" example:"2026-01-06T07:22:18Z"`
+}
+
+func newSuspensionNotice(suspension *store.Suspension) *suspensionNotice {
+	return &suspensionNotice{
+		Scope:      suspension.Scope,
+		Reason:     suspension.Reason,
+		AppealNote: suspension.AppealNote,
+		ExpiresAt:  suspension.ExpiresAt,
+		CreatedAt:  suspension.CreatedAt,
+	}
+}
+
+// suspendPayload represents the payload for suspending a user
+//
+//	@Description	Suspension request. Without a duration the user is banned until the suspension is lifted.
+type suspendPayload struct {
+	Scope    string `json:"scope" validate:"required,oneof=read_only full" example:"read_only"`
+	Reason   string `json:"reason" validate:"required,max=1000" example:"Repeated spam in comments"`
+	Duration string `json:"duration" validate:"omitempty,max=20" example:"168h"`
+}
+
+// SuspendUser godoc
+//
+//	@Summary		Suspend a user
+//	@Description	Suspend a user, replacing any suspension they are under. A read_only suspension blocks writes, a full suspension blocks sign in and signs the user out everywhere. The user is notified by email.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			userID	path		int								true	"User ID"
+//	@Param			payload	body		suspendPayload					true	"Suspension payload"
+//	@Success		201		{object}	DataResponse[store.Suspension]	"User suspended"
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/admin/users/{userID}/suspension [post]
+func (app *application) suspendUserHandler(w http.ResponseWriter, r *http.Request) {
+	var payload suspendPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	var duration time.Duration
+	if payload.Duration != "" {
+		d, err := time.ParseDuration(payload.Duration)
+		if err != nil || d <= 0 {
+			app.badRequestError(w, r, fmt.Errorf("duration must be a positive duration such as 72h"))
+			return
+		}
+		duration = d
+	}
+
+	ctx := r.Context()
+	actor := getCurrentUserFromContext(r)
+	target := getUserFromContext(r)
+	if !app.canManageUser(w, r, actor, target) {
+		return
+	}
+
+	suspension := &store.Suspension{
+		UserID:   target.ID,
+		IssuedBy: &actor.ID,
+		Scope:    payload.Scope,
+		Reason:   payload.Reason,
+	}
+	audit := func() *store.AuditEntry {
+		metadata := map[string]any{
+			"suspension_id": suspension.ID,
+			"scope":         suspension.Scope,
+			"reason":        suspension.Reason,
+			"expires_at":    suspension.ExpiresAt,
+		}
+		return auditEntry(r, actor.ID, "user.suspend", "user", strconv.FormatInt(target.ID, 10), metadata)
+	}
+	if err := app.store.Suspensions.Create(ctx, suspension, duration, audit); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if suspension.Scope == store.SuspensionScopeFull {
+		if err := app.store.Sessions.RevokeAllForUser(ctx, target.ID); err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+	}
+	app.sendSuspendedEmail(target, suspension)
+	app.jsonResponse(w, suspension, http.StatusCreated)
+}
+
+// LiftSuspension godoc
+//
+//	@Summary		Lift a suspension
+//	@Description	End a user's suspension before it expires. The user is notified by email.
+//	@Tags			admin
+//	@Produce		json
+//	@Param			userID	path		int	true	"User ID"
+//	@Success		204		{object}	nil	"Suspension lifted"
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse	"User is not suspended"
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/admin/users/{userID}/suspension [delete]
+func (app *application) liftSuspensionHandler(w http.ResponseWriter, r *http.Request) {
+	actor := getCurrentUserFromContext(r)
+	target := getUserFromContext(r)
+	if !app.canManageUser(w, r, actor, target) {
+		return
+	}
+	suspension := &store.Suspension{UserID: target.ID, LiftedBy: &actor.ID}
+	audit := func() *store.AuditEntry {
+		metadata := map[string]any{"suspension_id": suspension.ID}
+		return auditEntry(r, actor.ID, "user.reinstate", "user", strconv.FormatInt(target.ID, 10), metadata)
+	}
+	if err := app.store.Suspensions.Lift(r.Context(), suspension, audit); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundError(w, r)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	app.sendReinstatedEmail(target)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetOwnSuspension godoc
+//
+//	@Summary		Get your suspension
+//	@Description	Get the suspension the current user is under, if any
+//	@Tags			users
+//	@Produce		json
+//	@Success		200	{object}	DataResponse[suspensionNotice]
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse	"Not suspended"
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/users/me/suspension [get]
+func (app *application) getOwnSuspensionHandler(w http.ResponseWriter, r *http.Request) {
+	suspension := getSuspensionFromContext(r)
+	if suspension == nil {
+		app.notFoundError(w, r)
+		return
+	}
+	app.jsonResponse(w, newSuspensionNotice(suspension), http.StatusOK)
+}
+
+// appealPayload represents the payload for appealing a suspension
+//
+//	@Description	Suspension appeal
+type appealPayload struct {
+	Note string `json:"note" validate:"required,max=2000" example:"Those links were to my own blog"`
+}
+
+// AppealSuspension godoc
+//
+//	@Summary		Appeal your suspension
+//	@Description	Explain why the current user's suspension should be lifted. A later appeal replaces the earlier one. Users under a full suspension cannot sign in and appeal by replying to the suspension email instead.
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		appealPayload	true	"Appeal payload"
+//	@Success		204		{object}	nil				"Appeal recorded"
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse	"Not suspended"
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/users/me/suspension/appeal [put]
+func (app *application) appealSuspensionHandler(w http.ResponseWriter, r *http.Request) {
+	var payload appealPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	user := getCurrentUserFromContext(r)
+	audit := func() *store.AuditEntry {
+		return auditEntry(r, user.ID, "user.appeal", "user", strconv.FormatInt(user.ID, 10), nil)
+	}
+	if err := app.store.Suspensions.Appeal(r.Context(), user.ID, payload.Note, audit); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundError(w, r)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AllowWhileSuspended lets users under a read-only suspension make write
+// requests to the routes it wraps, so they can still appeal. It must run
+// before TokenAuthMiddleware. Full suspensions are refused regardless.
+func (app *application) AllowWhileSuspended(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), suspensionExemptKeyCtx, true)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// suspensionAllows reports whether a user under suspension may make the
+// request: full suspensions allow nothing, read-only ones allow reads and
+// the routes wrapped in AllowWhileSuspended.
+func suspensionAllows(r *http.Request, suspension *store.Suspension) bool {
+	if suspension.Scope != store.SuspensionScopeReadOnly {
+		return false
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	exempt, _ := r.Context().Value(suspensionExemptKeyCtx).(bool)
+	return exempt
+}
+
+// allowLogin writes a 403 response and returns false if the user is under a
+// full suspension. Read-only suspensions may still sign in.
+func (app *application) allowLogin(w http.ResponseWriter, r *http.Request, userID int64) bool {
+	suspension, err := app.store.Suspensions.GetActive(r.Context(), userID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return false
+	}
+	if suspension != nil && suspension.Scope == store.SuspensionScopeFull {
+		app.suspendedError(w, r, suspension)
+		return false
+	}
+	return true
+}
+
+func getSuspensionFromContext(r *http.Request) *store.Suspension {
+	suspension, ok := r.Context().Value(suspensionKeyCtx).(*store.Suspension)
+	if !ok {
+		return nil
+	}
+	return suspension
+}
+
+func (app *application) sendSuspendedEmail(user *store.User, suspension *store.Suspension) {
+	vars := struct {
+		Username  string
+		Reason    string
+		ReadOnly  bool
+		ExpiresAt string
+	}{
+		Username: user.Username,
+		Reason:   suspension.Reason,
+		ReadOnly: suspension.Scope == store.SuspensionScopeReadOnly,
+	}
+	if suspension.ExpiresAt != nil {
+		vars.ExpiresAt = *suspension.ExpiresAt
+	}
+	if err := app.emailPublisher.Publish(user.Email, email.SuspendedTemplate, vars); err != nil {
+		app.logger.Errorw("failed to send suspension email", "email", user.Email, "error", err)
+	}
+}
+
+func (app *application) sendReinstatedEmail(user *store.User) {
+	vars := struct {
+		Username string
+	}{
+		Username: user.Username,
+	}
+	if err := app.emailPublisher.Publish(user.Email, email.ReinstatedTemplate, vars); err != nil {
+		app.logger.Errorw("failed to send reinstatement email", "email", user.Email, "error", err)
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/samuel032khoury/gopherfeed/internal/email"
+	"github.com/samuel032khoury/gopherfeed/internal/mq/publisher"
+	"github.com/samuel032khoury/gopherfeed/internal/store"
+	"github.com/samuel032khoury/gopherfeed/internal/utils"
+)
+
+func TestSuspensions(t *testing.T) {
+	app := newTestApplication(t)
+	mux := app.mount()
+
+	// The mock Authenticate returns user 0; user 1 is read-only, user 3 is banned
+	app.store.Suspensions = &store.MockSuspensionStore{
+		Active: map[int64]*store.Suspension{
+			0: {UserID: 0, Scope: store.SuspensionScopeFull, Reason: "spam"},
+			1: {UserID: 1, Scope: store.SuspensionScopeReadOnly, Reason: "spam"},
+			3: {UserID: 3, Scope: store.SuspensionScopeFull, Reason: "spam"},
+		},
+	}
+
+	readOnlyToken, err := app.generateAccessToken(1, "test-session", "test-jti")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bannedToken, err := app.generateAccessToken(3, "test-session", "test-jti")
+	if err != nil {
+		t.Fatal(err)
+	}
+	activeToken, err := app.generateAccessToken(2, "test-session", "test-jti")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should let read-only suspended users read", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/v1/feeds", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+readOnlyToken)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("should stop read-only suspended users from writing", func(t *testing.T) {
+		body := strings.NewReader(`{"title":"title","content":"content"}`)
+		req, err := http.NewRequest(http.MethodPost, "/v1/posts", body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+readOnlyToken)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusForbidden, rr.Code)
+		if !strings.Contains(rr.Body.String(), "account suspended") {
+			t.Errorf("expected a suspension error; got %s", rr.Body.String())
+		}
+	})
+
+	t.Run("should let read-only suspended users appeal", func(t *testing.T) {
+		body := strings.NewReader(`{"note":"It was a misunderstanding"}`)
+		req, err := http.NewRequest(http.MethodPut, "/v1/users/me/suspension/appeal", body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+readOnlyToken)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusNoContent, rr.Code)
+	})
+
+	t.Run("should show users their suspension", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/v1/users/me/suspension", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+readOnlyToken)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusOK, rr.Code)
+
+		req.Header.Set("Authorization", "Bearer "+activeToken)
+		rr = execRequest(req, mux)
+		checkResponseCode(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("should refuse every request from banned users", func(t *testing.T) {
+		for _, path := range []string{"/v1/feeds", "/v1/users/me/suspension"} {
+			req, err := http.NewRequest(http.MethodGet, path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", "Bearer "+bannedToken)
+			rr := execRequest(req, mux)
+			checkResponseCode(t, http.StatusForbidden, rr.Code)
+		}
+	})
+
+	t.Run("should refuse to sign in banned users", func(t *testing.T) {
+		body := strings.NewReader(`{"email":"test@example.com","password":"password"}`)
+		req, err := http.NewRequest(http.MethodPost, "/v1/auth/login", body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("should refuse to sign in banned users enrolling in MFA", func(t *testing.T) {
+		challenge, err := app.generatePurposeToken(jwt.MapClaims{"sub": 3}, mfaChallengePurpose, utils.MFAChallengeExpiry)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body := strings.NewReader(`{"code":"123456"}`)
+		req, err := http.NewRequest(http.MethodPost, "/v1/auth/mfa/totp/confirm", body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set(mfaChallengeHeader, challenge)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("should require the user.ban permission to suspend", func(t *testing.T) {
+		body := strings.NewReader(`{"scope":"full","reason":"spam"}`)
+		req, err := http.NewRequest(http.MethodPost, "/v1/admin/users/4/suspension", body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+activeToken)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusForbidden, rr.Code)
+	})
+
+	app.permissions.set(1, []string{permUserBan})
+
+	t.Run("should reject invalid suspensions", func(t *testing.T) {
+		for _, payload := range []string{
+			`{"scope":"forever","reason":"spam"}`,
+			`{"scope":"full"}`,
+			`{"scope":"full","reason":"spam","duration":"-1h"}`,
+			`{"scope":"full","reason":"spam","duration":"a week"}`,
+		} {
+			req, err := http.NewRequest(http.MethodPost, "/v1/admin/users/4/suspension", strings.NewReader(payload))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", "Bearer "+activeToken)
+			rr := execRequest(req, mux)
+			checkResponseCode(t, http.StatusBadRequest, rr.Code)
+		}
+	})
+
+	// Every mock user has the same role level, so no one outranks anyone
+	t.Run("should not let moderators suspend their peers", func(t *testing.T) {
+		body := strings.NewReader(`{"scope":"full","reason":"spam","duration":"72h"}`)
+		req, err := http.NewRequest(http.MethodPost, "/v1/admin/users/4/suspension", body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+activeToken)
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusForbidden, rr.Code)
+	})
+}
+
+// expiringSuspensionStore has the suspensions in expired run out
+type expiringSuspensionStore struct {
+	store.MockSuspensionStore
+	expired []*store.Suspension
+}
+
+func (s *expiringSuspensionStore) LiftExpired(ctx context.Context) ([]*store.Suspension, error) {
+	return s.expired, nil
+}
+
+func TestLiftExpiredSuspensions(t *testing.T) {
+	app := newTestApplication(t)
+	emails := publisher.NewMockPublisher()
+	app.emailPublisher = emails
+
+	// User 3 was suspended again before the job ran
+	app.store.Suspensions = &expiringSuspensionStore{
+		MockSuspensionStore: store.MockSuspensionStore{
+			Active: map[int64]*store.Suspension{
+				3: {UserID: 3, Scope: store.SuspensionScopeFull, Reason: "spam"},
+			},
+		},
+		expired: []*store.Suspension{
+			{UserID: 1, Scope: store.SuspensionScopeReadOnly, Reason: "spam"},
+			{UserID: 3, Scope: store.SuspensionScopeReadOnly, Reason: "spam"},
+		},
+	}
+
+	t.Run("should only tell users without a new suspension", func(t *testing.T) {
+		if err := app.liftExpiredSuspensions(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		sent := emails.Sent()
+		if len(sent) != 1 || sent[0].Template != email.ReinstatedTemplate {
+			t.Errorf("expected one reinstated email; got %+v", sent)
+		}
+	})
+}
@@ -5,7 +5,11 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/go-webauthn/webauthn/webauthn"
 	"github.com/samuel032khoury/gopherfeed/internal/auth"
+	"github.com/samuel032khoury/gopherfeed/internal/lockout"
+	"github.com/samuel032khoury/gopherfeed/internal/mq/publisher"
+	"github.com/samuel032khoury/gopherfeed/internal/password"
 	"github.com/samuel032khoury/gopherfeed/internal/ratelimiter"
 	"github.com/samuel032khoury/gopherfeed/internal/store"
 	"github.com/samuel032khoury/gopherfeed/internal/store/cache"
@@ -24,6 +28,24 @@ func newTestApplication(t *testing.T) *application {
 		"gopherfeed-api",
 		"gopherfeed",
 	)
+	mockWebAuthn, err := webauthn.New(&webauthn.Config{
+		RPID:          "localhost",
+		RPDisplayName: "GopherFeed",
+		RPOrigins:     []string{"http://localhost:5173"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mockHasher, err := password.NewArgon2idHasher(password.Params{
+		Memory:      64,
+		Iterations:  1,
+		Parallelism: 1,
+		SaltLength:  16,
+		KeyLength:   32,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
 	testConfig := config{
 		addr:            ":8080",
 		frontendBaseURL: "http://localhost:5173",
@@ -33,12 +55,24 @@ func newTestApplication(t *testing.T) *application {
 		env: "test",
 	}
 	return &application{
-		config:        testConfig,
-		logger:        logger,
-		store:         &mockStore,
-		cacheStorage:  mockCache,
-		ratelimiter:   mockRatelimiter,
-		authenticator: mockAuthenticator,
+		config:            testConfig,
+		logger:            logger,
+		store:             &mockStore,
+		cacheStorage:      mockCache,
+		ratelimiter:       mockRatelimiter,
+		magicLinkLimiter:  ratelimiter.NewMockRateLimiter(),
+		resetLimiter:      ratelimiter.NewMockRateLimiter(),
+		lockNoticeLimiter: ratelimiter.NewMockRateLimiter(),
+		emailPublisher:    publisher.NewMockPublisher(),
+		webauthn:          mockWebAuthn,
+		permissions:       newPermissionCache(permissionCacheTTL),
+		authenticator:     mockAuthenticator,
+		passwordHasher:    mockHasher,
+		passwordPolicy:    &password.Policy{MinEntropy: 40},
+		loginAttempts: loginTrackers{
+			account: lockout.NewMockTracker(),
+			ip:      lockout.NewMockTracker(),
+		},
 	}
 }
 
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/samuel032khoury/gopherfeed/internal/auth"
+	"github.com/samuel032khoury/gopherfeed/internal/store"
+	"github.com/samuel032khoury/gopherfeed/internal/utils"
+)
+
+// Scopes a personal access token can be granted
+const (
+	scopePostsRead     = "posts:read"
+	scopePostsWrite    = "posts:write"
+	scopeCommentsWrite = "comments:write"
+	scopeFeedRead      = "feed:read"
+	scopeUsersWrite    = "users:write"
+)
+
+// createTokenPayload represents the payload for creating a personal access token
+//
+//	@Description	Personal access token creation payload
+type createTokenPayload struct {
+	Name          string   `json:"name" validate:"required,max=100" example:"ci-bot"`
+	Scopes        []string `json:"scopes" validate:"required,min=1,dive,oneof=posts:read posts:write comments:write feed:read users:write" example:"posts:read,feed:read"`
+	ExpiresInDays int      `json:"expires_in_days" validate:"required,min=1,max=365" example:"90"`
+}
+
+// createTokenResponse represents a newly created personal access token
+//
+//	@Description	Personal access token including the secret, which is only shown once
+type createTokenResponse struct {
+	store.PersonalAccessToken
+	Token string `json:"token" example:"gfp_Zm9vYmFyYmF6cXV4..."`
+}
+
+// CreateToken godoc
+//
+//	@Summary		Create a personal access token
+//	@Description	Create a named, scoped, expiring token for API and script clients. Use it as `Authorization: Bearer <token>`.
+//	@Tags			tokens
+//	@Accept			json
+//	@Produce		json
+//	@Param			token	body		createTokenPayload					true	"Token payload"
+//	@Success		201		{object}	DataResponse[createTokenResponse]	"Token created"
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse	"Unauthorized - login required"
+//	@Failure		403		{object}	ErrorResponse	"Not available to personal access tokens"
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/users/me/tokens [post]
+func (app *application) createTokenHandler(w http.ResponseWriter, r *http.Request) {
+	var payload createTokenPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	token, err := auth.GeneratePersonalAccessToken()
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	pat := &store.PersonalAccessToken{
+		UserID: getCurrentUserFromContext(r).ID,
+		Name:   payload.Name,
+		Scopes: payload.Scopes,
+	}
+	exp := time.Duration(payload.ExpiresInDays) * 24 * time.Hour
+	if err := app.store.Tokens.Create(r.Context(), pat, utils.Hash(token), exp); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	response := createTokenResponse{
+		PersonalAccessToken: *pat,
+		Token:               token,
+	}
+	app.jsonResponse(w, response, http.StatusCreated)
+}
+
+// ListTokens godoc
+//
+//	@Summary		List personal access tokens
+//	@Description	List the current user's personal access tokens without their secrets
+//	@Tags			tokens
+//	@Produce		json
+//	@Success		200	{object}	DataResponse[[]store.PersonalAccessToken]
+//	@Failure		401	{object}	ErrorResponse	"Unauthorized - login required"
+//	@Failure		403	{object}	ErrorResponse	"Not available to personal access tokens"
+//	@Failure		500	{object}	ErrorResponse
+//	@Router			/users/me/tokens [get]
+func (app *application) listTokensHandler(w http.ResponseWriter, r *http.Request) {
+	tokens, err := app.store.Tokens.ListByUser(r.Context(), getCurrentUserFromContext(r).ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, tokens, http.StatusOK)
+}
+
+// RevokeToken godoc
+//
+//	@Summary		Revoke a personal access token
+//	@Description	Delete one of the current user's personal access tokens
+//	@Tags			tokens
+//	@Param			tokenID	path		int	true	"Token ID"
+//	@Success		204		{object}	nil	"Token revoked"
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse	"Unauthorized - login required"
+//	@Failure		403		{object}	ErrorResponse	"Not available to personal access tokens"
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/users/me/tokens/{tokenID} [delete]
+func (app *application) revokeTokenHandler(w http.ResponseWriter, r *http.Request) {
+	tokenID, err := strconv.ParseInt(chi.URLParam(r, "tokenID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := app.store.Tokens.Delete(r.Context(), tokenID, getCurrentUserFromContext(r).ID); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundError(w, r)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/samuel032khoury/gopherfeed/internal/store"
+)
+
+// ListDeletedPosts godoc
+//
+//	@Summary		List deleted posts
+//	@Description	List the posts in the trash, most recently deleted first by default. Posts are purged once the retention period runs out.
+//	@Tags			admin
+//	@Produce		json
+//	@Param			limit	query		int		false	"Number of items per page (1-100)"	example(20)
+//	@Param			offset	query		int		false	"Number of items to skip"			example(0)
+//	@Param			sort	query		string	false	"Sort order by deletion time"		Enums(asc, desc)
+//	@Success		200		{object}	DataResponse[[]store.Post]
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/admin/trash/posts [get]
+func (app *application) listDeletedPostsHandler(w http.ResponseWriter, r *http.Request) {
+	params, ok := app.readTrashParams(w, r)
+	if !ok {
+		return
+	}
+	posts, err := app.store.Posts.ListDeleted(r.Context(), params)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, posts, http.StatusOK)
+}
+
+// RestorePost godoc
+//
+//	@Summary		Restore a deleted post
+//	@Description	Take a post out of the trash, along with its comments
+//	@Tags			admin
+//	@Produce		json
+//	@Param			postID	path		int	true	"Post ID"
+//	@Success		204		{object}	nil	"Post restored"
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse	"Post is not in the trash"
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/admin/trash/posts/{postID}/restore [post]
+func (app *application) restorePostHandler(w http.ResponseWriter, r *http.Request) {
+	postID, err := strconv.ParseInt(chi.URLParam(r, "postID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	audit := func() *store.AuditEntry {
+		return auditEntry(r, getCurrentUserFromContext(r).ID, "post.restore", "post", strconv.FormatInt(postID, 10), nil)
+	}
+	if err := app.store.Posts.Restore(r.Context(), postID, audit); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundError(w, r)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListDeletedComments godoc
+//
+//	@Summary		List deleted comments
+//	@Description	List the comments in the trash, most recently deleted first by default. Comments are purged once the retention period runs out.
+//	@Tags			admin
+//	@Produce		json
+//	@Param			limit	query		int		false	"Number of items per page (1-100)"	example(20)
+//	@Param			offset	query		int		false	"Number of items to skip"			example(0)
+//	@Param			sort	query		string	false	"Sort order by deletion time"		Enums(asc, desc)
+//	@Success		200		{object}	DataResponse[[]store.Comment]
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/admin/trash/comments [get]
+func (app *application) listDeletedCommentsHandler(w http.ResponseWriter, r *http.Request) {
+	params, ok := app.readTrashParams(w, r)
+	if !ok {
+		return
+	}
+	comments, err := app.store.Comments.ListDeleted(r.Context(), params)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, comments, http.StatusOK)
+}
+
+// RestoreComment godoc
+//
+//	@Summary		Restore a deleted comment
+//	@Description	Take a comment out of the trash. It stays hidden while its post is in the trash.
+//	@Tags			admin
+//	@Produce		json
+//	@Param			commentID	path		int	true	"Comment ID"
+//	@Success		204			{object}	nil	"Comment restored"
+//	@Failure		400			{object}	ErrorResponse
+//	@Failure		401			{object}	ErrorResponse
+//	@Failure		403			{object}	ErrorResponse
+//	@Failure		404			{object}	ErrorResponse	"Comment is not in the trash"
+//	@Failure		500			{object}	ErrorResponse
+//	@Router			/admin/trash/comments/{commentID}/restore [post]
+func (app *application) restoreCommentHandler(w http.ResponseWriter, r *http.Request) {
+	commentID, err := strconv.ParseInt(chi.URLParam(r, "commentID"), 10, 64)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	audit := func() *store.AuditEntry {
+		return auditEntry(r, getCurrentUserFromContext(r).ID, "comment.restore", "comment", strconv.FormatInt(commentID, 10), nil)
+	}
+	if err := app.store.Comments.Restore(r.Context(), commentID, audit); err != nil {
+		switch err {
+		case store.ErrNotFound:
+			app.notFoundError(w, r)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (app *application) readTrashParams(w http.ResponseWriter, r *http.Request) (*store.PaginationParams, bool) {
+	params := &store.PaginationParams{
+		Limit:  20,
+		Offset: 0,
+		Sort:   "desc",
+	}
+	params, err := params.Parse(r)
+	if err != nil {
+		app.badRequestError(w, r, err)
+		return nil, false
+	}
+	if err := Validate.Struct(params); err != nil {
+		app.badRequestError(w, r, err)
+		return nil, false
+	}
+	return params, true
+}
@@ -3,8 +3,12 @@ package main
 import (
 	"fmt"
 	"net/http"
+	"strings"
 
+	"github.com/google/uuid"
+	"github.com/samuel032khoury/gopherfeed/internal/email"
 	"github.com/samuel032khoury/gopherfeed/internal/store"
+	"github.com/samuel032khoury/gopherfeed/internal/utils"
 )
 
 type userKey string
@@ -92,6 +96,158 @@ func (app *application) unfollowUserHandler(w http.ResponseWriter, r *http.Reque
 	w.WriteHeader(http.StatusOK)
 }
 
+// changeEmailPayload represents the payload for changing the email address
+//
+//	@Description	Email change payload
+type changeEmailPayload struct {
+	Email           string `json:"email" validate:"required,email,max=255" example:"new@example.com"`
+	CurrentPassword string `json:"current_password" validate:"required,max=256" example:"plum-Orbit-71-lantern"`
+}
+
+// ChangeEmail godoc
+//
+//	@Summary		Change email address
+//	@Description	Send a confirmation link to the new address and a notice to the current one. The address only changes once the link is confirmed. Wrong current passwords count towards the login lockout.
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		changeEmailPayload				true	"New email address and current password"
+//	@Success		202		{object}	DataResponse[messageResponse]	"Confirmation link sent"
+//	@Failure		400		{object}	ErrorResponse	"Invalid email"
+//	@Failure		401		{object}	ErrorResponse	"Wrong current password"
+//	@Failure		403		{object}	ErrorResponse
+//	@Failure		409		{object}	ErrorResponse	"Email already in use"
+//	@Failure		429		{object}	ErrorResponse	"Too many failed attempts, see Retry-After"
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/users/me/email [put]
+func (app *application) changeEmailHandler(w http.ResponseWriter, r *http.Request) {
+	var payload changeEmailPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	user := getCurrentUserFromContext(r)
+	if strings.EqualFold(payload.Email, user.Email) {
+		app.badRequestError(w, r, fmt.Errorf("new email must differ from the current one"))
+		return
+	}
+	accountKey := loginAccountKey(user.Email)
+	if !app.allowLoginAttempt(w, r, accountKey) {
+		return
+	}
+	if _, err := app.store.Users.Authenticate(ctx, user.Email, payload.CurrentPassword); err != nil {
+		switch err {
+		case store.ErrInvalidCredentials:
+			app.recordLoginFailure(ctx, r, accountKey, func() (*store.User, error) {
+				return user, nil
+			})
+			app.unauthorizedError(w, r, false, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	app.resetLoginFailures(ctx, accountKey)
+
+	existing, err := app.store.Users.GetByEmail(ctx, payload.Email)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if existing != nil {
+		app.conflictError(w, r, store.ErrDuplicateEmail)
+		return
+	}
+
+	token := uuid.New().String()
+	exp := utils.EmailChangeExpiry
+	if err := app.store.Users.CreateEmailChange(ctx, user.ID, payload.Email, utils.Hash(token), exp); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	isProdEnv := app.config.env == "production"
+	confirmVars := struct {
+		Username   string
+		NewEmail   string
+		ConfirmURL string
+		ExpiresIn  string
+	}{
+		Username:   user.Username,
+		NewEmail:   payload.Email,
+		ConfirmURL: utils.GenerateEmailChangeURL(app.config.frontendBaseURL, token, isProdEnv),
+		ExpiresIn:  exp.String(),
+	}
+	if err := app.emailPublisher.Publish(payload.Email, email.EmailChangeTemplate, confirmVars); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	noticeVars := struct {
+		Username string
+		NewEmail string
+	}{
+		Username: user.Username,
+		NewEmail: payload.Email,
+	}
+	if err := app.emailPublisher.Publish(user.Email, email.EmailNoticeTemplate, noticeVars); err != nil {
+		app.logger.Errorw("failed to send email change notice", "email", user.Email, "error", err)
+	}
+
+	response := messageResponse{
+		Message: "A confirmation link has been sent to the new address",
+	}
+	app.jsonResponse(w, response, http.StatusAccepted)
+}
+
+// ConfirmEmailChange godoc
+//
+//	@Summary		Confirm an email change
+//	@Description	Consume an email change token and switch the account to the new address
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		tokenDTO					true	"Email change token"
+//	@Success		200		{object}	DataResponse[store.User]	"Email address changed"
+//	@Failure		400		{object}	ErrorResponse	"Invalid token, or email already in use"
+//	@Failure		500		{object}	ErrorResponse
+//	@Router			/auth/email/confirm [post]
+func (app *application) confirmEmailChangeHandler(w http.ResponseWriter, r *http.Request) {
+	payload := &tokenDTO{}
+	if err := readJSON(w, r, payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestError(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	user, err := app.store.Users.ConfirmEmailChange(ctx, utils.Hash(payload.Token))
+	if err != nil {
+		switch err {
+		case store.ErrInvalidToken:
+			app.badRequestError(w, r, err)
+		case store.ErrDuplicateEmail:
+			// Someone else claimed the address after the change was requested
+			app.badRequestError(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+	if err := app.invalidateUser(ctx, user.ID); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.jsonResponse(w, user, http.StatusOK)
+}
+
 func getCurrentUserFromContext(r *http.Request) *store.User {
 	user, ok := r.Context().Value(currUserKeyCtx).(*store.User)
 	if !ok {
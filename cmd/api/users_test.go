@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/samuel032khoury/gopherfeed/internal/email"
+	"github.com/samuel032khoury/gopherfeed/internal/mq/publisher"
+	"github.com/samuel032khoury/gopherfeed/internal/store"
+	"github.com/samuel032khoury/gopherfeed/internal/store/cache"
+)
+
+// emailChangeUserStore accepts "password" as the current password, has
+// taken@example.com registered to someone else and confirms every email
+// change token for user 2
+type emailChangeUserStore struct {
+	store.MockUserStore
+}
+
+func (s *emailChangeUserStore) Authenticate(ctx context.Context, email, password string) (*store.User, error) {
+	if password != "password" {
+		return nil, store.ErrInvalidCredentials
+	}
+	return s.GetByEmail(ctx, email)
+}
+
+func (s *emailChangeUserStore) GetByEmail(ctx context.Context, email string) (*store.User, error) {
+	if email != "taken@example.com" {
+		return nil, nil
+	}
+	return &store.User{ID: 3, Email: email}, nil
+}
+
+func (s *emailChangeUserStore) ConfirmEmailChange(ctx context.Context, token string) (*store.User, error) {
+	return &store.User{ID: 2, Username: "testuser", Email: "new@example.com"}, nil
+}
+
+// recordingUserCache records which users are dropped from the cache
+type recordingUserCache struct {
+	cache.MockUserCache
+	deleted []int64
+}
+
+func (c *recordingUserCache) Delete(ctx context.Context, id int64) error {
+	c.deleted = append(c.deleted, id)
+	return nil
+}
+
+func TestChangeEmail(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		code int
+		sent int
+	}{
+		{
+			"should send a confirmation link and a notice",
+			`{"email":"new@example.com","current_password":"password"}`,
+			http.StatusAccepted,
+			2,
+		},
+		{
+			"should reject a wrong current password",
+			`{"email":"new@example.com","current_password":"wrong"}`,
+			http.StatusUnauthorized,
+			0,
+		},
+		{
+			"should require the current password",
+			`{"email":"new@example.com"}`,
+			http.StatusBadRequest,
+			0,
+		},
+		{
+			"should reject emails in use",
+			`{"email":"taken@example.com","current_password":"password"}`,
+			http.StatusConflict,
+			0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+			mux := app.mount()
+			app.store.Users = &emailChangeUserStore{}
+			emails := publisher.NewMockPublisher()
+			app.emailPublisher = emails
+
+			token, err := app.generateAccessToken(2, "test-session", "test-jti")
+			if err != nil {
+				t.Fatal(err)
+			}
+			req, err := http.NewRequest(http.MethodPut, "/v1/users/me/email", strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+			rr := execRequest(req, mux)
+			checkResponseCode(t, tt.code, rr.Code)
+
+			sent := emails.Sent()
+			if len(sent) != tt.sent {
+				t.Fatalf("expected %d emails; got %d", tt.sent, len(sent))
+			}
+			if tt.sent > 0 && (sent[0].To != "new@example.com" || sent[0].Template != email.EmailChangeTemplate) {
+				t.Errorf("expected a confirmation link to the new address; got %+v", sent[0])
+			}
+		})
+	}
+}
+
+func TestConfirmEmailChangeInvalidatesCache(t *testing.T) {
+	app := newTestApplication(t)
+	mux := app.mount()
+	app.store.Users = &emailChangeUserStore{}
+	app.config.cache.enabled = true
+	users := &recordingUserCache{}
+	app.cacheStorage.Users = users
+
+	t.Run("should drop the cached user once the change is confirmed", func(t *testing.T) {
+		body := strings.NewReader(`{"token":"123e4567-e89b-42d3-a456-426614174000"}`)
+		req, err := http.NewRequest(http.MethodPost, "/v1/auth/email/confirm", body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := execRequest(req, mux)
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		if len(users.deleted) != 1 || users.deleted[0] != 2 {
+			t.Errorf("expected user 2 to be dropped from the cache; got %v", users.deleted)
+		}
+	})
+}
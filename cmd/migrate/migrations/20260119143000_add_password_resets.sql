@@ -0,0 +1,9 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS password_resets (
+    token bytea PRIMARY KEY NOT NULL,
+    user_id bigint REFERENCES users(id) ON DELETE CASCADE,
+    expires_at timestamptz NOT NULL
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS password_resets;
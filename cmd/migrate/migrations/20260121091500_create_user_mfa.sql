@@ -0,0 +1,20 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS user_mfa (
+    user_id bigint PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret TEXT NOT NULL,
+    last_counter bigint NOT NULL DEFAULT 0,
+    confirmed_at timestamptz,
+    created_at timestamptz NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+    user_id bigint NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code bytea NOT NULL,
+    used_at timestamptz,
+
+    PRIMARY KEY (user_id, code)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS mfa_recovery_codes;
+DROP TABLE IF EXISTS user_mfa;
@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS login_tokens (
+    token bytea PRIMARY KEY NOT NULL,
+    user_id bigint NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    email CITEXT NOT NULL,
+    expires_at timestamptz NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_tokens_user_id ON login_tokens (user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS login_tokens;
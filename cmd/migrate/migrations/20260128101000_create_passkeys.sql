@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS passkeys (
+    id BIGSERIAL PRIMARY KEY,
+    user_id bigint NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    credential_id bytea UNIQUE NOT NULL,
+    credential jsonb NOT NULL,
+    sign_count bigint NOT NULL DEFAULT 0,
+    last_used_at timestamptz,
+    created_at timestamptz NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_passkeys_user_id ON passkeys (user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS passkeys;
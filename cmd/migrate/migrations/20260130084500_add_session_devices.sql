@@ -0,0 +1,16 @@
+-- +goose Up
+ALTER TABLE user_sessions
+    ADD COLUMN IF NOT EXISTS user_agent TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS ip VARCHAR(45) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS access_jti uuid,
+    ADD COLUMN IF NOT EXISTS last_seen_at timestamptz NOT NULL DEFAULT NOW();
+
+CREATE INDEX IF NOT EXISTS idx_user_sessions_user_agent ON user_sessions (user_id, user_agent);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_user_sessions_user_agent;
+ALTER TABLE user_sessions
+    DROP COLUMN IF EXISTS user_agent,
+    DROP COLUMN IF EXISTS ip,
+    DROP COLUMN IF EXISTS access_jti,
+    DROP COLUMN IF EXISTS last_seen_at;
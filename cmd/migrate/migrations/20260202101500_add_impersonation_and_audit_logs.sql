@@ -0,0 +1,21 @@
+-- +goose Up
+ALTER TABLE user_sessions
+    ADD COLUMN IF NOT EXISTS impersonator_id bigint REFERENCES users(id) ON DELETE CASCADE;
+
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id BIGSERIAL PRIMARY KEY,
+    actor_id bigint REFERENCES users(id) ON DELETE SET NULL,
+    action VARCHAR(100) NOT NULL,
+    target_type VARCHAR(50) NOT NULL DEFAULT '',
+    target_id VARCHAR(100) NOT NULL DEFAULT '',
+    metadata jsonb NOT NULL DEFAULT '{}',
+    ip VARCHAR(45) NOT NULL DEFAULT '',
+    created_at timestamptz NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor_id ON audit_logs (actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs (created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS audit_logs;
+ALTER TABLE user_sessions DROP COLUMN IF EXISTS impersonator_id;
@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS email_changes (
+    token bytea PRIMARY KEY NOT NULL,
+    user_id bigint NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    new_email CITEXT NOT NULL,
+    expires_at timestamptz NOT NULL
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS email_changes;
@@ -0,0 +1,33 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS permissions (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id bigint NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    permission_id bigint NOT NULL REFERENCES permissions(id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission_id)
+);
+
+INSERT INTO permissions (name, description) VALUES
+('post.update.any', 'Edit posts written by other users'),
+('post.delete.any', 'Delete posts written by other users'),
+('comment.delete.any', 'Delete comments written by other users'),
+('user.ban', 'Suspend and ban user accounts'),
+('user.unlock', 'Lift login lockouts'),
+('user.impersonate', 'Act as a less privileged user');
+
+-- Matches what the role levels granted before permissions existed
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name IN ('post.update.any', 'comment.delete.any')
+WHERE r.name = 'moderator';
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p
+WHERE r.name = 'admin';
+
+-- +goose Down
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS permissions;
@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE UNIQUE INDEX IF NOT EXISTS idx_roles_name ON roles (name);
+
+INSERT INTO permissions (name, description) VALUES
+('user.manage', 'List users, assign roles and activate or deactivate accounts'),
+('role.manage', 'Create and edit roles');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name IN ('user.manage', 'role.manage')
+WHERE r.name = 'admin';
+
+-- +goose Down
+DELETE FROM permissions WHERE name IN ('user.manage', 'role.manage');
+DROP INDEX IF EXISTS idx_roles_name;
@@ -0,0 +1,27 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS user_suspensions (
+    id BIGSERIAL PRIMARY KEY,
+    user_id bigint NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    issued_by bigint REFERENCES users(id) ON DELETE SET NULL,
+    scope VARCHAR(20) NOT NULL CHECK (scope IN ('read_only', 'full')),
+    reason text NOT NULL,
+    appeal_note text NOT NULL DEFAULT '',
+    appealed_at timestamptz,
+    expires_at timestamptz,
+    lifted_at timestamptz,
+    lifted_by bigint REFERENCES users(id) ON DELETE SET NULL,
+    created_at timestamptz NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_suspensions_user_id ON user_suspensions (user_id) WHERE lifted_at IS NULL;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'user.ban'
+WHERE r.name = 'moderator'
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DELETE FROM role_permissions
+WHERE role_id = (SELECT id FROM roles WHERE name = 'moderator')
+  AND permission_id = (SELECT id FROM permissions WHERE name = 'user.ban');
+DROP TABLE IF EXISTS user_suspensions;
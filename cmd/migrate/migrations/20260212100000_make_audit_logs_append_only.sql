@@ -0,0 +1,43 @@
+-- +goose Up
+-- Audit entries must outlive the users they mention, and ON DELETE SET NULL
+-- would be an update to an append-only table.
+ALTER TABLE audit_logs
+    DROP CONSTRAINT IF EXISTS audit_logs_actor_id_fkey,
+    ADD COLUMN IF NOT EXISTS request_id VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS snapshot_before jsonb,
+    ADD COLUMN IF NOT EXISTS snapshot_after jsonb;
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_action ON audit_logs (action);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_target ON audit_logs (target_type, target_id);
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER audit_logs_append_only
+    BEFORE UPDATE OR DELETE OR TRUNCATE ON audit_logs
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_logs_append_only();
+
+INSERT INTO permissions (name, description) VALUES
+('audit.read', 'Search and export the audit log');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'audit.read'
+WHERE r.name = 'admin';
+
+-- +goose Down
+DELETE FROM permissions WHERE name = 'audit.read';
+DROP TRIGGER IF EXISTS audit_logs_append_only ON audit_logs;
+DROP FUNCTION IF EXISTS audit_logs_append_only();
+DROP INDEX IF EXISTS idx_audit_logs_target;
+DROP INDEX IF EXISTS idx_audit_logs_action;
+UPDATE audit_logs SET actor_id = NULL WHERE actor_id NOT IN (SELECT id FROM users);
+ALTER TABLE audit_logs
+    DROP COLUMN IF EXISTS snapshot_after,
+    DROP COLUMN IF EXISTS snapshot_before,
+    DROP COLUMN IF EXISTS request_id,
+    ADD CONSTRAINT audit_logs_actor_id_fkey FOREIGN KEY (actor_id) REFERENCES users(id) ON DELETE SET NULL;
@@ -0,0 +1,25 @@
+-- +goose Up
+ALTER TABLE posts
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'published'
+        CHECK (status IN ('draft', 'scheduled', 'published')),
+    ADD COLUMN IF NOT EXISTS publish_at timestamptz,
+    ADD COLUMN IF NOT EXISTS published_at timestamptz;
+
+UPDATE posts SET published_at = created_at WHERE published_at IS NULL;
+
+ALTER TABLE posts
+    ADD CONSTRAINT posts_publish_at_check CHECK (status <> 'scheduled' OR publish_at IS NOT NULL),
+    ADD CONSTRAINT posts_published_at_check CHECK (status <> 'published' OR published_at IS NOT NULL);
+
+CREATE INDEX IF NOT EXISTS idx_posts_publish_at ON posts (publish_at) WHERE status = 'scheduled';
+CREATE INDEX IF NOT EXISTS idx_posts_published_at ON posts (published_at) WHERE status = 'published';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_posts_published_at;
+DROP INDEX IF EXISTS idx_posts_publish_at;
+ALTER TABLE posts
+    DROP CONSTRAINT IF EXISTS posts_published_at_check,
+    DROP CONSTRAINT IF EXISTS posts_publish_at_check,
+    DROP COLUMN IF EXISTS published_at,
+    DROP COLUMN IF EXISTS publish_at,
+    DROP COLUMN IF EXISTS status;
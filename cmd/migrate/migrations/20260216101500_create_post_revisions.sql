@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS post_revisions (
+    post_id bigint NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    version INT NOT NULL,
+    title text NOT NULL,
+    content text NOT NULL,
+    tags VARCHAR(100)[],
+    editor_id bigint REFERENCES users(id) ON DELETE SET NULL,
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (post_id, version)
+);
+
+-- Earlier versions were overwritten, so history starts at the current one
+INSERT INTO post_revisions (post_id, version, title, content, tags, editor_id, created_at)
+SELECT id, version, title, content, tags, user_id, updated_at FROM posts
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DROP TABLE IF EXISTS post_revisions;
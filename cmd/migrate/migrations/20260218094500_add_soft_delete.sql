@@ -0,0 +1,32 @@
+-- +goose Up
+ALTER TABLE posts
+    ADD COLUMN IF NOT EXISTS deleted_at timestamptz,
+    ADD COLUMN IF NOT EXISTS deleted_by bigint REFERENCES users(id) ON DELETE SET NULL;
+
+ALTER TABLE comments
+    ADD COLUMN IF NOT EXISTS deleted_at timestamptz,
+    ADD COLUMN IF NOT EXISTS deleted_by bigint REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_posts_deleted_at ON posts (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_comments_deleted_at ON comments (deleted_at) WHERE deleted_at IS NOT NULL;
+
+INSERT INTO permissions (name, description) VALUES
+('trash.manage', 'List and restore deleted posts and comments');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'trash.manage'
+WHERE r.name IN ('admin', 'moderator');
+
+-- +goose Down
+DELETE FROM permissions WHERE name = 'trash.manage';
+DROP INDEX IF EXISTS idx_comments_deleted_at;
+DROP INDEX IF EXISTS idx_posts_deleted_at;
+-- Whatever is still in the trash would reappear without the columns
+DELETE FROM comments WHERE deleted_at IS NOT NULL;
+DELETE FROM posts WHERE deleted_at IS NOT NULL;
+ALTER TABLE comments
+    DROP COLUMN IF EXISTS deleted_by,
+    DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE posts
+    DROP COLUMN IF EXISTS deleted_by,
+    DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Rows are rendered by the post-rerender job, which picks up every post
+-- rendered by an older version of the renderer
+ALTER TABLE posts
+    ADD COLUMN IF NOT EXISTS content_html text NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS render_version INT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_posts_render_version ON posts (render_version);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_posts_render_version;
+ALTER TABLE posts
+    DROP COLUMN IF EXISTS render_version,
+    DROP COLUMN IF EXISTS content_html;
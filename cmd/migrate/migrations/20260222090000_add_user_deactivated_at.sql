@@ -0,0 +1,13 @@
+-- +goose Up
+-- is_active only records that the email was confirmed, deactivation by an
+-- administrator is tracked separately so users cannot undo it themselves
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deactivated_at timestamptz;
+
+-- Inactive users without an invitation were deactivated by an administrator
+UPDATE users u SET is_active = TRUE, deactivated_at = NOW()
+WHERE u.is_active = FALSE
+AND NOT EXISTS (SELECT 1 FROM user_invitations ui WHERE ui.user_id = u.id);
+
+-- +goose Down
+UPDATE users SET is_active = FALSE WHERE deactivated_at IS NOT NULL;
+ALTER TABLE users DROP COLUMN IF EXISTS deactivated_at;
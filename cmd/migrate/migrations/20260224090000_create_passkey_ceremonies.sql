@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS passkey_ceremonies (
+    id VARCHAR(64) PRIMARY KEY,
+    purpose VARCHAR(50) NOT NULL,
+    user_id bigint REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL DEFAULT '',
+    session_data jsonb NOT NULL,
+    expires_at timestamptz NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_passkey_ceremonies_expires_at ON passkey_ceremonies (expires_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS passkey_ceremonies;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- publish_at only means something while a post is scheduled
+UPDATE posts SET publish_at = NULL WHERE status <> 'scheduled' AND publish_at IS NOT NULL;
+
+ALTER TABLE posts
+    ADD CONSTRAINT posts_publish_at_scheduled_check CHECK (status = 'scheduled' OR publish_at IS NULL);
+
+-- +goose Down
+ALTER TABLE posts DROP CONSTRAINT IF EXISTS posts_publish_at_scheduled_check;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- History was backfilled with the current version of every post, credited to
+-- its author. Who saved a later version was not recorded, so those are left
+-- without an editor. A first version is always saved by the author.
+UPDATE post_revisions pr
+SET editor_id = NULL
+WHERE pr.version > 1 AND NOT EXISTS (
+    SELECT 1 FROM post_revisions earlier
+    WHERE earlier.post_id = pr.post_id AND earlier.version < pr.version
+);
+
+-- +goose Down
+-- The cleared editors were never recorded, so there is nothing to restore
@@ -5,7 +5,9 @@ import (
 
 	"github.com/samuel032khoury/gopherfeed/internal/db"
 	"github.com/samuel032khoury/gopherfeed/internal/env"
+	"github.com/samuel032khoury/gopherfeed/internal/password"
 	"github.com/samuel032khoury/gopherfeed/internal/store"
+	"go.uber.org/zap"
 )
 
 func main() {
@@ -15,6 +17,11 @@ func main() {
 		log.Fatal(err)
 	}
 	defer conn.Close()
-	store := store.NewPostgresStorage(conn)
-	seed(store)
+	hasher, err := password.NewArgon2idHasher(password.DefaultParams)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Seeding never authenticates, so there is nothing for the store to log
+	store := store.NewPostgresStorage(conn, hasher, zap.NewNop().Sugar())
+	seed(store, hasher)
 }
@@ -5,14 +5,14 @@ import (
 	"log"
 	"strconv"
 
+	"github.com/samuel032khoury/gopherfeed/internal/password"
 	"github.com/samuel032khoury/gopherfeed/internal/store"
-	"github.com/samuel032khoury/gopherfeed/internal/utils"
 )
 
-func seed(store *store.Storage) {
+func seed(store *store.Storage, hasher password.Hasher) {
 	ctx := context.Background()
 
-	users := generateUsers(100)
+	users := generateUsers(100, hasher)
 	log.Println("Creating users...")
 	for _, user := range users {
 		if err := store.Users.Create(ctx, nil, user); err != nil {
@@ -41,9 +41,9 @@ func seed(store *store.Storage) {
 	log.Println("Database seeding completed successfully.")
 }
 
-func generateUsers(n int) []*store.User {
+func generateUsers(n int, hasher password.Hasher) []*store.User {
 	users := make([]*store.User, n)
-	hashedPassword, _ := utils.EncryptPassword("password")
+	hashedPassword, _ := hasher.Hash("password")
 	for i := range n {
 		users[i] = &store.User{
 			Username: "user" + strconv.Itoa(i+1),
@@ -64,6 +64,7 @@ func generatePosts(users []*store.User, n int) []*store.Post {
 			Content: "This is the content of post number " + strconv.Itoa(i),
 			UserID:  user.ID,
 			Tags:    []string{"tag1", "tag2"},
+			Status:  store.PostStatusPublished,
 		}
 	}
 	return posts
@@ -24,79 +24,80 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/auth/activate": {
-            "post": {
-                "description": "Activate a user account using the provided token",
-                "consumes": [
-                    "application/json"
-                ],
+        "/admin/audit-log": {
+            "get": {
+                "description": "List audit log entries, newest first, with optional filters",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Activate a user account",
+                "summary": "Search the audit log",
                 "parameters": [
                     {
-                        "description": "Activation token",
-                        "name": "token",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/main.tokenDTO"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Account activated successfully",
-                        "schema": {
-                            "$ref": "#/definitions/main.DataResponse-main_activateResponse"
-                        }
+                        "type": "integer",
+                        "example": 20,
+                        "description": "Number of items per page (1-100)",
+                        "name": "limit",
+                        "in": "query"
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/main.ErrorResponse"
-                        }
+                    {
+                        "type": "integer",
+                        "example": 0,
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
                     },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/main.ErrorResponse"
-                        }
-                    }
-                }
-            }
-        },
-        "/auth/login": {
-            "post": {
-                "description": "Authenticate a user and set authentication cookie",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "auth"
-                ],
-                "summary": "User login",
-                "parameters": [
                     {
-                        "description": "User login payload",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/main.loginPayload"
-                        }
+                        "type": "integer",
+                        "example": 2,
+                        "description": "Only actions by this user",
+                        "name": "actor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "post.delete",
+                        "description": "Only this action",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "post",
+                        "description": "Only targets of this type",
+                        "name": "target_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "17",
+                        "description": "Only this target",
+                        "name": "target_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2026-01-01T00:00:00Z",
+                        "description": "Only entries at or after this time",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2026-01-31T23:59:59Z",
+                        "description": "Only entries at or before this time",
+                        "name": "until",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Login successful, cookie set"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.DataResponse-array_store_AuditEntry"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -110,65 +111,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/main.ErrorResponse"
-                        }
-                    }
-                }
-            }
-        },
-        "/auth/logout": {
-            "post": {
-                "description": "Clear JWT cookie and log out the user",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "auth"
-                ],
-                "summary": "User logout",
-                "responses": {
-                    "200": {
-                        "description": "Logged out successfully"
-                    }
-                }
-            }
-        },
-        "/auth/register": {
-            "post": {
-                "description": "Create a new user account",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "auth"
-                ],
-                "summary": "Register a new user",
-                "parameters": [
-                    {
-                        "description": "User registration payload",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/main.registerPayload"
-                        }
-                    }
-                ],
-                "responses": {
-                    "201": {
-                        "description": "User registered successfully",
-                        "schema": {
-                            "$ref": "#/definitions/main.DataResponse-store_User"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/main.ErrorResponse"
                         }
@@ -182,79 +126,65 @@ const docTemplate = `{
                 }
             }
         },
-        "/feeds": {
+        "/admin/audit-log/export": {
             "get": {
-                "description": "Get posts feed with filtering and pagination",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Download every audit log entry matching the filters as JSON lines, oldest first. The limit and offset parameters are ignored.",
                 "produces": [
-                    "application/json"
+                    "application/x-ndjson"
                 ],
                 "tags": [
-                    "feeds"
+                    "admin"
                 ],
-                "summary": "Get user feed",
+                "summary": "Export the audit log",
                 "parameters": [
                     {
                         "type": "integer",
-                        "example": 20,
-                        "description": "Number of items per page (1-100)",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "example": 0,
-                        "description": "Number of items to skip",
-                        "name": "offset",
+                        "example": 2,
+                        "description": "Only actions by this user",
+                        "name": "actor_id",
                         "in": "query"
                     },
                     {
-                        "enum": [
-                            "asc",
-                            "desc"
-                        ],
                         "type": "string",
-                        "example": "desc",
-                        "description": "Sort order",
-                        "name": "sort",
+                        "example": "post.delete",
+                        "description": "Only this action",
+                        "name": "action",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "example": "\"golang,api\"",
-                        "description": "Comma-separated tags filter",
-                        "name": "tags",
+                        "example": "post",
+                        "description": "Only targets of this type",
+                        "name": "target_type",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "example": "\"golang\"",
-                        "description": "Search in title and content",
-                        "name": "search",
+                        "example": "17",
+                        "description": "Only this target",
+                        "name": "target_id",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "example": "\"2026-01-01T00:00:00Z\"",
-                        "description": "Posts since this date (RFC3339)",
+                        "example": "2026-01-01T00:00:00Z",
+                        "description": "Only entries at or after this time",
                         "name": "since",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "example": "\"2026-12-31T23:59:59Z\"",
-                        "description": "Posts until this date (RFC3339)",
+                        "example": "2026-01-31T23:59:59Z",
+                        "description": "Only entries at or before this time",
                         "name": "until",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "One entry per line",
                         "schema": {
-                            "$ref": "#/definitions/main.DataResponse-array_store_FeedablePost"
+                            "$ref": "#/definitions/store.AuditEntry"
                         }
                     },
                     "400": {
@@ -264,36 +194,16 @@ const docTemplate = `{
                         }
                     },
                     "401": {
-                        "description": "Unauthorized - login required",
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/main.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/main.ErrorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/health": {
-            "get": {
-                "description": "Check the health of the application",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "health"
-                ],
-                "summary": "Health check",
-                "responses": {
-                    "200": {
-                        "description": "Application is healthy",
-                        "schema": {
-                            "$ref": "#/definitions/main.DataResponse-main_healthResponse"
-                        }
                     },
                     "500": {
                         "description": "Internal Server Error",
@@ -304,45 +214,43 @@ const docTemplate = `{
                 }
             }
         },
-        "/posts": {
-            "post": {
-                "description": "Create a new post",
-                "consumes": [
-                    "application/json"
-                ],
+        "/admin/impersonations/{sessionID}": {
+            "delete": {
+                "description": "End an impersonation session started by the current admin before it expires",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "posts"
+                    "admin"
                 ],
-                "summary": "Create a post",
+                "summary": "Stop an impersonation",
                 "parameters": [
                     {
-                        "description": "Post payload",
-                        "name": "post",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/main.PostDTO"
-                        }
+                        "type": "string",
+                        "description": "Impersonation session ID",
+                        "name": "sessionID",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Post created successfully",
+                    "204": {
+                        "description": "Impersonation ended"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/main.DataResponse-store_Post"
+                            "$ref": "#/definitions/main.ErrorResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/main.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized - login required",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/main.ErrorResponse"
                         }
@@ -356,43 +264,31 @@ const docTemplate = `{
                 }
             }
         },
-        "/posts/{postID}": {
+        "/admin/roles": {
             "get": {
-                "description": "Get a post by its unique ID with comments",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "List every role with its permissions",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "posts"
-                ],
-                "summary": "Get a post",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Post ID",
-                        "name": "postID",
-                        "in": "path",
-                        "required": true
-                    }
+                    "admin"
                 ],
+                "summary": "List roles",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/main.DataResponse-store_Post"
+                            "$ref": "#/definitions/main.DataResponse-array_store_Role"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/main.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/main.ErrorResponse"
                         }
@@ -405,8 +301,8 @@ const docTemplate = `{
                     }
                 }
             },
-            "put": {
-                "description": "Update a post by its unique ID",
+            "post": {
+                "description": "Create a custom role. Its level must be below your own and it can only hold permissions you hold.",
                 "consumes": [
                     "application/json"
                 ],
@@ -414,30 +310,26 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "posts"
+                    "admin"
                 ],
-                "summary": "Update a post",
+                "summary": "Create a role",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Post ID",
-                        "name": "postID",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Post payload",
-                        "name": "post",
+                        "description": "Role payload",
+                        "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/main.PostDTO"
+                            "$ref": "#/definitions/main.rolePayload"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Post updated successfully"
+                    "201": {
+                        "description": "Role created",
+                        "schema": {
+                            "$ref": "#/definitions/main.DataResponse-store_Role"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -446,19 +338,13 @@ const docTemplate = `{
                         }
                     },
                     "401": {
-                        "description": "Unauthorized - login required",
-                        "schema": {
-                            "$ref": "#/definitions/main.ErrorResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "Not Found",
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/main.ErrorResponse"
                         }
                     },
-                    "409": {
-                        "description": "Edit conflict",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/main.ErrorResponse"
                         }
@@ -470,9 +356,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete a post by its unique ID",
+            }
+        },
+        "/admin/roles/{roleID}": {
+            "put": {
+                "description": "Replace a role's name, level, description and permissions. Only roles below your own level can be edited, only to a level below your own, and only with permissions you hold. Permission changes apply at once on every instance with the Redis cache enabled, and otherwise take up to 30 seconds to reach other instances.",
                 "consumes": [
                     "application/json"
                 ],
@@ -480,21 +368,33 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "posts"
+                    "admin"
                 ],
-                "summary": "Delete a post",
+                "summary": "Update a role",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Post ID",
-                        "name": "postID",
+                        "description": "Role ID",
+                        "name": "roleID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Role payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.rolePayload"
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "Post deleted successfully"
+                    "200": {
+                        "description": "Role updated",
+                        "schema": {
+                            "$ref": "#/definitions/main.DataResponse-store_Role"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -503,7 +403,13 @@ const docTemplate = `{
                         }
                     },
                     "401": {
-                        "description": "Unauthorized - login required",
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/main.ErrorResponse"
                         }
@@ -523,42 +429,47 @@ const docTemplate = `{
                 }
             }
         },
-        "/posts/{postID}/comments": {
-            "post": {
-                "description": "Create a new comment on a post",
-                "consumes": [
-                    "application/json"
-                ],
+        "/admin/trash/comments": {
+            "get": {
+                "description": "List the comments in the trash, most recently deleted first by default. Comments are purged once the retention period runs out.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "comments"
+                    "admin"
                 ],
-                "summary": "Create a comment",
+                "summary": "List deleted comments",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Post ID",
-                        "name": "postID",
-                        "in": "path",
-                        "required": true
+                        "example": 20,
+                        "description": "Number of items per page (1-100)",
+                        "name": "limit",
+                        "in": "query"
                     },
                     {
-                        "description": "Comment payload",
-                        "name": "comment",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/main.CommentDTO"
-                        }
+                        "type": "integer",
+                        "example": 0,
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Sort order by deletion time",
+                        "name": "sort",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/main.DataResponse-store_Comment"
+                            "$ref": "#/definitions/main.DataResponse-array_store_Comment"
                         }
                     },
                     "400": {
@@ -568,13 +479,13 @@ const docTemplate = `{
                         }
                     },
                     "401": {
-                        "description": "Unauthorized - login required",
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/main.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/main.ErrorResponse"
                         }
@@ -588,34 +499,28 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{userID}": {
-            "get": {
-                "description": "Get a user by their unique ID",
-                "consumes": [
-                    "application/json"
-                ],
+        "/admin/trash/comments/{commentID}/restore": {
+            "post": {
+                "description": "Take a comment out of the trash. It stays hidden while its post is in the trash.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "admin"
                 ],
-                "summary": "Get user profile",
+                "summary": "Restore a deleted comment",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
-                        "name": "userID",
+                        "description": "Comment ID",
+                        "name": "commentID",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/main.DataResponse-store_User"
-                        }
+                    "204": {
+                        "description": "Comment restored"
                     },
                     "400": {
                         "description": "Bad Request",
@@ -623,8 +528,20 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
                     "404": {
-                        "description": "Not Found",
+                        "description": "Comment is not in the trash",
                         "schema": {
                             "$ref": "#/definitions/main.ErrorResponse"
                         }
@@ -638,31 +555,48 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{userID}/follow": {
-            "put": {
-                "description": "Follow a user by their unique ID",
-                "consumes": [
-                    "application/json"
-                ],
+        "/admin/trash/posts": {
+            "get": {
+                "description": "List the posts in the trash, most recently deleted first by default. Posts are purged once the retention period runs out.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "admin"
                 ],
-                "summary": "Follow a user",
+                "summary": "List deleted posts",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
-                        "name": "userID",
-                        "in": "path",
-                        "required": true
+                        "example": 20,
+                        "description": "Number of items per page (1-100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 0,
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Sort order by deletion time",
+                        "name": "sort",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "User followed successfully"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.DataResponse-array_store_Post"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -671,13 +605,13 @@ const docTemplate = `{
                         }
                     },
                     "401": {
-                        "description": "Unauthorized - login required",
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/main.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "User not found",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/main.ErrorResponse"
                         }
@@ -691,31 +625,28 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{userID}/unfollow": {
-            "put": {
-                "description": "Unfollow a user by their unique ID",
-                "consumes": [
-                    "application/json"
-                ],
+        "/admin/trash/posts/{postID}/restore": {
+            "post": {
+                "description": "Take a post out of the trash, along with its comments",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "admin"
                 ],
-                "summary": "Unfollow a user",
+                "summary": "Restore a deleted post",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
-                        "name": "userID",
+                        "description": "Post ID",
+                        "name": "postID",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "User unfollowed successfully"
+                    "204": {
+                        "description": "Post restored"
                     },
                     "400": {
                         "description": "Bad Request",
@@ -724,13 +655,19 @@ const docTemplate = `{
                         }
                     },
                     "401": {
-                        "description": "Unauthorized - login required",
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/main.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "User not found",
+                        "description": "Post is not in the trash",
                         "schema": {
                             "$ref": "#/definitions/main.ErrorResponse"
                         }
@@ -743,198 +680,4309 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "main.CommentDTO": {
-            "description": "Comment creation payload",
-            "type": "object",
-            "required": [
-                "content"
-            ],
-            "properties": {
-                "content": {
-                    "type": "string",
-                    "maxLength": 1000,
-                    "example": "Great post!"
-                }
-            }
-        },
-        "main.DataResponse-array_store_FeedablePost": {
-            "type": "object",
-            "properties": {
-                "data": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/store.FeedablePost"
-                    }
-                }
-            }
-        },
-        "main.DataResponse-main_activateResponse": {
-            "type": "object",
-            "properties": {
-                "data": {
-                    "$ref": "#/definitions/main.activateResponse"
-                }
-            }
-        },
-        "main.DataResponse-main_healthResponse": {
-            "type": "object",
-            "properties": {
-                "data": {
-                    "$ref": "#/definitions/main.healthResponse"
-                }
-            }
         },
-        "main.DataResponse-store_Comment": {
-            "type": "object",
-            "properties": {
-                "data": {
-                    "$ref": "#/definitions/store.Comment"
-                }
+        "/admin/users": {
+            "get": {
+                "description": "List users, including inactive ones, with optional filters",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List users",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 20,
+                        "description": "Number of items per page (1-100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 0,
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "moderator",
+                        "description": "Only users with this role",
+                        "name": "role",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "example": true,
+                        "description": "Only users who can or cannot sign in",
+                        "name": "active",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "john",
+                        "description": "Search in username and email",
+                        "name": "search",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.DataResponse-array_store_User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{userID}/activate": {
+            "post": {
+                "description": "Lift a deactivation. An account whose invitation was never accepted is activated as well.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Activate a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Account activated"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{userID}/deactivate": {
+            "post": {
+                "description": "Deactivate an account and sign it out everywhere. Only an administrator can lift it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Deactivate a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Account deactivated"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{userID}/impersonate": {
+            "post": {
+                "description": "Start a short-lived session acting as the given user. Only users with a lower role can be impersonated. Responses to the returned token carry an X-Impersonated-By header, and sensitive account actions are refused.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Impersonate a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Impersonation payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.impersonatePayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Impersonation started",
+                        "schema": {
+                            "$ref": "#/definitions/main.DataResponse-main_impersonationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{userID}/role": {
+            "put": {
+                "description": "Change a user's role. Only users and roles less privileged than your own can be managed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Assign a role",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Role to assign",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.assignRolePayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Role assigned"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{userID}/suspension": {
+            "post": {
+                "description": "Suspend a user, replacing any suspension they are under. A read_only suspension blocks writes, a full suspension blocks sign in and signs the user out everywhere. The user is notified by email.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Suspend a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Suspension payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.suspendPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "User suspended",
+                        "schema": {
+                            "$ref": "#/definitions/main.DataResponse-store_Suspension"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "End a user's suspension before it expires. The user is notified by email.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Lift a suspension",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Suspension lifted"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User is not suspended",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{userID}/unlock": {
+            "post": {
+                "description": "Clear failed login and MFA attempts so a locked out user can sign in again",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Unlock a user account",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Account unlocked"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/activate": {
+            "post": {
+                "description": "Activate a user account using the provided token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Activate a user account",
+                "parameters": [
+                    {
+                        "description": "Activation token",
+                        "name": "token",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.tokenDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Account activated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/main.DataResponse-main_activateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/activate/resend": {
+            "post": {
+                "description": "Rotate the activation token of an inactive account and email a new link. The response is the same whether or not the email is registered.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Resend activation email",
+                "parameters": [
+                    {
+                        "description": "Account email",
+                        "name": "email",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.emailDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Activation email queued if the account is pending",
+                        "schema": {
+                            "$ref": "#/definitions/main.DataResponse-main_messageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/csrf": {
+            "get": {
+                "description": "Return the CSRF token, issuing a new csrf_token cookie if needed. Browser clients using cookie authentication must send it in the X-CSRF-Token header on every POST, PUT and DELETE request.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Get a CSRF token",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.DataResponse-main_csrfTokenResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/email/confirm": {
+            "post": {
+                "description": "Consume an email change token and switch the account to the new address",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Confirm an email change",
+                "parameters": [
+                    {
+                        "description": "Email change token",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.tokenDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Email address changed",
+                        "schema": {
+                            "$ref": "#/definitions/main.DataResponse-store_User"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid token, or email already in use",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "Authenticate a user and set authentication cookie, or return a pending challenge when two-factor authentication is needed. Repeated failures are delayed progressively and eventually lock the account temporarily.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "User login",
+                "parameters": [
+                    {
+                        "description": "User login payload",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.loginPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Login successful, cookie set"
+                    },
+                    "202": {
+                        "description": "Second factor required",
+                        "schema": {
+                            "$ref": "#/definitions/main.DataResponse-main_mfaChallengeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed attempts, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "description": "Revoke the current session and clear the authentication cookies",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "User logout",
+                "responses": {
+                    "200": {
+                        "description": "Logged out successfully"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/magic-link": {
+            "post": {
+                "description": "Email a single-use login link. The response is the same whether or not the account exists.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Request a login link",
+                "parameters": [
+                    {
+                        "description": "Account email",
+                        "name": "email",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.emailDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Login link queued if the account exists",
+                        "schema": {
+                            "$ref": "#/definitions/main.DataResponse-main_messageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many links requested for this address, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/magic-link/redeem": {
+            "post": {
+                "description": "Consume a login link token and set authentication cookie, or return a pending challenge when two-factor authentication is needed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Sign in with a login link",
+                "parameters": [
+                    {
+                        "description": "Login link token",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.magicLinkPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Login successful, cookie set"
+                    },
+                    "202": {
+                        "description": "Second factor required",
+                        "schema": {
+                            "$ref": "#/definitions/main.DataResponse-main_mfaChallengeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/mfa/recovery-codes": {
+            "post": {
+                "description": "Invalidate all recovery codes and issue a new set",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Regenerate recovery codes",
+                "parameters": [
+                    {
+                        "description": "TOTP code",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.mfaCodePayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "New recovery codes",
+                        "schema": {
+                            "$ref": "#/definitions/main.DataResponse-main_recoveryCodesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/mfa/totp": {
+            "post": {
+                "description": "Generate a TOTP secret for the current user. Users whose role requires MFA can enroll during login by passing the pending challenge header.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Start TOTP enrollment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Pending MFA challenge",
+                        "name": "X-MFA-Challenge",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Pending enrollment created",
+                        "schema": {
+                            "$ref": "#/definitions/main.DataResponse-main_totpEnrollmentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Turn off two-factor authentication for the current user",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Disable TOTP",
+                "parameters": [
+                    {
+                        "description": "TOTP code",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.mfaCodePayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Two-factor authentication disabled"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/mfa/totp/confirm": {
+            "post": {
+                "description": "Activate the pending TOTP secret with a valid code and receive recovery codes. When enrolling with a pending challenge, this also completes the login.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Confirm TOTP enrollment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Pending MFA challenge",
+                        "name": "X-MFA-Challenge",
+                        "in": "header"
+                    },
+                    {
+                        "description": "TOTP code",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.mfaCodePayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Two-factor authentication enabled",
+                        "schema": {
+                            "$ref": "#/definitions/main.DataResponse-main_recoveryCodesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/mfa/verify": {
+            "post": {
+                "description": "Exchange a pending MFA challenge and a TOTP or recovery code for a session",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Complete a two-factor login",
+                "parameters": [
+                    {
+                        "description": "Challenge and code",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.mfaVerifyPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Login successful, cookie set"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed attempts, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/oidc/{provider}/callback": {
+            "post": {
+                "description": "Exchange the authorization code for the provider's identity and sign in the linked user. Unknown identities are linked to the user with the same verified email, or get a new active account.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Complete an external login",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Identity provider name",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Code and state from the provider redirect",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.oidcCallbackPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Login successful, cookie set"
+                    },
+                    "202": {
+                        "description": "Second factor required",
+                        "schema": {
+                            "$ref": "#/definitions/main.DataResponse-main_mfaChallengeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/oidc/{provider}/login": {
+            "get": {
+                "description": "Redirect to the identity provider. The flow state is kept in a short-lived cookie that the callback endpoint checks.",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Start an external login",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Identity provider name",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "302": {
+                        "description": "Redirect to the identity provider"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/passkeys": {
+            "get": {
+                "description": "List the passkeys registered by the current user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "List passkeys",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.DataResponse-array_store_Passkey"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/passkeys/login/begin": {
+            "post": {
+                "description": "Return the options for navigator.credentials.get. Any passkey registered with this site can answer.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Start passkey login",
+                "responses": {
+                    "200": {
+                        "description": "PublicKeyCredentialRequestOptions",
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/passkeys/login/finish": {
+            "post": {
+                "description": "Verify the assertion returned by navigator.credentials.get and set authentication cookie",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Finish passkey login",
+                "parameters": [
+                    {
+                        "description": "PublicKeyCredential from navigator.credentials.get",
+                        "name": "credential",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Login successful, cookie set"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/passkeys/register/begin": {
+            "post": {
+                "description": "Return the options for navigator.credentials.create. The ceremony state is kept server-side, referenced by a short-lived cookie.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Start passkey registration",
+                "parameters": [
+                    {
+                        "description": "Passkey name",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.passkeyRegistrationPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "PublicKeyCredentialCreationOptions",
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/passkeys/register/finish": {
+            "post": {
+                "description": "Verify the attestation returned by navigator.credentials.create and store the passkey",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Finish passkey registration",
+                "parameters": [
+                    {
+                        "description": "PublicKeyCredential from navigator.credentials.create",
+                        "name": "credential",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Passkey registered",
+                        "schema": {
+                            "$ref": "#/definitions/main.DataResponse-store_Passkey"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/passkeys/{passkeyID}": {
+            "delete": {
+                "description": "Remove one of the current user's passkeys",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Delete a passkey",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Passkey ID",
+                        "name": "passkeyID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Passkey deleted"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/password/forgot": {
+            "post": {
+                "description": "Email a single-use password reset link. The response is the same whether or not the email is registered, and requests are limited per address.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Request a password reset",
+                "parameters": [
+                    {
+                        "description": "Account email",
+                        "name": "email",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.emailDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Reset email queued if the account exists",
+                        "schema": {
+                            "$ref": "#/definitions/main.DataResponse-main_messageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests for this address, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/password/reset": {
+            "post": {
+                "description": "Set a new password using a reset token and revoke all existing sessions",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Reset password",
+                "parameters": [
+                    {
+                        "description": "Reset token and new password",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.resetPasswordPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Password reset successfully",
+                        "schema": {
+                            "$ref": "#/definitions/main.DataResponse-main_messageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid token, or password rejected by the password policy",
+                        "schema": {
+                            "$ref": "#/definitions/main.FieldErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/refresh": {
+            "post": {
+                "description": "Rotate the refresh token cookie and issue a new access token cookie",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Refresh access token",
+                "responses": {
+                    "200": {
+                        "description": "Tokens refreshed, cookies set"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/register": {
+            "post": {
+                "description": "Create a new user account",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Register a new user",
+                "parameters": [
+                    {
+                        "description": "User registration payload",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.registerPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "User registered successfully",
+                        "schema": {
+                            "$ref": "#/definitions/main.DataResponse-store_User"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid payload, or password rejected by the password policy",
+                        "schema": {
+                            "$ref": "#/definitions/main.FieldErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/feeds": {
+            "get": {
+                "description": "Get posts feed with filtering and pagination",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "feeds"
+                ],
+                "summary": "Get user feed",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 20,
+                        "description": "Number of items per page (1-100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 0,
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "example": "desc",
+                        "description": "Sort order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"golang,api\"",
+                        "description": "Comma-separated tags filter",
+                        "name": "tags",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"golang\"",
+                        "description": "Search in title and content",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"2026-01-01T00:00:00Z\"",
+                        "description": "Posts since this date (RFC3339)",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"2026-12-31T23:59:59Z\"",
+                        "description": "Posts until this date (RFC3339)",
+                        "name": "until",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.DataResponse-array_store_FeedablePost"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - login required",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Check the health of the application",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Health check",
+                "responses": {
+                    "200": {
+                        "description": "Application is healthy",
+                        "schema": {
+                            "$ref": "#/definitions/main.DataResponse-main_healthResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts": {
+            "post": {
+                "description": "Create a new post. It is published right away unless it is saved as a draft or scheduled for later.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "posts"
+                ],
+                "summary": "Create a post",
+                "parameters": [
+                    {
+                        "description": "Post payload",
+                        "name": "post",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.PostDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Post created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/main.DataResponse-store_Post"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - login required",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/{postID}": {
+            "get": {
+                "description": "Get a post by its unique ID with comments. The ETag follows the post's version and the renderer version of its HTML, so new comments do not change it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "posts"
+                ],
+                "summary": "Get a post",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Post ID",
+                        "name": "postID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a copy the client already has",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.DataResponse-store_Post"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Current version of the post"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "The client's copy is current"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update a post by its unique ID. Posts by other users require the post.update.any permission.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "posts"
+                ],
+                "summary": "Update a post",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Post ID",
+                        "name": "postID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only update the post if it is still at this ETag",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Post payload",
+                        "name": "post",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.PostDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Post updated successfully",
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New version of the post"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - login required",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Edit conflict",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "The post has changed",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Move a post to the trash by its unique ID. It can be restored until the retention period runs out. Posts by other users require the post.delete.any permission.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "posts"
+                ],
+                "summary": "Delete a post",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Post ID",
+                        "name": "postID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only delete the post if it is still at this ETag",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Post deleted successfully"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - login required",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "The post has changed",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Update some fields of a post with a JSON Merge Patch (RFC 7396) sent as application/merge-patch+json. Fields left out keep their value and fields set to null are cleared. The If-Match header is required. Posts by other users require the post.update.any permission.",
+                "consumes": [
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "posts"
+                ],
+                "summary": "Partially update a post",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Post ID",
+                        "name": "postID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only update the post if it is still at this ETag",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to change",
+                        "name": "patch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.PostDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Post updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/main.DataResponse-store_Post"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New version of the post"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - login required",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Edit conflict",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "The post has changed",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Body is not a merge patch",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "Missing If-Match header",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/{postID}/comments": {
+            "post": {
+                "description": "Create a new comment on a post",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Create a comment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Post ID",
+                        "name": "postID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Comment payload",
+                        "name": "comment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.CommentDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.DataResponse-store_Comment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - login required",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/{postID}/comments/{commentID}": {
+            "delete": {
+                "description": "Move a comment to the trash. It can be restored until the retention period runs out. Comments by other users require the comment.delete.any permission.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Delete a comment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Post ID",
+                        "name": "postID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Comment ID",
+                        "name": "commentID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Comment deleted successfully"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - login required",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/{postID}/revisions": {
+            "get": {
+                "description": "List the saved versions of a post, newest first by default. Only the author and users with the post.update.any permission can see a post's history.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "posts"
+                ],
+                "summary": "List post revisions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Post ID",
+                        "name": "postID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "example": 20,
+                        "description": "Number of items per page (1-100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 0,
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Sort order by version",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.DataResponse-array_store_PostRevision"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/{postID}/revisions/diff": {
+            "get": {
+                "description": "Show what changed between two versions of a post. The content is compared line by line.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "posts"
+                ],
+                "summary": "Compare post revisions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Post ID",
+                        "name": "postID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "example": 1,
+                        "description": "Older version",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "example": 3,
+                        "description": "Newer version, the current one by default",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.DataResponse-main_revisionDiff"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/{postID}/revisions/{version}": {
+            "get": {
+                "description": "Get a post as it was saved at one version",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "posts"
+                ],
+                "summary": "Get a post revision",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Post ID",
+                        "name": "postID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Version",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.DataResponse-store_PostRevision"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/{postID}/revisions/{version}/restore": {
+            "post": {
+                "description": "Save an earlier version's title, content and tags as the newest version of the post, like an update that changes only those. Only the author can restore.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "posts"
+                ],
+                "summary": "Restore a post revision",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Post ID",
+                        "name": "postID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Version to restore",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only restore if the post is still at this ETag",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Revision restored",
+                        "schema": {
+                            "$ref": "#/definitions/main.DataResponse-store_Post"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New version of the post"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Edit conflict",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "The post has changed",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/drafts": {
+            "get": {
+                "description": "List the current user's drafts and scheduled posts, most recently edited first by default",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "posts"
+                ],
+                "summary": "List your drafts",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 20,
+                        "description": "Number of items per page (1-100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 0,
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Sort order by last edit",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.DataResponse-array_store_Post"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/email": {
+            "put": {
+                "description": "Send a confirmation link to the new address and a notice to the current one. The address only changes once the link is confirmed. Wrong current passwords count towards the login lockout.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Change email address",
+                "parameters": [
+                    {
+                        "description": "New email address and current password",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.changeEmailPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Confirmation link sent",
+                        "schema": {
+                            "$ref": "#/definitions/main.DataResponse-main_messageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid email",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Wrong current password",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Email already in use",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed attempts, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/password": {
+            "put": {
+                "description": "Replace the current user's password. Other sessions are logged out; the current one stays signed in. Wrong current passwords count towards the login lockout.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Change password",
+                "parameters": [
+                    {
+                        "description": "Current and new password",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.changePasswordPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Password changed"
+                    },
+                    "400": {
+                        "description": "New password rejected by the password policy",
+                        "schema": {
+                            "$ref": "#/definitions/main.FieldErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Wrong current password",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed attempts, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/sessions": {
+            "get": {
+                "description": "List the devices the current user is logged in on",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "List active sessions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.DataResponse-array_main_sessionResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Log out every session of the current user except the one making the request",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Revoke all other sessions",
+                "responses": {
+                    "204": {
+                        "description": "Other sessions revoked"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/sessions/{sessionID}": {
+            "delete": {
+                "description": "Log out one of the current user's sessions",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Revoke a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "sessionID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Session revoked"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/suspension": {
+            "get": {
+                "description": "Get the suspension the current user is under, if any",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get your suspension",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.DataResponse-main_suspensionNotice"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not suspended",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/suspension/appeal": {
+            "put": {
+                "description": "Explain why the current user's suspension should be lifted. A later appeal replaces the earlier one. Users under a full suspension cannot sign in and appeal by replying to the suspension email instead.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Appeal your suspension",
+                "parameters": [
+                    {
+                        "description": "Appeal payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.appealPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Appeal recorded"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not suspended",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/tokens": {
+            "get": {
+                "description": "List the current user's personal access tokens without their secrets",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tokens"
+                ],
+                "summary": "List personal access tokens",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.DataResponse-array_store_PersonalAccessToken"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - login required",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not available to personal access tokens",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a named, scoped, expiring token for API and script clients. Use it as ` + "`" + `Authorization: Bearer \u003ctoken\u003e` + "`" + `.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tokens"
+                ],
+                "summary": "Create a personal access token",
+                "parameters": [
+                    {
+                        "description": "Token payload",
+                        "name": "token",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.createTokenPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Token created",
+                        "schema": {
+                            "$ref": "#/definitions/main.DataResponse-main_createTokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - login required",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not available to personal access tokens",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/tokens/{tokenID}": {
+            "delete": {
+                "description": "Delete one of the current user's personal access tokens",
+                "tags": [
+                    "tokens"
+                ],
+                "summary": "Revoke a personal access token",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Token ID",
+                        "name": "tokenID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Token revoked"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - login required",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not available to personal access tokens",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{userID}": {
+            "get": {
+                "description": "Get a user by their unique ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get user profile",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.DataResponse-store_User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{userID}/follow": {
+            "put": {
+                "description": "Follow a user by their unique ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Follow a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User followed successfully"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - login required",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{userID}/unfollow": {
+            "put": {
+                "description": "Unfollow a user by their unique ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Unfollow a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User unfollowed successfully"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - login required",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "main.CommentDTO": {
+            "description": "Comment creation payload",
+            "type": "object",
+            "required": [
+                "content"
+            ],
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "maxLength": 1000,
+                    "example": "Great post!"
+                }
+            }
+        },
+        "main.DataResponse-array_main_sessionResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.sessionResponse"
+                    }
+                }
+            }
+        },
+        "main.DataResponse-array_store_AuditEntry": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.AuditEntry"
+                    }
+                }
+            }
+        },
+        "main.DataResponse-array_store_Comment": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.Comment"
+                    }
+                }
+            }
+        },
+        "main.DataResponse-array_store_FeedablePost": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.FeedablePost"
+                    }
+                }
+            }
+        },
+        "main.DataResponse-array_store_Passkey": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.Passkey"
+                    }
+                }
+            }
+        },
+        "main.DataResponse-array_store_PersonalAccessToken": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.PersonalAccessToken"
+                    }
+                }
+            }
+        },
+        "main.DataResponse-array_store_Post": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.Post"
+                    }
+                }
+            }
+        },
+        "main.DataResponse-array_store_PostRevision": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.PostRevision"
+                    }
+                }
+            }
+        },
+        "main.DataResponse-array_store_Role": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.Role"
+                    }
+                }
+            }
+        },
+        "main.DataResponse-array_store_User": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.User"
+                    }
+                }
+            }
+        },
+        "main.DataResponse-main_activateResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/main.activateResponse"
+                }
+            }
+        },
+        "main.DataResponse-main_createTokenResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/main.createTokenResponse"
+                }
+            }
+        },
+        "main.DataResponse-main_csrfTokenResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/main.csrfTokenResponse"
+                }
+            }
+        },
+        "main.DataResponse-main_healthResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/main.healthResponse"
+                }
+            }
+        },
+        "main.DataResponse-main_impersonationResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/main.impersonationResponse"
+                }
+            }
+        },
+        "main.DataResponse-main_messageResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/main.messageResponse"
+                }
+            }
+        },
+        "main.DataResponse-main_mfaChallengeResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/main.mfaChallengeResponse"
+                }
+            }
+        },
+        "main.DataResponse-main_recoveryCodesResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/main.recoveryCodesResponse"
+                }
+            }
+        },
+        "main.DataResponse-main_revisionDiff": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/main.revisionDiff"
+                }
+            }
+        },
+        "main.DataResponse-main_suspensionNotice": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/main.suspensionNotice"
+                }
+            }
+        },
+        "main.DataResponse-main_totpEnrollmentResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/main.totpEnrollmentResponse"
+                }
+            }
+        },
+        "main.DataResponse-store_Comment": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/store.Comment"
+                }
+            }
+        },
+        "main.DataResponse-store_Passkey": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/store.Passkey"
+                }
+            }
+        },
+        "main.DataResponse-store_Post": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/store.Post"
+                }
+            }
+        },
+        "main.DataResponse-store_PostRevision": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/store.PostRevision"
+                }
+            }
+        },
+        "main.DataResponse-store_Role": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/store.Role"
+                }
+            }
+        },
+        "main.DataResponse-store_Suspension": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/store.Suspension"
+                }
+            }
+        },
+        "main.DataResponse-store_User": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/store.User"
+                }
+            }
+        },
+        "main.ErrorResponse": {
+            "description": "Error response format",
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "Something went wrong"
+                }
+            }
+        },
+        "main.FieldErrorResponse": {
+            "description": "Field-level validation error response",
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "invalid password"
+                },
+                "fields": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "main.PostDTO": {
+            "description": "Post creation/update payload",
+            "type": "object",
+            "required": [
+                "content",
+                "title"
+            ],
+            "properties": {
+                "content": {
+                    "description": "Content is CommonMark. Posts are returned with it rendered as\nsanitized HTML in content_html.",
+                    "type": "string",
+                    "maxLength": 2000,
+                    "example": "This is the *content* of my post"
+                },
+                "publish_at": {
+                    "type": "string",
+                    "example": "2026-01-07T09:00:00Z"
+                },
+                "status": {
+                    "description": "Status defaults to published for new posts and is left unchanged by\nupdates that omit it",
+                    "type": "string",
+                    "enum": [
+                        "draft",
+                        "scheduled",
+                        "published"
+                    ],
+                    "example": "scheduled"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "golang",
+                        "api"
+                    ]
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "My First Post"
+                }
+            }
+        },
+        "main.activateResponse": {
+            "description": "Account activation response",
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Account activated successfully"
+                }
+            }
+        },
+        "main.appealPayload": {
+            "description": "Suspension appeal",
+            "type": "object",
+            "required": [
+                "note"
+            ],
+            "properties": {
+                "note": {
+                    "type": "string",
+                    "maxLength": 2000,
+                    "example": "Those links were to my own blog"
+                }
+            }
+        },
+        "main.assignRolePayload": {
+            "description": "Role assignment payload",
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "moderator"
+                }
+            }
+        },
+        "main.changeEmailPayload": {
+            "description": "Email change payload",
+            "type": "object",
+            "required": [
+                "current_password",
+                "email"
+            ],
+            "properties": {
+                "current_password": {
+                    "type": "string",
+                    "maxLength": 256,
+                    "example": "plum-Orbit-71-lantern"
+                },
+                "email": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "new@example.com"
+                }
+            }
+        },
+        "main.changePasswordPayload": {
+            "description": "Password change payload",
+            "type": "object",
+            "required": [
+                "current_password",
+                "new_password"
+            ],
+            "properties": {
+                "current_password": {
+                    "type": "string",
+                    "maxLength": 256,
+                    "example": "plum-Orbit-71-lantern"
+                },
+                "new_password": {
+                    "type": "string",
+                    "maxLength": 256,
+                    "minLength": 8,
+                    "example": "quiet-Harbor-38-violin"
+                }
+            }
+        },
+        "main.createTokenPayload": {
+            "description": "Personal access token creation payload",
+            "type": "object",
+            "required": [
+                "expires_in_days",
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "expires_in_days": {
+                    "type": "integer",
+                    "maximum": 365,
+                    "minimum": 1,
+                    "example": 90
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "ci-bot"
+                },
+                "scopes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "posts:read",
+                        "feed:read"
+                    ]
+                }
+            }
+        },
+        "main.createTokenResponse": {
+            "description": "Personal access token including the secret, which is only shown once",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2026-01-06T07:22:18Z"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2026-04-06T07:22:18Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "last_used_at": {
+                    "type": "string",
+                    "example": "2026-01-06T07:22:18Z"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "ci-bot"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "posts:read",
+                        "feed:read"
+                    ]
+                },
+                "token": {
+                    "type": "string",
+                    "example": "gfp_Zm9vYmFyYmF6cXV4..."
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "main.csrfTokenResponse": {
+            "description": "CSRF token for cookie-authenticated requests",
+            "type": "object",
+            "properties": {
+                "csrf_token": {
+                    "type": "string",
+                    "example": "3q2-7wAAAAC6KYtLyF8Q6w"
+                }
+            }
+        },
+        "main.emailDTO": {
+            "description": "Email payload",
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "user1@example.com"
+                }
             }
         },
-        "main.DataResponse-store_Post": {
+        "main.healthResponse": {
             "type": "object",
             "properties": {
-                "data": {
-                    "$ref": "#/definitions/store.Post"
+                "env": {
+                    "type": "string",
+                    "example": "development"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "OK"
+                },
+                "version": {
+                    "type": "string",
+                    "example": "1.0.0"
                 }
             }
         },
-        "main.DataResponse-store_User": {
+        "main.impersonatePayload": {
+            "description": "Impersonation request; the reason is kept in the audit log",
             "type": "object",
+            "required": [
+                "reason"
+            ],
             "properties": {
-                "data": {
-                    "$ref": "#/definitions/store.User"
+                "reason": {
+                    "type": "string",
+                    "maxLength": 500,
+                    "example": "Investigating support ticket #1234"
                 }
             }
         },
-        "main.ErrorResponse": {
-            "description": "Error response format",
+        "main.impersonationResponse": {
+            "description": "Impersonation access token, to be sent as a bearer token",
             "type": "object",
             "properties": {
-                "error": {
+                "expires_at": {
                     "type": "string",
-                    "example": "Something went wrong"
+                    "example": "2026-01-06T07:52:18Z"
+                },
+                "session_id": {
+                    "type": "string",
+                    "example": "7f9c2d4e-1b3a-4c5d-8e6f-0a1b2c3d4e5f"
+                },
+                "token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIs..."
                 }
             }
         },
-        "main.PostDTO": {
-            "description": "Post creation/update payload",
+        "main.loginPayload": {
+            "description": "Login payload",
             "type": "object",
             "required": [
-                "content",
-                "title"
+                "email",
+                "password"
             ],
             "properties": {
-                "content": {
+                "email": {
                     "type": "string",
-                    "maxLength": 2000,
-                    "example": "This is the content of my post"
+                    "example": "user1@example.com"
                 },
-                "tags": {
+                "password": {
+                    "type": "string",
+                    "maxLength": 256,
+                    "minLength": 8,
+                    "example": "password"
+                }
+            }
+        },
+        "main.magicLinkPayload": {
+            "description": "Magic link redemption payload",
+            "type": "object",
+            "required": [
+                "token"
+            ],
+            "properties": {
+                "token": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                }
+            }
+        },
+        "main.messageResponse": {
+            "description": "Message response",
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Request processed successfully"
+                }
+            }
+        },
+        "main.mfaChallengeResponse": {
+            "description": "Pending two-factor authentication challenge",
+            "type": "object",
+            "properties": {
+                "challenge": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                },
+                "enrollment_required": {
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "main.mfaCodePayload": {
+            "description": "TOTP code payload",
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                }
+            }
+        },
+        "main.mfaVerifyPayload": {
+            "description": "MFA verification payload, either a TOTP code or a recovery code",
+            "type": "object",
+            "required": [
+                "challenge"
+            ],
+            "properties": {
+                "challenge": {
+                    "type": "string"
+                },
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                },
+                "recovery_code": {
+                    "type": "string",
+                    "maxLength": 16,
+                    "example": "abcd-efgh"
+                }
+            }
+        },
+        "main.oidcCallbackPayload": {
+            "description": "Authorization code and state returned by the identity provider",
+            "type": "object",
+            "required": [
+                "code",
+                "state"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "4/0AX4XfWh..."
+                },
+                "state": {
+                    "type": "string",
+                    "example": "kQ3n1n1ZlW3y6m4n..."
+                }
+            }
+        },
+        "main.passkeyRegistrationPayload": {
+            "description": "Passkey registration payload",
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "MacBook Touch ID"
+                }
+            }
+        },
+        "main.recoveryCodesResponse": {
+            "description": "Single-use recovery codes, only shown once",
+            "type": "object",
+            "properties": {
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "abcd-efgh",
+                        "ijkl-mnop"
+                    ]
+                }
+            }
+        },
+        "main.registerPayload": {
+            "description": "Registration payload",
+            "type": "object",
+            "required": [
+                "email",
+                "password",
+                "username"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "newuser@example.com"
+                },
+                "password": {
+                    "type": "string",
+                    "maxLength": 256,
+                    "minLength": 8,
+                    "example": "plum-Orbit-71-lantern"
+                },
+                "username": {
+                    "type": "string",
+                    "maxLength": 30,
+                    "minLength": 3,
+                    "example": "newuser"
+                }
+            }
+        },
+        "main.resetPasswordPayload": {
+            "description": "Password reset payload",
+            "type": "object",
+            "required": [
+                "password",
+                "token"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "maxLength": 256,
+                    "minLength": 8,
+                    "example": "quiet-Harbor-38-violin"
+                },
+                "token": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                }
+            }
+        },
+        "main.revisionDiff": {
+            "description": "Changes between two versions of a post",
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/textdiff.Line"
+                    }
+                },
+                "from": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "tags_added": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "golang"
+                    ]
+                },
+                "tags_removed": {
                     "type": "array",
                     "items": {
                         "type": "string"
                     },
                     "example": [
-                        "golang",
                         "api"
                     ]
                 },
                 "title": {
+                    "$ref": "#/definitions/main.titleChange"
+                },
+                "to": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "main.rolePayload": {
+            "description": "Role payload; permissions replace the role's current ones",
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "description": {
                     "type": "string",
-                    "maxLength": 100,
-                    "example": "My First Post"
+                    "maxLength": 255,
+                    "example": "Support staff"
+                },
+                "level": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 3
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 50,
+                    "example": "support"
+                },
+                "permissions": {
+                    "type": "array",
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "comment.delete.any",
+                        "user.unlock"
+                    ]
                 }
             }
         },
-        "main.activateResponse": {
-            "description": "Account activation response",
+        "main.sessionResponse": {
+            "description": "Active login session",
             "type": "object",
             "properties": {
-                "message": {
+                "created_at": {
                     "type": "string",
-                    "example": "Account activated successfully"
+                    "example": "2026-01-06T07:22:18Z"
+                },
+                "current": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2026-01-13T07:22:18Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "0b6f1b9e-4c2a-4f51-9d1c-3c5e6f7a8b9c"
+                },
+                "impersonator_id": {
+                    "description": "ImpersonatorID is the admin acting as the user, if any",
+                    "type": "integer",
+                    "example": 2
+                },
+                "ip": {
+                    "type": "string",
+                    "example": "203.0.113.7"
+                },
+                "last_seen_at": {
+                    "type": "string",
+                    "example": "2026-01-06T09:41:02Z"
+                },
+                "user_agent": {
+                    "type": "string",
+                    "example": "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_2) AppleWebKit/605.1.15"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 1
                 }
             }
         },
-        "main.healthResponse": {
+        "main.suspendPayload": {
+            "description": "Suspension request. Without a duration the user is banned until the suspension is lifted.",
             "type": "object",
+            "required": [
+                "reason",
+                "scope"
+            ],
             "properties": {
-                "env": {
+                "duration": {
                     "type": "string",
-                    "example": "development"
+                    "maxLength": 20,
+                    "example": "168h"
                 },
-                "status": {
+                "reason": {
+                    "type": "string",
+                    "maxLength": 1000,
+                    "example": "Repeated spam in comments"
+                },
+                "scope": {
+                    "type": "string",
+                    "enum": [
+                        "read_only",
+                        "full"
+                    ],
+                    "example": "read_only"
+                }
+            }
+        },
+        "main.suspensionNotice": {
+            "description": "The current user's suspension",
+            "type": "object",
+            "properties": {
+                "appeal_note": {
+                    "type": "string",
+                    "example": "Those links were to my own blog"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2026-01-06T07:22:18Z"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2026-01-13T07:22:18Z"
+                },
+                "reason": {
                     "type": "string",
-                    "example": "OK"
+                    "example": "Repeated spam in comments"
                 },
-                "version": {
+                "scope": {
                     "type": "string",
-                    "example": "1.0.0"
+                    "enum": [
+                        "read_only",
+                        "full"
+                    ],
+                    "example": "read_only"
                 }
             }
         },
-        "main.loginPayload": {
-            "description": "Login payload",
+        "main.titleChange": {
             "type": "object",
-            "required": [
-                "email",
-                "password"
-            ],
             "properties": {
-                "email": {
+                "from": {
                     "type": "string",
-                    "example": "user1@example.com"
+                    "example": "My First Post"
                 },
-                "password": {
+                "to": {
                     "type": "string",
-                    "maxLength": 72,
-                    "minLength": 8,
-                    "example": "password"
+                    "example": "My First Go Post"
                 }
             }
         },
-        "main.registerPayload": {
-            "description": "Registration payload",
+        "main.tokenDTO": {
+            "description": "Token payload",
             "type": "object",
             "required": [
-                "email",
-                "password",
-                "username"
+                "token"
             ],
             "properties": {
-                "email": {
+                "token": {
                     "type": "string",
-                    "example": "newuser@example.com"
-                },
-                "password": {
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                }
+            }
+        },
+        "main.totpEnrollmentResponse": {
+            "description": "TOTP secret and provisioning URI for authenticator apps",
+            "type": "object",
+            "properties": {
+                "otpauth_uri": {
                     "type": "string",
-                    "maxLength": 72,
-                    "minLength": 8,
-                    "example": "password"
+                    "example": "otpauth://totp/GopherFeed:user1@example.com?secret=JBSWY3DPEHPK3PXP"
                 },
-                "username": {
+                "secret": {
                     "type": "string",
-                    "maxLength": 30,
-                    "minLength": 3,
-                    "example": "newuser"
+                    "example": "JBSWY3DPEHPK3PXP"
                 }
             }
         },
-        "main.tokenDTO": {
-            "description": "Token payload",
+        "store.AuditEntry": {
+            "description": "Audit log entry",
             "type": "object",
-            "required": [
-                "token"
-            ],
             "properties": {
-                "token": {
+                "action": {
                     "type": "string",
-                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                    "example": "post.delete"
+                },
+                "actor_id": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "after": {
+                    "type": "object"
+                },
+                "before": {
+                    "description": "Before and After are snapshots of the target around the action",
+                    "type": "object"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2026-01-06T07:22:18Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "ip": {
+                    "type": "string",
+                    "example": "203.0.113.7"
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "request_id": {
+                    "type": "string",
+                    "example": "host/abcdef-000001"
+                },
+                "target_id": {
+                    "type": "string",
+                    "example": "17"
+                },
+                "target_type": {
+                    "type": "string",
+                    "example": "post"
                 }
             }
         },
@@ -950,6 +4998,15 @@ const docTemplate = `{
                     "type": "string",
                     "example": "2026-01-06T07:22:18Z"
                 },
+                "deleted_at": {
+                    "description": "DeletedAt and DeletedBy are only set on comments in the trash",
+                    "type": "string",
+                    "example": "2026-01-08T10:00:00Z"
+                },
+                "deleted_by": {
+                    "type": "integer",
+                    "example": 3
+                },
                 "id": {
                     "type": "integer",
                     "example": 1
@@ -980,16 +5037,47 @@ const docTemplate = `{
                 },
                 "content": {
                     "type": "string",
-                    "example": "This is the content of my first post"
+                    "example": "This is the *content* of my first post"
+                },
+                "content_html": {
+                    "type": "string",
+                    "example": "\u003cp\u003eThis is the \u003cem\u003econtent\u003c/em\u003e of my first post\u003c/p\u003e"
                 },
                 "created_at": {
                     "type": "string",
                     "example": "2026-01-06T07:22:18Z"
                 },
+                "deleted_at": {
+                    "description": "DeletedAt and DeletedBy are only set on posts in the trash",
+                    "type": "string",
+                    "example": "2026-01-08T10:00:00Z"
+                },
+                "deleted_by": {
+                    "type": "integer",
+                    "example": 3
+                },
                 "id": {
                     "type": "integer",
                     "example": 1
                 },
+                "publish_at": {
+                    "description": "PublishAt is when a scheduled post goes live",
+                    "type": "string",
+                    "example": "2026-01-07T09:00:00Z"
+                },
+                "published_at": {
+                    "type": "string",
+                    "example": "2026-01-06T07:22:18Z"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "draft",
+                        "scheduled",
+                        "published"
+                    ],
+                    "example": "published"
+                },
                 "tags": {
                     "type": "array",
                     "items": {
@@ -1022,6 +5110,72 @@ const docTemplate = `{
                 }
             }
         },
+        "store.Passkey": {
+            "description": "Registered passkey",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2026-01-06T07:22:18Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "last_used_at": {
+                    "type": "string",
+                    "example": "2026-01-06T07:22:18Z"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "MacBook Touch ID"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "store.PersonalAccessToken": {
+            "description": "Personal access token metadata; the secret is only returned on creation",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2026-01-06T07:22:18Z"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2026-04-06T07:22:18Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "last_used_at": {
+                    "type": "string",
+                    "example": "2026-01-06T07:22:18Z"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "ci-bot"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "posts:read",
+                        "feed:read"
+                    ]
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
         "store.Post": {
             "description": "Blog post information",
             "type": "object",
@@ -1034,16 +5188,47 @@ const docTemplate = `{
                 },
                 "content": {
                     "type": "string",
-                    "example": "This is the content of my first post"
+                    "example": "This is the *content* of my first post"
+                },
+                "content_html": {
+                    "type": "string",
+                    "example": "\u003cp\u003eThis is the \u003cem\u003econtent\u003c/em\u003e of my first post\u003c/p\u003e"
                 },
                 "created_at": {
                     "type": "string",
                     "example": "2026-01-06T07:22:18Z"
                 },
+                "deleted_at": {
+                    "description": "DeletedAt and DeletedBy are only set on posts in the trash",
+                    "type": "string",
+                    "example": "2026-01-08T10:00:00Z"
+                },
+                "deleted_by": {
+                    "type": "integer",
+                    "example": 3
+                },
                 "id": {
                     "type": "integer",
                     "example": 1
                 },
+                "publish_at": {
+                    "description": "PublishAt is when a scheduled post goes live",
+                    "type": "string",
+                    "example": "2026-01-07T09:00:00Z"
+                },
+                "published_at": {
+                    "type": "string",
+                    "example": "2026-01-06T07:22:18Z"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "draft",
+                        "scheduled",
+                        "published"
+                    ],
+                    "example": "published"
+                },
                 "tags": {
                     "type": "array",
                     "items": {
@@ -1072,6 +5257,133 @@ const docTemplate = `{
                 }
             }
         },
+        "store.PostRevision": {
+            "description": "Saved version of a post",
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "example": "This is the content of my first post"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2026-01-06T07:22:18Z"
+                },
+                "editor_id": {
+                    "description": "EditorID is who saved the revision; nil when the scheduler published it\nor the revision was saved before history was kept",
+                    "type": "integer",
+                    "example": 1
+                },
+                "post_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "golang",
+                        "api"
+                    ]
+                },
+                "title": {
+                    "type": "string",
+                    "example": "My First Post"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "store.Role": {
+            "description": "Role with its granted permissions",
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Moderator with elevated privileges"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "level": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "name": {
+                    "type": "string",
+                    "example": "moderator"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "post.update.any",
+                        "comment.delete.any"
+                    ]
+                }
+            }
+        },
+        "store.Suspension": {
+            "description": "Account suspension issued by a moderator",
+            "type": "object",
+            "properties": {
+                "appeal_note": {
+                    "type": "string",
+                    "example": "Those links were to my own blog"
+                },
+                "appealed_at": {
+                    "type": "string",
+                    "example": "2026-01-07T10:12:45Z"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2026-01-06T07:22:18Z"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2026-01-13T07:22:18Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "issued_by": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "lifted_at": {
+                    "type": "string",
+                    "example": "2026-01-08T16:03:11Z"
+                },
+                "lifted_by": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "Repeated spam in comments"
+                },
+                "scope": {
+                    "type": "string",
+                    "enum": [
+                        "read_only",
+                        "full"
+                    ],
+                    "example": "read_only"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
         "store.User": {
             "description": "User account information",
             "type": "object",
@@ -1080,6 +5392,11 @@ const docTemplate = `{
                     "type": "string",
                     "example": "2026-01-06T07:22:18Z"
                 },
+                "deactivated_at": {
+                    "description": "DeactivatedAt is set while an administrator has deactivated the account",
+                    "type": "string",
+                    "example": "2026-01-08T10:00:00Z"
+                },
                 "email": {
                     "type": "string",
                     "example": "john@example.com"
@@ -1101,6 +5418,36 @@ const docTemplate = `{
                     "example": "john_doe"
                 }
             }
+        },
+        "textdiff.Line": {
+            "type": "object",
+            "properties": {
+                "op": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/textdiff.Op"
+                        }
+                    ],
+                    "example": "insert"
+                },
+                "text": {
+                    "type": "string",
+                    "example": "A line that was added"
+                }
+            }
+        },
+        "textdiff.Op": {
+            "type": "string",
+            "enum": [
+                "equal",
+                "insert",
+                "delete"
+            ],
+            "x-enum-varnames": [
+                "Equal",
+                "Insert",
+                "Delete"
+            ]
         }
     }
 }`
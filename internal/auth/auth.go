@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"context"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -11,3 +12,20 @@ type Authenticator interface {
 	ValidateToken(token string) (*jwt.Token, error)
 	GetMetadata() (exp time.Duration, iss string, aud string)
 }
+
+// IdentityProvider signs users in with an external account using the
+// authorization code flow with PKCE.
+type IdentityProvider interface {
+	Name() string
+	AuthCodeURL(state, nonce, codeVerifier string) string
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*ExternalIdentity, error)
+}
+
+// ExternalIdentity is the user as asserted by an identity provider.
+type ExternalIdentity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
@@ -0,0 +1,195 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWK is the public part of a signing key as published in a JWKS document.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKSProvider is implemented by authenticators whose tokens can be verified
+// by third parties with public keys.
+type JWKSProvider interface {
+	JWKS() JWKS
+}
+
+type signingKey struct {
+	kid     string
+	method  jwt.SigningMethod
+	private crypto.Signer
+	public  crypto.PublicKey
+}
+
+// KeySetAuthenticator signs tokens with RS256 or EdDSA keys identified by a
+// kid header. Only the active key signs; every other key in the set still
+// verifies, so rotating the active key does not invalidate issued tokens.
+type KeySetAuthenticator struct {
+	keys                map[string]*signingKey
+	activeKID           string
+	tokenExpiryDuration time.Duration
+	iss                 string
+	aud                 string
+}
+
+// NewKeySetAuthenticator loads every <kid>.pem file in keysDir. Private keys
+// (PKCS#8, or PKCS#1 for RSA) can sign and verify, public keys (PKIX) only
+// verify, which is how a retired key is kept until its last tokens expire.
+func NewKeySetAuthenticator(keysDir, activeKID, tokenExpiryString, iss, aud string) (*KeySetAuthenticator, error) {
+	tokenExpiryDuration, err := time.ParseDuration(tokenExpiryString)
+	if err != nil {
+		return nil, err
+	}
+	paths, err := filepath.Glob(filepath.Join(keysDir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string]*signingKey, len(paths))
+	for _, path := range paths {
+		kid := strings.TrimSuffix(filepath.Base(path), ".pem")
+		key, err := loadSigningKey(kid, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load signing key %q: %w", kid, err)
+		}
+		keys[kid] = key
+	}
+	active, ok := keys[activeKID]
+	if !ok {
+		return nil, fmt.Errorf("active signing key %q not found in %s", activeKID, keysDir)
+	}
+	if active.private == nil {
+		return nil, fmt.Errorf("active signing key %q has no private key", activeKID)
+	}
+	return &KeySetAuthenticator{
+		keys:                keys,
+		activeKID:           activeKID,
+		tokenExpiryDuration: tokenExpiryDuration,
+		iss:                 iss,
+		aud:                 aud,
+	}, nil
+}
+
+func (a *KeySetAuthenticator) GenerateToken(claims jwt.Claims) (string, error) {
+	key := a.keys[a.activeKID]
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.kid
+	return token.SignedString(key.private)
+}
+
+func (a *KeySetAuthenticator) ValidateToken(token string) (*jwt.Token, error) {
+	return jwt.Parse(token, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, ok := a.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		// The algorithm is bound to the key, never taken from the token alone
+		if t.Method.Alg() != key.method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method %v", t.Header["alg"])
+		}
+		return key.public, nil
+	},
+		jwt.WithExpirationRequired(),
+		jwt.WithAudience(a.aud),
+		jwt.WithIssuer(a.iss),
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}),
+	)
+}
+
+func (a *KeySetAuthenticator) GetMetadata() (exp time.Duration, iss string, aud string) {
+	return a.tokenExpiryDuration, a.iss, a.aud
+}
+
+func (a *KeySetAuthenticator) JWKS() JWKS {
+	kids := make([]string, 0, len(a.keys))
+	for kid := range a.keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	jwks := JWKS{Keys: make([]JWK, 0, len(kids))}
+	for _, kid := range kids {
+		key := a.keys[kid]
+		jwk := JWK{Kid: kid, Use: "sig", Alg: key.method.Alg()}
+		switch pub := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	return jwks
+}
+
+func loadSigningKey(kid, path string) (*signingKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found")
+	}
+
+	var parsed any
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &signingKey{kid: kid}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.method, key.private, key.public = jwt.SigningMethodRS256, k, &k.PublicKey
+	case ed25519.PrivateKey:
+		key.method, key.private, key.public = jwt.SigningMethodEdDSA, k, k.Public()
+	case *rsa.PublicKey:
+		key.method, key.public = jwt.SigningMethodRS256, k
+	case ed25519.PublicKey:
+		key.method, key.public = jwt.SigningMethodEdDSA, k
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+	return key, nil
+}
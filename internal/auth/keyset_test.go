@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func writeKey(t *testing.T, dir, kid, blockType string, der []byte) {
+	t.Helper()
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(filepath.Join(dir, kid+".pem"), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testClaims(iss, aud string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub": 1,
+		"exp": time.Now().Add(time.Hour).Unix(),
+		"iss": iss,
+		"aud": aud,
+	}
+}
+
+func TestKeySetAuthenticator(t *testing.T) {
+	dir := t.TempDir()
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(edKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeKey(t, dir, "2026-01", "PRIVATE KEY", der)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeKey(t, dir, "2026-02", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey))
+
+	oldAuth, err := NewKeySetAuthenticator(dir, "2026-01", "15m", "gopherfeed-api", "gopherfeed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldToken, err := oldAuth.GenerateToken(testClaims("gopherfeed-api", "gopherfeed"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should keep validating tokens signed by a rotated key", func(t *testing.T) {
+		newAuth, err := NewKeySetAuthenticator(dir, "2026-02", "15m", "gopherfeed-api", "gopherfeed")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := newAuth.ValidateToken(oldToken); err != nil {
+			t.Errorf("expected token from previous key to be valid: %v", err)
+		}
+		newToken, err := newAuth.GenerateToken(testClaims("gopherfeed-api", "gopherfeed"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		token, err := oldAuth.ValidateToken(newToken)
+		if err != nil {
+			t.Fatalf("expected token from new key to be valid: %v", err)
+		}
+		if token.Header["kid"] != "2026-02" || token.Method.Alg() != "RS256" {
+			t.Errorf("unexpected header %v", token.Header)
+		}
+	})
+
+	t.Run("should verify with public-only retired keys", func(t *testing.T) {
+		der, err := x509.MarshalPKIXPublicKey(edKey.Public())
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeKey(t, dir, "2026-01", "PUBLIC KEY", der)
+		retiredAuth, err := NewKeySetAuthenticator(dir, "2026-02", "15m", "gopherfeed-api", "gopherfeed")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := retiredAuth.ValidateToken(oldToken); err != nil {
+			t.Errorf("expected token from retired key to be valid: %v", err)
+		}
+		if _, err := NewKeySetAuthenticator(dir, "2026-01", "15m", "gopherfeed-api", "gopherfeed"); err == nil {
+			t.Error("expected a public-only key to be rejected as the active key")
+		}
+	})
+
+	t.Run("should publish every key in the JWKS", func(t *testing.T) {
+		jwks := oldAuth.JWKS()
+		if len(jwks.Keys) != 2 {
+			t.Fatalf("expected 2 keys; got %d", len(jwks.Keys))
+		}
+		if jwks.Keys[0].Kty != "OKP" || jwks.Keys[1].Kty != "RSA" {
+			t.Errorf("unexpected key types %s, %s", jwks.Keys[0].Kty, jwks.Keys[1].Kty)
+		}
+	})
+
+	t.Run("should reject tokens with an unknown kid", func(t *testing.T) {
+		hs := NewJWTAuthenticator("secret", "15m", "gopherfeed-api", "gopherfeed")
+		token, err := hs.GenerateToken(testClaims("gopherfeed-api", "gopherfeed"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := oldAuth.ValidateToken(token); err == nil {
+			t.Error("expected HS256 token to be rejected")
+		}
+	})
+}
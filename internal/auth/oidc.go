@@ -0,0 +1,307 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type OIDCConfig struct {
+	Name         string
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// OIDCProvider is an IdentityProvider for any OpenID Connect compliant
+// server, configured through its discovery document.
+type OIDCProvider struct {
+	config                OIDCConfig
+	client                *http.Client
+	issuer                string
+	authorizationEndpoint string
+	tokenEndpoint         string
+	jwksURI               string
+
+	mu   sync.RWMutex
+	keys map[string]crypto.PublicKey
+}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// NewOIDCProvider fetches the provider's discovery document from
+// <IssuerURL>/.well-known/openid-configuration.
+func NewOIDCProvider(ctx context.Context, config OIDCConfig) (*OIDCProvider, error) {
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"openid", "email", "profile"}
+	}
+	p := &OIDCProvider{
+		config: config,
+		client: &http.Client{Timeout: 10 * time.Second},
+		keys:   make(map[string]crypto.PublicKey),
+	}
+	discovery := &oidcDiscovery{}
+	wellKnown := strings.TrimSuffix(config.IssuerURL, "/") + "/.well-known/openid-configuration"
+	if err := p.getJSON(ctx, wellKnown, discovery); err != nil {
+		return nil, fmt.Errorf("failed to fetch discovery document: %w", err)
+	}
+	if strings.TrimSuffix(discovery.Issuer, "/") != strings.TrimSuffix(config.IssuerURL, "/") {
+		return nil, fmt.Errorf("discovery issuer %q does not match %q", discovery.Issuer, config.IssuerURL)
+	}
+	p.issuer = discovery.Issuer
+	p.authorizationEndpoint = discovery.AuthorizationEndpoint
+	p.tokenEndpoint = discovery.TokenEndpoint
+	p.jwksURI = discovery.JWKSURI
+	return p, nil
+}
+
+func (p *OIDCProvider) Name() string {
+	return p.config.Name
+}
+
+func (p *OIDCProvider) AuthCodeURL(state, nonce, codeVerifier string) string {
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.config.ClientID},
+		"redirect_uri":          {p.config.RedirectURL},
+		"scope":                 {strings.Join(p.config.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {CodeChallenge(codeVerifier)},
+		"code_challenge_method": {"S256"},
+	}
+	sep := "?"
+	if strings.Contains(p.authorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return p.authorizationEndpoint + sep + params.Encode()
+}
+
+// Exchange redeems the authorization code and verifies the returned ID token,
+// including that it was issued for the given nonce.
+func (p *OIDCProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*ExternalIdentity, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"code_verifier": {codeVerifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.tokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token endpoint returned %s", resp.Status)
+	}
+	var tokens struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+		return nil, err
+	}
+	if tokens.IDToken == "" {
+		return nil, fmt.Errorf("token response has no id_token")
+	}
+	return p.verifyIDToken(ctx, tokens.IDToken, nonce)
+}
+
+func (p *OIDCProvider) verifyIDToken(ctx context.Context, idToken, nonce string) (*ExternalIdentity, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(idToken, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, err := p.publicKey(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+		if !keyMatchesMethod(key, t.Method) {
+			return nil, fmt.Errorf("unexpected signing method %v", t.Header["alg"])
+		}
+		return key, nil
+	},
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuer(p.issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithValidMethods([]string{
+			jwt.SigningMethodRS256.Alg(),
+			jwt.SigningMethodES256.Alg(),
+			jwt.SigningMethodEdDSA.Alg(),
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id token: %w", err)
+	}
+	if claimNonce, _ := claims["nonce"].(string); claimNonce == "" || claimNonce != nonce {
+		return nil, fmt.Errorf("id token nonce mismatch")
+	}
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return nil, fmt.Errorf("id token has no subject")
+	}
+	identity := &ExternalIdentity{
+		Provider: p.config.Name,
+		Subject:  subject,
+	}
+	identity.Email, _ = claims["email"].(string)
+	identity.Name, _ = claims["name"].(string)
+	// Some providers send email_verified as a string
+	switch v := claims["email_verified"].(type) {
+	case bool:
+		identity.EmailVerified = v
+	case string:
+		identity.EmailVerified = v == "true"
+	}
+	return identity, nil
+}
+
+// publicKey returns the provider key with the given kid, refetching the JWKS
+// once if it is unknown since the provider may have rotated its keys.
+func (p *OIDCProvider) publicKey(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	p.mu.RLock()
+	key, ok := p.keys[kid]
+	p.mu.RUnlock()
+	if ok {
+		return key, nil
+	}
+
+	jwks := &JWKS{}
+	if err := p.getJSON(ctx, p.jwksURI, jwks); err != nil {
+		return nil, fmt.Errorf("failed to fetch provider keys: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		if pub, err := jwk.PublicKey(); err == nil {
+			keys[jwk.Kid] = pub
+		}
+	}
+	p.mu.Lock()
+	p.keys = keys
+	p.mu.Unlock()
+
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+func (p *OIDCProvider) getJSON(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// PublicKey decodes an RSA, P-256 or Ed25519 JWK.
+func (k JWK) PublicKey() (crypto.PublicKey, error) {
+	decode := base64.RawURLEncoding.DecodeString
+	switch k.Kty {
+	case "RSA":
+		n, err := decode(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 key size")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+func keyMatchesMethod(key crypto.PublicKey, method jwt.SigningMethod) bool {
+	switch key.(type) {
+	case *rsa.PublicKey:
+		return method.Alg() == jwt.SigningMethodRS256.Alg()
+	case *ecdsa.PublicKey:
+		return method.Alg() == jwt.SigningMethodES256.Alg()
+	case ed25519.PublicKey:
+		return method.Alg() == jwt.SigningMethodEdDSA.Alg()
+	}
+	return false
+}
+
+// GenerateOAuthSecret returns a random value suitable for state, nonce and
+// PKCE code verifiers.
+func GenerateOAuthSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// CodeChallenge derives the S256 PKCE challenge of a code verifier.
+func CodeChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,141 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// stubOIDCServer is a minimal OpenID provider issuing RS256 ID tokens for a
+// single authorization code.
+type stubOIDCServer struct {
+	*httptest.Server
+	key           *rsa.PrivateKey
+	code          string
+	codeChallenge string
+	nonce         string
+}
+
+func newStubOIDCServer(t *testing.T) *stubOIDCServer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &stubOIDCServer{key: key, code: "test-code"}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(oidcDiscovery{
+			Issuer:                s.URL,
+			AuthorizationEndpoint: s.URL + "/authorize",
+			TokenEndpoint:         s.URL + "/token",
+			JWKSURI:               s.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(JWKS{Keys: []JWK{{
+			Kty: "RSA",
+			Kid: "stub",
+			Use: "sig",
+			Alg: "RS256",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		clientID, secret, _ := r.BasicAuth()
+		if clientID != "client" || secret != "secret" {
+			http.Error(w, "invalid client", http.StatusUnauthorized)
+			return
+		}
+		if r.PostFormValue("code") != s.code || CodeChallenge(r.PostFormValue("code_verifier")) != s.codeChallenge {
+			http.Error(w, "invalid grant", http.StatusBadRequest)
+			return
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"iss":            s.URL,
+			"aud":            "client",
+			"sub":            "stub-user-1",
+			"email":          "user@example.com",
+			"email_verified": true,
+			"nonce":          s.nonce,
+			"exp":            time.Now().Add(time.Minute).Unix(),
+			"iat":            time.Now().Unix(),
+		})
+		token.Header["kid"] = "stub"
+		idToken, err := token.SignedString(key)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id_token": idToken})
+	})
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// authorize plays the user approving the request at the provider.
+func (s *stubOIDCServer) authorize(t *testing.T, authURL string) {
+	t.Helper()
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if q.Get("code_challenge_method") != "S256" {
+		t.Fatalf("expected S256 code challenge; got %q", q.Get("code_challenge_method"))
+	}
+	s.codeChallenge = q.Get("code_challenge")
+	s.nonce = q.Get("nonce")
+}
+
+func TestOIDCProvider(t *testing.T) {
+	server := newStubOIDCServer(t)
+	ctx := context.Background()
+	provider, err := NewOIDCProvider(ctx, OIDCConfig{
+		Name:         "stub",
+		IssuerURL:    server.URL,
+		ClientID:     "client",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost:5173/oauth/callback",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should exchange a code for a verified identity", func(t *testing.T) {
+		server.authorize(t, provider.AuthCodeURL("state", "nonce", "verifier"))
+		identity, err := provider.Exchange(ctx, "test-code", "verifier", "nonce")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if identity.Subject != "stub-user-1" || identity.Email != "user@example.com" || !identity.EmailVerified {
+			t.Errorf("unexpected identity %+v", identity)
+		}
+	})
+
+	t.Run("should reject a wrong code verifier", func(t *testing.T) {
+		server.authorize(t, provider.AuthCodeURL("state", "nonce", "verifier"))
+		if _, err := provider.Exchange(ctx, "test-code", "other-verifier", "nonce"); err == nil {
+			t.Error("expected exchange with a wrong code verifier to fail")
+		}
+	})
+
+	t.Run("should reject a mismatched nonce", func(t *testing.T) {
+		server.authorize(t, provider.AuthCodeURL("state", "nonce", "verifier"))
+		if _, err := provider.Exchange(ctx, "test-code", "verifier", "other-nonce"); err == nil {
+			t.Error("expected exchange with a mismatched nonce to fail")
+		}
+	})
+}
@@ -0,0 +1,18 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+)
+
+// PersonalAccessTokenPrefix marks opaque API tokens so they can be told apart
+// from JWTs in the Authorization header and picked up by secret scanners.
+const PersonalAccessTokenPrefix = "gfp_"
+
+func GeneratePersonalAccessToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return PersonalAccessTokenPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
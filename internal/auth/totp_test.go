@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateTOTP(t *testing.T) {
+	// RFC 6238 appendix B test secret "12345678901234567890"
+	secret := b32.EncodeToString([]byte("12345678901234567890"))
+
+	t.Run("should match the RFC 6238 reference values", func(t *testing.T) {
+		cases := map[int64]string{
+			59:         "287082",
+			1111111109: "081804",
+			1234567890: "005924",
+			2000000000: "279037",
+		}
+		for unix, code := range cases {
+			if _, ok := ValidateTOTP(secret, code, time.Unix(unix, 0)); !ok {
+				t.Errorf("expected code %s to be valid at %d", code, unix)
+			}
+		}
+	})
+
+	t.Run("should tolerate one step of clock drift", func(t *testing.T) {
+		if _, ok := ValidateTOTP(secret, "287082", time.Unix(59+30, 0)); !ok {
+			t.Error("expected code from previous step to be valid")
+		}
+		if _, ok := ValidateTOTP(secret, "287082", time.Unix(59+90, 0)); ok {
+			t.Error("expected code from three steps ago to be rejected")
+		}
+	})
+
+	t.Run("should reject malformed codes", func(t *testing.T) {
+		if _, ok := ValidateTOTP(secret, "28708", time.Unix(59, 0)); ok {
+			t.Error("expected short code to be rejected")
+		}
+	})
+}
@@ -12,9 +12,17 @@ import (
 )
 
 const (
-	FromName           = "GopherFeed"
-	maxRetries         = 3
-	UserInviteTemplate = "user_invitation.gtpl"
+	FromName              = "GopherFeed"
+	maxRetries            = 3
+	UserInviteTemplate    = "user_invitation.gtpl"
+	PasswordResetTemplate = "password_reset.gtpl"
+	AccountLockedTemplate = "account_locked.gtpl"
+	MagicLinkTemplate     = "magic_link.gtpl"
+	NewDeviceTemplate     = "new_device_login.gtpl"
+	EmailChangeTemplate   = "email_change_confirm.gtpl"
+	EmailNoticeTemplate   = "email_change_notice.gtpl"
+	SuspendedTemplate     = "account_suspended.gtpl"
+	ReinstatedTemplate    = "account_reinstated.gtpl"
 )
 
 //go:embed "templates"
@@ -0,0 +1,75 @@
+package lockout
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Policy describes how failed attempts on a key are throttled: the first
+// few failures are free, later ones are delayed exponentially and reaching
+// LockAfter locks the key out entirely.
+type Policy struct {
+	FreeAttempts int
+	BaseDelay    time.Duration
+	MaxDelay     time.Duration
+	LockAfter    int
+	LockDuration time.Duration
+	// Window is how long failures are remembered after the last one.
+	Window time.Duration
+}
+
+// Status is the state of a key after a failed attempt.
+type Status struct {
+	Failures int
+	Wait     time.Duration
+	Locked   bool
+}
+
+type Tracker interface {
+	// Check returns how long the key has to wait before its next attempt.
+	Check(ctx context.Context, key string) (time.Duration, error)
+	// Fail records a failed attempt. A Locked status is returned once per
+	// lockout, when the failure that triggered it is recorded.
+	Fail(ctx context.Context, key string) (Status, error)
+	Reset(ctx context.Context, key string) error
+}
+
+func NewPolicy(freeAttempts int, baseDelay, maxDelay string, lockAfter int, lockDuration, window string) (Policy, error) {
+	durations := make([]time.Duration, 4)
+	for i, s := range []string{baseDelay, maxDelay, lockDuration, window} {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return Policy{}, err
+		}
+		durations[i] = d
+	}
+	if durations[3] < durations[2] {
+		return Policy{}, fmt.Errorf("failure window %s is shorter than lock duration %s", window, lockDuration)
+	}
+	return Policy{
+		FreeAttempts: freeAttempts,
+		BaseDelay:    durations[0],
+		MaxDelay:     durations[1],
+		LockAfter:    lockAfter,
+		LockDuration: durations[2],
+		Window:       durations[3],
+	}, nil
+}
+
+// Wait returns the delay imposed after the given number of failures and
+// whether it is a lockout.
+func (p Policy) Wait(failures int) (time.Duration, bool) {
+	if p.LockAfter > 0 && failures >= p.LockAfter {
+		return p.LockDuration, true
+	}
+	if failures <= p.FreeAttempts {
+		return 0, false
+	}
+	exp := min(failures-p.FreeAttempts-1, 30)
+	delay := p.BaseDelay << exp
+	if delay > p.MaxDelay || delay <= 0 {
+		delay = p.MaxDelay
+	}
+	return delay, false
+}
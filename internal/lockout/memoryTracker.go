@@ -0,0 +1,92 @@
+package lockout
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type entry struct {
+	failures     int
+	lastFailure  time.Time
+	blockedUntil time.Time
+}
+
+// MemoryTracker keeps failed attempts in process memory. It is only suitable
+// for a single API instance; use RedisTracker when running several.
+type MemoryTracker struct {
+	sync.Mutex
+	policy  Policy
+	entries map[string]*entry
+}
+
+func NewMemoryTracker(policy Policy) *MemoryTracker {
+	return &MemoryTracker{
+		policy:  policy,
+		entries: make(map[string]*entry),
+	}
+}
+
+func (t *MemoryTracker) Check(ctx context.Context, key string) (time.Duration, error) {
+	t.Lock()
+	defer t.Unlock()
+	now := time.Now()
+	e := t.get(key, now)
+	if e == nil || !now.Before(e.blockedUntil) {
+		return 0, nil
+	}
+	return e.blockedUntil.Sub(now), nil
+}
+
+func (t *MemoryTracker) Fail(ctx context.Context, key string) (Status, error) {
+	t.Lock()
+	defer t.Unlock()
+	now := time.Now()
+	e := t.get(key, now)
+	if e == nil {
+		e = &entry{}
+		t.entries[key] = e
+		time.AfterFunc(t.policy.Window, func() { t.expire(key) })
+	}
+	e.failures++
+	e.lastFailure = now
+	wait, locked := t.policy.Wait(e.failures)
+	e.blockedUntil = now.Add(wait)
+	return Status{Failures: e.failures, Wait: wait, Locked: locked}, nil
+}
+
+func (t *MemoryTracker) Reset(ctx context.Context, key string) error {
+	t.Lock()
+	defer t.Unlock()
+	delete(t.entries, key)
+	return nil
+}
+
+// get returns the entry for key, dropping it if its window has passed.
+func (t *MemoryTracker) get(key string, now time.Time) *entry {
+	e, ok := t.entries[key]
+	if !ok {
+		return nil
+	}
+	if now.Sub(e.lastFailure) > t.policy.Window {
+		delete(t.entries, key)
+		return nil
+	}
+	return e
+}
+
+// expire removes stale entries so the map does not grow without bound,
+// checking again later for keys that kept failing.
+func (t *MemoryTracker) expire(key string) {
+	t.Lock()
+	defer t.Unlock()
+	e, ok := t.entries[key]
+	if !ok {
+		return
+	}
+	if remaining := t.policy.Window - time.Since(e.lastFailure); remaining > 0 {
+		time.AfterFunc(remaining, func() { t.expire(key) })
+		return
+	}
+	delete(t.entries, key)
+}
@@ -0,0 +1,58 @@
+package lockout
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryTracker(t *testing.T) {
+	policy := Policy{
+		FreeAttempts: 2,
+		BaseDelay:    time.Second,
+		MaxDelay:     4 * time.Second,
+		LockAfter:    6,
+		LockDuration: time.Hour,
+		Window:       2 * time.Hour,
+	}
+	ctx := context.Background()
+
+	t.Run("should delay progressively and then lock", func(t *testing.T) {
+		tracker := NewMemoryTracker(policy)
+		expected := []time.Duration{0, 0, time.Second, 2 * time.Second, 4 * time.Second, time.Hour}
+		for i, want := range expected {
+			status, err := tracker.Fail(ctx, "user@example.com")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status.Wait != want {
+				t.Errorf("failure %d: expected wait %s; got %s", i+1, want, status.Wait)
+			}
+			if status.Locked != (i == len(expected)-1) {
+				t.Errorf("failure %d: unexpected locked %v", i+1, status.Locked)
+			}
+		}
+		wait, err := tracker.Check(ctx, "user@example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if wait <= 59*time.Minute {
+			t.Errorf("expected key to be locked for about an hour; got %s", wait)
+		}
+	})
+
+	t.Run("should unlock on reset", func(t *testing.T) {
+		tracker := NewMemoryTracker(policy)
+		for range policy.LockAfter {
+			if _, err := tracker.Fail(ctx, "user@example.com"); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := tracker.Reset(ctx, "user@example.com"); err != nil {
+			t.Fatal(err)
+		}
+		if wait, _ := tracker.Check(ctx, "user@example.com"); wait != 0 {
+			t.Errorf("expected no wait after reset; got %s", wait)
+		}
+	})
+}
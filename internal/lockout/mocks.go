@@ -0,0 +1,24 @@
+package lockout
+
+import (
+	"context"
+	"time"
+)
+
+type MockTracker struct{}
+
+func NewMockTracker() Tracker {
+	return &MockTracker{}
+}
+
+func (m *MockTracker) Check(ctx context.Context, key string) (time.Duration, error) {
+	return 0, nil
+}
+
+func (m *MockTracker) Fail(ctx context.Context, key string) (Status, error) {
+	return Status{Failures: 1}, nil
+}
+
+func (m *MockTracker) Reset(ctx context.Context, key string) error {
+	return nil
+}
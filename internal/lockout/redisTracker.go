@@ -0,0 +1,67 @@
+package lockout
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// RedisTracker shares failed attempts between API instances. Failure
+// counters and blocks are plain keys expiring on their own.
+type RedisTracker struct {
+	client *redis.Client
+	prefix string
+	policy Policy
+}
+
+func NewRedisTracker(client *redis.Client, prefix string, policy Policy) *RedisTracker {
+	return &RedisTracker{
+		client: client,
+		prefix: prefix,
+		policy: policy,
+	}
+}
+
+func (t *RedisTracker) Check(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := t.client.PTTL(ctx, t.blockKey(key)).Result()
+	if err != nil {
+		return 0, err
+	}
+	if ttl <= 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+func (t *RedisTracker) Fail(ctx context.Context, key string) (Status, error) {
+	var incr *redis.IntCmd
+	_, err := t.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, t.failuresKey(key))
+		pipe.PExpire(ctx, t.failuresKey(key), t.policy.Window)
+		return nil
+	})
+	if err != nil {
+		return Status{}, err
+	}
+	failures := int(incr.Val())
+	wait, locked := t.policy.Wait(failures)
+	if wait > 0 {
+		if err := t.client.Set(ctx, t.blockKey(key), failures, wait).Err(); err != nil {
+			return Status{}, err
+		}
+	}
+	return Status{Failures: failures, Wait: wait, Locked: locked}, nil
+}
+
+func (t *RedisTracker) Reset(ctx context.Context, key string) error {
+	return t.client.Del(ctx, t.failuresKey(key), t.blockKey(key)).Err()
+}
+
+func (t *RedisTracker) failuresKey(key string) string {
+	return t.prefix + ":failures:" + key
+}
+
+func (t *RedisTracker) blockKey(key string) string {
+	return t.prefix + ":block:" + key
+}
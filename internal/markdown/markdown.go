@@ -0,0 +1,52 @@
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+)
+
+// Version identifies the output of Render. Bump it whenever the renderer or
+// the sanitizer policy changes, so content rendered by an older version is
+// rendered again.
+const Version = 1
+
+var (
+	renderer = goldmark.New()
+	policy   = newPolicy()
+)
+
+// Render converts CommonMark source to HTML that is safe to embed in a page.
+// Raw HTML in the source is dropped and only an allow-list of tags and
+// attributes survives sanitization.
+func Render(source string) (string, error) {
+	var buf bytes.Buffer
+	if err := renderer.Convert([]byte(source), &buf); err != nil {
+		return "", err
+	}
+	return policy.Sanitize(buf.String()), nil
+}
+
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.NewPolicy()
+	p.AllowElements(
+		"p", "br", "hr", "h1", "h2", "h3", "h4", "h5", "h6",
+		"strong", "em", "code", "pre", "blockquote", "ul", "ol", "li",
+	)
+	p.AllowAttrs("start").Matching(bluemonday.Integer).OnElements("ol")
+	// Fenced code blocks name their language for client-side highlighting
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w-]+$`)).OnElements("code")
+
+	p.AllowAttrs("href").OnElements("a")
+	p.AllowAttrs("src", "alt").OnElements("img")
+	p.AllowAttrs("title").OnElements("a", "img")
+	p.AllowURLSchemes("http", "https", "mailto")
+	p.AllowRelativeURLs(true)
+	p.RequireParseableURLs(true)
+	p.RequireNoFollowOnLinks(true)
+	p.RequireNoReferrerOnLinks(true)
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+	return p
+}
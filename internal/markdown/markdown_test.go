@@ -0,0 +1,68 @@
+package markdown
+
+import "testing"
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{
+			name:   "paragraph",
+			source: "Hello *world*",
+			want:   "<p>Hello <em>world</em></p>\n",
+		},
+		{
+			name:   "fenced code",
+			source: "```go\nfmt.Println(1)\n```",
+			want:   "<pre><code class=\"language-go\">fmt.Println(1)\n</code></pre>\n",
+		},
+		{
+			name:   "ordered list",
+			source: "3. three\n4. four",
+			want:   "<ol start=\"3\">\n<li>three</li>\n<li>four</li>\n</ol>\n",
+		},
+		{
+			name:   "raw html",
+			source: "<script>alert(1)</script>\n\n<b onclick=\"x()\">bold</b>",
+			want:   "\n<p>bold</p>\n",
+		},
+		{
+			name:   "external link",
+			source: "[site](https://example.com \"Example\")",
+			want:   "<p><a href=\"https://example.com\" title=\"Example\" rel=\"nofollow noreferrer noopener\" target=\"_blank\">site</a></p>\n",
+		},
+		{
+			name:   "relative link",
+			source: "[post](/posts/2)",
+			want:   "<p><a href=\"/posts/2\" rel=\"nofollow noreferrer\">post</a></p>\n",
+		},
+		{
+			name:   "image",
+			source: "![cat](https://example.com/cat.png)",
+			want:   "<p><img src=\"https://example.com/cat.png\" alt=\"cat\"></p>\n",
+		},
+		{
+			name:   "table is not CommonMark",
+			source: "| a |\n|---|\n| b |",
+			want:   "<p>| a |\n|---|\n| b |</p>\n",
+		},
+		{
+			name:   "script link",
+			source: "[click](javascript:alert(1))",
+			want:   "<p>click</p>\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(tt.source)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Render(%q) = %q; want %q", tt.source, got, tt.want)
+			}
+		})
+	}
+}
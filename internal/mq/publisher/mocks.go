@@ -0,0 +1,34 @@
+package publisher
+
+import "sync"
+
+// MockMessage is an email recorded by MockPublisher.
+type MockMessage struct {
+	To       string
+	Template string
+	Data     any
+}
+
+// MockPublisher records published emails instead of queueing them.
+type MockPublisher struct {
+	mu   sync.Mutex
+	sent []MockMessage
+}
+
+func NewMockPublisher() *MockPublisher {
+	return &MockPublisher{}
+}
+
+func (m *MockPublisher) Publish(to string, templatePath string, data any) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, MockMessage{To: to, Template: templatePath, Data: data})
+	return nil
+}
+
+// Sent returns the emails published so far.
+func (m *MockPublisher) Sent() []MockMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]MockMessage(nil), m.sent...)
+}
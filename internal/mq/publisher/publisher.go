@@ -0,0 +1,6 @@
+package publisher
+
+// Publisher queues an email rendered from templatePath with data for to.
+type Publisher interface {
+	Publish(to string, templatePath string, data any) error
+}
@@ -0,0 +1,80 @@
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const hashPrefixLength = 5
+
+// BreachedList is a local corpus of breached password SHA-1 hashes. Like the
+// Pwned Passwords range API, hashes are bucketed by their first five hex
+// characters so a lookup only ever compares suffixes within one bucket.
+type BreachedList struct {
+	ranges map[string]map[string]struct{}
+	size   int
+}
+
+// LoadBreachedList reads a file with one uppercase or lowercase SHA-1 hex
+// hash per line, optionally followed by ":<count>" as in the Pwned Passwords
+// downloads. Blank lines and lines starting with # are skipped.
+func LoadBreachedList(path string) (*BreachedList, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadBreachedList(f)
+}
+
+func ReadBreachedList(r io.Reader) (*BreachedList, error) {
+	list := &BreachedList{ranges: make(map[string]map[string]struct{})}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		hash, _, _ := strings.Cut(text, ":")
+		hash = strings.ToUpper(hash)
+		if _, err := hex.DecodeString(hash); err != nil || len(hash) != 2*sha1.Size {
+			return nil, fmt.Errorf("line %d: invalid SHA-1 hash", line)
+		}
+		list.add(hash)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Contains reports whether password is in the corpus.
+func (l *BreachedList) Contains(password string) bool {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	_, ok := l.ranges[hash[:hashPrefixLength]][hash[hashPrefixLength:]]
+	return ok
+}
+
+// Len returns the number of hashes in the corpus.
+func (l *BreachedList) Len() int {
+	return l.size
+}
+
+func (l *BreachedList) add(hash string) {
+	prefix, suffix := hash[:hashPrefixLength], hash[hashPrefixLength:]
+	bucket, ok := l.ranges[prefix]
+	if !ok {
+		bucket = make(map[string]struct{})
+		l.ranges[prefix] = bucket
+	}
+	if _, ok := bucket[suffix]; !ok {
+		bucket[suffix] = struct{}{}
+		l.size++
+	}
+}
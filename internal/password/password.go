@@ -0,0 +1,149 @@
+// Package password hashes and verifies user passwords. Hashes are stored in
+// a self-describing format, so the scheme and its parameters can change
+// without invalidating existing hashes.
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var ErrMalformedHash = errors.New("malformed password hash")
+
+// Hasher creates and checks password hashes.
+type Hasher interface {
+	// Hash returns an encoded hash of password using the current scheme.
+	Hash(password string) (string, error)
+	// Verify reports whether password matches hash, and whether hash should
+	// be replaced because it uses an outdated scheme or parameters.
+	Verify(password, hash string) (match bool, needsRehash bool, err error)
+}
+
+// Params are the argon2id cost parameters.
+type Params struct {
+	Memory      uint32 // KiB
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// validCost reports whether the cost parameters are within what argon2id
+// allows.
+func (p Params) validCost() bool {
+	return p.Parallelism >= 1 && p.Iterations >= 1 && p.Memory >= 8*uint32(p.Parallelism)
+}
+
+// DefaultParams follow the OWASP recommendation for argon2id.
+var DefaultParams = Params{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 2,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// Argon2idHasher hashes new passwords with argon2id in the PHC string format,
+//
+//	$argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>
+//
+// and still verifies legacy bcrypt hashes, flagging them for rehashing.
+type Argon2idHasher struct {
+	params Params
+}
+
+func NewArgon2idHasher(params Params) (*Argon2idHasher, error) {
+	if !params.validCost() {
+		return nil, fmt.Errorf("invalid argon2id parameters %+v", params)
+	}
+	if params.SaltLength < 16 || params.KeyLength < 16 {
+		return nil, fmt.Errorf("argon2id salt and key must be at least 16 bytes")
+	}
+	return &Argon2idHasher{params: params}, nil
+}
+
+func (h *Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.params.Iterations, h.params.Memory, h.params.Parallelism, h.params.KeyLength)
+	return fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		h.params.Memory,
+		h.params.Iterations,
+		h.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (h *Argon2idHasher) Verify(password, hash string) (bool, bool, error) {
+	switch {
+	case hash == "":
+		// Accounts created through an identity provider have no password
+		return false, false, nil
+	case strings.HasPrefix(hash, "$argon2id$"):
+		params, salt, key, err := decodeArgon2id(hash)
+		if err != nil {
+			return false, false, err
+		}
+		other := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+		if subtle.ConstantTimeCompare(key, other) != 1 {
+			return false, false, nil
+		}
+		return true, params != h.params, nil
+	case strings.HasPrefix(hash, "$2a$"), strings.HasPrefix(hash, "$2b$"), strings.HasPrefix(hash, "$2y$"):
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+		switch err {
+		case nil:
+			return true, true, nil
+		case bcrypt.ErrMismatchedHashAndPassword, bcrypt.ErrPasswordTooLong:
+			return false, false, nil
+		default:
+			return false, false, err
+		}
+	default:
+		return false, false, ErrMalformedHash
+	}
+}
+
+func decodeArgon2id(hash string) (Params, []byte, []byte, error) {
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return Params{}, nil, nil, ErrMalformedHash
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return Params{}, nil, nil, ErrMalformedHash
+	}
+	var params Params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return Params{}, nil, nil, ErrMalformedHash
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return Params{}, nil, nil, ErrMalformedHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return Params{}, nil, nil, ErrMalformedHash
+	}
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+	// Degenerate parameters would make any password match, e.g. an empty
+	// key, or make Verify panic
+	if !params.validCost() || len(salt) < 8 || len(key) < 16 {
+		return Params{}, nil, nil, ErrMalformedHash
+	}
+	return params, salt, key, nil
+}
@@ -0,0 +1,97 @@
+package password
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testParams keep the tests fast; production uses DefaultParams
+var testParams = Params{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func TestArgon2idHasher(t *testing.T) {
+	hasher, err := NewArgon2idHasher(testParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should verify its own hashes", func(t *testing.T) {
+		// Well past bcrypt's 72 byte limit
+		password := strings.Repeat("correct horse battery staple ", 5)
+		hash, err := hasher.Hash(password)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$") {
+			t.Fatalf("unexpected hash format %q", hash)
+		}
+		match, rehash, err := hasher.Verify(password, hash)
+		if err != nil || !match || rehash {
+			t.Errorf("expected match without rehash; got match=%v rehash=%v err=%v", match, rehash, err)
+		}
+		if match, _, _ := hasher.Verify(password[:72], hash); match {
+			t.Error("expected a truncated password not to match")
+		}
+	})
+
+	t.Run("should flag hashes with outdated parameters", func(t *testing.T) {
+		hash, err := hasher.Hash("password")
+		if err != nil {
+			t.Fatal(err)
+		}
+		stronger := testParams
+		stronger.Iterations = 2
+		upgraded, err := NewArgon2idHasher(stronger)
+		if err != nil {
+			t.Fatal(err)
+		}
+		match, rehash, err := upgraded.Verify("password", hash)
+		if err != nil || !match || !rehash {
+			t.Errorf("expected match with rehash; got match=%v rehash=%v err=%v", match, rehash, err)
+		}
+	})
+
+	t.Run("should verify and flag legacy bcrypt hashes", func(t *testing.T) {
+		legacy, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+		if err != nil {
+			t.Fatal(err)
+		}
+		match, rehash, err := hasher.Verify("password", string(legacy))
+		if err != nil || !match || !rehash {
+			t.Errorf("expected match with rehash; got match=%v rehash=%v err=%v", match, rehash, err)
+		}
+		if match, _, err := hasher.Verify("wrong", string(legacy)); match || err != nil {
+			t.Errorf("expected mismatch; got match=%v err=%v", match, err)
+		}
+	})
+
+	t.Run("should never match accounts without a password", func(t *testing.T) {
+		if match, _, err := hasher.Verify("", ""); match || err != nil {
+			t.Errorf("expected mismatch; got match=%v err=%v", match, err)
+		}
+	})
+
+	t.Run("should reject unknown formats", func(t *testing.T) {
+		if _, _, err := hasher.Verify("password", "plaintext"); err != ErrMalformedHash {
+			t.Errorf("expected ErrMalformedHash; got %v", err)
+		}
+	})
+
+	t.Run("should reject degenerate parameters", func(t *testing.T) {
+		salt := "c29tZXNhbHRzb21lc2FsdA"
+		key := "a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5"
+		for _, hash := range []string{
+			"$argon2id$v=19$m=64,t=0,p=1$" + salt + "$" + key,
+			"$argon2id$v=19$m=64,t=1,p=0$" + salt + "$" + key,
+			"$argon2id$v=19$m=0,t=1,p=1$" + salt + "$" + key,
+			"$argon2id$v=19$m=64,t=1,p=16$" + salt + "$" + key,
+			"$argon2id$v=19$m=64,t=1,p=1$" + salt + "$",
+			"$argon2id$v=19$m=64,t=1,p=1$$" + key,
+		} {
+			if match, _, err := hasher.Verify("password", hash); match || err != ErrMalformedHash {
+				t.Errorf("%s: expected ErrMalformedHash; got match=%v err=%v", hash, match, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package password
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Policy decides whether a new password is strong enough. Length limits are
+// left to request validation.
+type Policy struct {
+	// MinEntropy is the minimum estimated strength in bits
+	MinEntropy float64
+	// Breached, if set, rejects passwords known from data breaches
+	Breached *BreachedList
+}
+
+// Check returns the reasons password is rejected, or nil if it is accepted.
+// userInputs are values such as the username or email address that must not
+// appear in the password.
+func (p *Policy) Check(password string, userInputs ...string) []string {
+	var violations []string
+	lower := strings.ToLower(password)
+	for _, input := range bannedSubstrings(userInputs) {
+		if strings.Contains(lower, input) {
+			violations = append(violations, "must not contain your username or email address")
+			break
+		}
+	}
+	if Entropy(password) < p.MinEntropy {
+		violations = append(violations, "is too easy to guess, use a longer password or mix in other kinds of characters")
+	}
+	if p.Breached != nil && p.Breached.Contains(password) {
+		violations = append(violations, "has appeared in a data breach, choose a different password")
+	}
+	return violations
+}
+
+// bannedSubstrings expands the user inputs, e.g. an email address also bans
+// its local part. Very short values are ignored to avoid false positives.
+func bannedSubstrings(userInputs []string) []string {
+	var banned []string
+	for _, input := range userInputs {
+		input = strings.ToLower(strings.TrimSpace(input))
+		candidates := []string{input}
+		if local, _, ok := strings.Cut(input, "@"); ok {
+			candidates = append(candidates, local)
+		}
+		for _, candidate := range candidates {
+			if len(candidate) >= 3 {
+				banned = append(banned, candidate)
+			}
+		}
+	}
+	return banned
+}
+
+// Entropy estimates the strength of password in bits. Each character adds
+// the bits of the character classes in use, while repeated characters and
+// runs like "aaa" or "abc" and "321" add next to nothing.
+func Entropy(password string) float64 {
+	bitsPerChar := math.Log2(float64(poolSize(password)))
+	var bits float64
+	seen := make(map[rune]bool)
+	var prev rune
+	for i, r := range []rune(password) {
+		switch {
+		case i > 0 && (r == prev || r == prev+1 || r == prev-1):
+			bits++
+		case seen[r]:
+			bits += bitsPerChar / 2
+		default:
+			bits += bitsPerChar
+		}
+		seen[r] = true
+		prev = r
+	}
+	return bits
+}
+
+func poolSize(password string) int {
+	var lower, upper, digit, symbol, other bool
+	for _, r := range password {
+		switch {
+		case r > unicode.MaxASCII:
+			other = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+	size := 1
+	for _, class := range []struct {
+		used bool
+		size int
+	}{{lower, 26}, {upper, 26}, {digit, 10}, {symbol, 33}, {other, 100}} {
+		if class.used {
+			size += class.size
+		}
+	}
+	return size
+}
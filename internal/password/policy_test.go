@@ -0,0 +1,67 @@
+package password
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPolicy(t *testing.T) {
+	// SHA-1 hashes of "Tr0ub4dor&3" and, with a count, "password"
+	breached, err := ReadBreachedList(strings.NewReader(`
+# test corpus
+874572E7A5AE6A49466A6AC578B98ADBA78C6AA6
+5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:42
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy := &Policy{MinEntropy: 40, Breached: breached}
+
+	tests := []struct {
+		name     string
+		password string
+		inputs   []string
+		wantErr  string
+	}{
+		{"strong password", "plum-Orbit-71-lantern", nil, ""},
+		{"repeated characters", "aaaaaaaaaaaaaaaa", nil, "too easy to guess"},
+		{"sequence", "abcdefgh12345678", nil, "too easy to guess"},
+		{"contains username", "gopher_jane-Orbit-71", []string{"gopher_jane"}, "username or email"},
+		{"contains email local part", "Orbit-71-jane.doe!x", []string{"jane.doe@example.com"}, "username or email"},
+		{"breached", "Tr0ub4dor&3", nil, "data breach"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := policy.Check(tt.password, tt.inputs...)
+			if tt.wantErr == "" {
+				if len(violations) > 0 {
+					t.Errorf("expected no violations; got %v", violations)
+				}
+				return
+			}
+			if !strings.Contains(strings.Join(violations, "; "), tt.wantErr) {
+				t.Errorf("expected a violation containing %q; got %v", tt.wantErr, violations)
+			}
+		})
+	}
+}
+
+func TestReadBreachedList(t *testing.T) {
+	t.Run("should reject malformed hashes", func(t *testing.T) {
+		if _, err := ReadBreachedList(strings.NewReader("not-a-hash\n")); err == nil {
+			t.Error("expected an error")
+		}
+	})
+
+	t.Run("should ignore duplicates and case", func(t *testing.T) {
+		list, err := ReadBreachedList(strings.NewReader(
+			"5baa61e4c9b93f3f0682250b6cf8331b7ee68fd8\n5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:3\n",
+		))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if list.Len() != 1 || !list.Contains("password") {
+			t.Errorf("expected the corpus to hold only \"password\"; got %d hashes", list.Len())
+		}
+	})
+}
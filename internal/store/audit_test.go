@@ -0,0 +1,63 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// auditFailingConn is a database connection on which every update changes
+// one row but audit entries cannot be inserted. It keeps what became of the
+// last transaction.
+type auditFailingConn struct {
+	committed  bool
+	rolledBack bool
+}
+
+func (c *auditFailingConn) Connect(ctx context.Context) (driver.Conn, error) { return c, nil }
+func (c *auditFailingConn) Driver() driver.Driver                            { return nil }
+func (c *auditFailingConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+func (c *auditFailingConn) Close() error              { return nil }
+func (c *auditFailingConn) Begin() (driver.Tx, error) { return c, nil }
+func (c *auditFailingConn) Commit() error {
+	c.committed = true
+	return nil
+}
+func (c *auditFailingConn) Rollback() error {
+	c.rolledBack = true
+	return nil
+}
+func (c *auditFailingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+func (c *auditFailingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if strings.Contains(query, "INSERT INTO audit_logs") {
+		return nil, errors.New("audit log unavailable")
+	}
+	return nil, errors.New("not supported")
+}
+
+func TestAuditedChangeRollsBack(t *testing.T) {
+	conn := &auditFailingConn{}
+	db := sql.OpenDB(conn)
+	defer db.Close()
+
+	t.Run("should roll the change back when its audit entry fails", func(t *testing.T) {
+		posts := &PostStore{db: db}
+		audit := func() *AuditEntry {
+			return &AuditEntry{Action: "post.restore", TargetType: "post", TargetID: "1"}
+		}
+		err := posts.Restore(context.Background(), 1, audit)
+		if err == nil || !strings.Contains(err.Error(), "audit log unavailable") {
+			t.Fatalf("expected the audit error; got %v", err)
+		}
+		if conn.committed || !conn.rolledBack {
+			t.Errorf("expected the restore to be rolled back; committed %t, rolled back %t", conn.committed, conn.rolledBack)
+		}
+	})
+}
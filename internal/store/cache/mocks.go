@@ -11,12 +11,19 @@ type MockCacheStorage struct {
 	Users interface {
 		Get(context.Context, int64) (*store.User, error)
 		Set(context.Context, *store.User) error
+		Delete(context.Context, int64) error
+	}
+	Roles interface {
+		GetPermissions(context.Context, int64) ([]string, error)
+		SetPermissions(context.Context, int64, []string) error
+		Delete(context.Context, int64) error
 	}
 }
 
 func NewMockCacheStorage(client *redis.Client) *CacheStorage {
 	return &CacheStorage{
 		Users: &MockUserCache{},
+		Roles: &MockRoleCache{},
 	}
 }
 
@@ -30,3 +37,22 @@ func (m *MockUserCache) Get(ctx context.Context, id int64) (*store.User, error)
 func (m *MockUserCache) Set(ctx context.Context, user *store.User) error {
 	return nil
 }
+
+func (m *MockUserCache) Delete(ctx context.Context, id int64) error {
+	return nil
+}
+
+type MockRoleCache struct {
+}
+
+func (m *MockRoleCache) GetPermissions(ctx context.Context, roleID int64) ([]string, error) {
+	return nil, nil
+}
+
+func (m *MockRoleCache) SetPermissions(ctx context.Context, roleID int64, permissions []string) error {
+	return nil
+}
+
+func (m *MockRoleCache) Delete(ctx context.Context, roleID int64) error {
+	return nil
+}
@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// RoleCache keeps the permissions of each role. It is shared by every
+// instance, so dropping a role's entry takes effect everywhere at once.
+type RoleCache struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// GetPermissions returns nil if the role's permissions are not cached.
+func (c *RoleCache) GetPermissions(ctx context.Context, roleID int64) ([]string, error) {
+	roleCacheKey := fmt.Sprintf("role:%d:permissions", roleID)
+	data, err := c.client.Get(ctx, roleCacheKey).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+	permissions := []string{}
+	if err := json.Unmarshal([]byte(data), &permissions); err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+func (c *RoleCache) SetPermissions(ctx context.Context, roleID int64, permissions []string) error {
+	roleCacheKey := fmt.Sprintf("role:%d:permissions", roleID)
+	if permissions == nil {
+		permissions = []string{}
+	}
+	json, err := json.Marshal(permissions)
+	if err != nil {
+		return err
+	}
+	return c.client.SetEX(ctx, roleCacheKey, json, c.ttl).Err()
+}
+
+func (c *RoleCache) Delete(ctx context.Context, roleID int64) error {
+	roleCacheKey := fmt.Sprintf("role:%d:permissions", roleID)
+	return c.client.Del(ctx, roleCacheKey).Err()
+}
@@ -12,11 +12,18 @@ type CacheStorage struct {
 	Users interface {
 		Get(context.Context, int64) (*store.User, error)
 		Set(context.Context, *store.User) error
+		Delete(context.Context, int64) error
+	}
+	Roles interface {
+		GetPermissions(context.Context, int64) ([]string, error)
+		SetPermissions(context.Context, int64, []string) error
+		Delete(context.Context, int64) error
 	}
 }
 
 func NewRedisStorage(client *redis.Client) *CacheStorage {
 	return &CacheStorage{
 		Users: &UserCache{client: client, ttl: time.Hour},
+		Roles: &RoleCache{client: client, ttl: time.Hour},
 	}
 }
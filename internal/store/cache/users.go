@@ -42,3 +42,8 @@ func (c *UserCache) Set(ctx context.Context, user *store.User) error {
 	}
 	return c.client.SetEX(ctx, userCacheKey, json, c.ttl).Err()
 }
+
+func (c *UserCache) Delete(ctx context.Context, userID int64) error {
+	userCacheKey := fmt.Sprintf("user:%d", userID)
+	return c.client.Del(ctx, userCacheKey).Err()
+}
@@ -3,6 +3,7 @@ package store
 import (
 	"context"
 	"database/sql"
+	"time"
 )
 
 // Comment represents a comment on a post
@@ -14,6 +15,9 @@ type Comment struct {
 	UserID    int64  `json:"user_id" example:"2"`
 	Content   string `json:"content" example:"Great post!"`
 	CreatedAt string `json:"created_at" example:"2026-01-06T07:22:18Z"`
+	// DeletedAt and DeletedBy are only set on comments in the trash
+	DeletedAt *string `json:"deleted_at,omitempty" example:"2026-01-08T10:00:00Z"`
+	DeletedBy *int64  `json:"deleted_by,omitempty" example:"3"`
 }
 
 type CommentStore struct {
@@ -25,7 +29,7 @@ func (s *CommentStore) GetByPostID(ctx context.Context, postId int64) ([]*Commen
 		SELECT c.id, c.post_id, c.user_id, c.content, c.created_at
 		FROM comments c
 		JOIN users ON users.id = c.user_id
-		WHERE c.post_id = $1
+		WHERE c.post_id = $1 AND c.deleted_at IS NULL
 		ORDER BY c.created_at DESC
 	`
 	ctx, cancel := withTimeout(ctx)
@@ -70,3 +74,131 @@ func (s *CommentStore) Create(ctx context.Context, comment *Comment) error {
 		comment.Content,
 	).Scan(&comment.ID, &comment.CreatedAt)
 }
+
+func (s *CommentStore) GetByID(ctx context.Context, id int64) (*Comment, error) {
+	query := `
+		SELECT id, post_id, user_id, content, created_at
+		FROM comments
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	comment := &Comment{}
+	err := s.db.QueryRowContext(ctx, query, id).Scan(
+		&comment.ID,
+		&comment.PostID,
+		&comment.UserID,
+		&comment.Content,
+		&comment.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return comment, nil
+}
+
+// Delete moves the comment to the trash until it is restored or purged.
+func (s *CommentStore) Delete(ctx context.Context, id, deletedBy int64, audit AuditFunc) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `
+			UPDATE comments SET deleted_at = NOW(), deleted_by = $2
+			WHERE id = $1 AND deleted_at IS NULL
+		`
+		ctx, cancel := withTimeout(ctx)
+		defer cancel()
+		res, err := tx.ExecContext(ctx, query, id, deletedBy)
+		if err != nil {
+			return err
+		}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return ErrNotFound
+		}
+		return recordAudit(ctx, tx, audit)
+	})
+}
+
+// ListDeleted returns the comments in the trash by when they were deleted.
+func (s *CommentStore) ListDeleted(ctx context.Context, params *PaginationParams) ([]*Comment, error) {
+	query := `
+		SELECT id, post_id, user_id, content, created_at, deleted_at, deleted_by
+		FROM comments
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at ` + params.Sort + `
+		LIMIT $1 OFFSET $2
+	`
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	rows, err := s.db.QueryContext(ctx, query, params.Limit, params.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comments := []*Comment{}
+	for rows.Next() {
+		comment := &Comment{}
+		err := rows.Scan(
+			&comment.ID,
+			&comment.PostID,
+			&comment.UserID,
+			&comment.Content,
+			&comment.CreatedAt,
+			&comment.DeletedAt,
+			&comment.DeletedBy,
+		)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, comment)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// Restore takes the comment out of the trash. It returns ErrNotFound if the
+// comment is not in the trash.
+func (s *CommentStore) Restore(ctx context.Context, id int64, audit AuditFunc) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `
+			UPDATE comments SET deleted_at = NULL, deleted_by = NULL
+			WHERE id = $1 AND deleted_at IS NOT NULL
+		`
+		ctx, cancel := withTimeout(ctx)
+		defer cancel()
+		res, err := tx.ExecContext(ctx, query, id)
+		if err != nil {
+			return err
+		}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return ErrNotFound
+		}
+		return recordAudit(ctx, tx, audit)
+	})
+}
+
+// PurgeDeleted permanently deletes the comments that have been in the trash
+// longer than retention.
+func (s *CommentStore) PurgeDeleted(ctx context.Context, retention time.Duration) (int64, error) {
+	query := `DELETE FROM comments WHERE deleted_at < $1`
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	res, err := s.db.ExecContext(ctx, query, time.Now().Add(-retention))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,147 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Identity links an account at an external identity provider to a user
+type Identity struct {
+	Provider  string `json:"provider"`
+	Subject   string `json:"subject"`
+	UserID    int64  `json:"user_id"`
+	Email     string `json:"email"`
+	CreatedAt string `json:"created_at"`
+}
+
+type IdentityStore struct {
+	db *sql.DB
+}
+
+// GetUser returns the active user linked to the provider account, or nil if
+// the account has not been linked yet.
+func (s *IdentityStore) GetUser(ctx context.Context, provider, subject string) (*User, error) {
+	query := `
+		SELECT u.id, u.username, u.email, u.password_hash, u.created_at, u.is_active, u.role_id
+		FROM users u
+		JOIN user_identities ui ON u.id = ui.user_id
+		WHERE ui.provider = $1 AND ui.subject = $2 AND u.is_active = TRUE AND u.deactivated_at IS NULL
+	`
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	user := &User{}
+	err := s.db.QueryRowContext(ctx, query, provider, subject).Scan(
+		&user.ID,
+		&user.Username,
+		&user.Email,
+		&user.Password,
+		&user.CreatedAt,
+		&user.IsActive,
+		&user.RoleID,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// LinkByEmail links the identity to the user registered with the same email.
+// The provider has verified the address, so a user still waiting for
+// activation is activated as well. It returns nil when no user has the email
+// and ErrUserDeactivated when that user was deactivated by an administrator.
+// Users without a password hash can only sign in through a provider until
+// they set one with a password reset.
+func (s *IdentityStore) LinkByEmail(ctx context.Context, identity *Identity) (*User, error) {
+	query := `
+		SELECT id, username, email, password_hash, created_at, is_active, role_id, deactivated_at
+		FROM users
+		WHERE email = $1
+		FOR UPDATE
+	`
+	users := &UserStore{db: s.db}
+	user := &User{}
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := withTimeout(ctx)
+		defer cancel()
+
+		err := tx.QueryRowContext(ctx, query, identity.Email).Scan(
+			&user.ID,
+			&user.Username,
+			&user.Email,
+			&user.Password,
+			&user.CreatedAt,
+			&user.IsActive,
+			&user.RoleID,
+			&user.DeactivatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		if user.DeactivatedAt != nil {
+			return ErrUserDeactivated
+		}
+		if !user.IsActive {
+			// Whoever registered the pending account never proved they own
+			// the email, so the password they chose must not survive.
+			user.IsActive = true
+			user.Password = ""
+			if err := users.update(ctx, tx, user); err != nil {
+				return err
+			}
+			if err := users.updatePassword(ctx, tx, user); err != nil {
+				return err
+			}
+			if err := users.deleteUserInvitation(ctx, tx, user.ID); err != nil {
+				return err
+			}
+		}
+		identity.UserID = user.ID
+		return s.create(ctx, tx, identity)
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// CreateUser creates an already active user for the identity, skipping the
+// invitation step.
+func (s *IdentityStore) CreateUser(ctx context.Context, user *User, identity *Identity) error {
+	users := &UserStore{db: s.db}
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if err := users.Create(ctx, tx, user); err != nil {
+			return err
+		}
+		user.IsActive = true
+		if err := users.update(ctx, tx, user); err != nil {
+			return err
+		}
+		identity.UserID = user.ID
+		return s.create(ctx, tx, identity)
+	})
+}
+
+func (s *IdentityStore) create(ctx context.Context, tx *sql.Tx, identity *Identity) error {
+	query := `
+		INSERT INTO user_identities (provider, subject, user_id, email)
+		VALUES ($1, $2, $3, $4) RETURNING created_at
+	`
+	ctx, cancel, execer := prepareContext(ctx, s.db, tx)
+	defer cancel()
+
+	return execer.QueryRowContext(
+		ctx,
+		query,
+		identity.Provider,
+		identity.Subject,
+		identity.UserID,
+		identity.Email,
+	).Scan(&identity.CreatedAt)
+}
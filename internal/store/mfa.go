@@ -0,0 +1,183 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// MFA represents a user's TOTP enrollment. It is pending until the user
+// proves possession of the secret by confirming a code.
+type MFA struct {
+	UserID      int64
+	Secret      string
+	LastCounter int64
+	Confirmed   bool
+}
+
+type MFAStore struct {
+	db *sql.DB
+}
+
+var (
+	ErrMFAAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrInvalidMFACode    = errors.New("invalid two-factor authentication code")
+)
+
+func (s *MFAStore) Get(ctx context.Context, userID int64) (*MFA, error) {
+	query := `
+		SELECT user_id, secret, last_counter, confirmed_at IS NOT NULL
+		FROM user_mfa
+		WHERE user_id = $1
+	`
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	mfa := &MFA{}
+	err := s.db.QueryRowContext(ctx, query, userID).Scan(
+		&mfa.UserID,
+		&mfa.Secret,
+		&mfa.LastCounter,
+		&mfa.Confirmed,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return mfa, nil
+}
+
+// Enroll stores a pending secret for the user, replacing any earlier pending
+// one. A confirmed enrollment must be disabled before enrolling again.
+func (s *MFAStore) Enroll(ctx context.Context, userID int64, secret string) error {
+	query := `
+		INSERT INTO user_mfa (user_id, secret)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret = EXCLUDED.secret, last_counter = 0, created_at = NOW()
+		WHERE user_mfa.confirmed_at IS NULL
+	`
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, userID, secret)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrMFAAlreadyEnabled
+	}
+	return nil
+}
+
+// Confirm activates a pending enrollment and replaces the recovery codes.
+func (s *MFAStore) Confirm(ctx context.Context, userID, counter int64, recoveryCodes []string) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `
+			UPDATE user_mfa SET confirmed_at = NOW(), last_counter = $2
+			WHERE user_id = $1 AND confirmed_at IS NULL
+		`
+		ctx, cancel := withTimeout(ctx)
+		defer cancel()
+
+		res, err := tx.ExecContext(ctx, query, userID, counter)
+		if err != nil {
+			return err
+		}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return ErrMFAAlreadyEnabled
+		}
+		return s.replaceRecoveryCodes(ctx, tx, userID, recoveryCodes)
+	})
+}
+
+// UseCounter records the time step of an accepted code, rejecting codes from
+// a step that was already used so a code cannot be replayed.
+func (s *MFAStore) UseCounter(ctx context.Context, userID, counter int64) error {
+	query := `
+		UPDATE user_mfa SET last_counter = $2
+		WHERE user_id = $1 AND last_counter < $2 AND confirmed_at IS NOT NULL
+	`
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, userID, counter)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrInvalidMFACode
+	}
+	return nil
+}
+
+func (s *MFAStore) UseRecoveryCode(ctx context.Context, userID int64, code string) error {
+	query := `
+		UPDATE mfa_recovery_codes SET used_at = NOW()
+		WHERE user_id = $1 AND code = $2 AND used_at IS NULL
+	`
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, userID, code)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrInvalidMFACode
+	}
+	return nil
+}
+
+func (s *MFAStore) ReplaceRecoveryCodes(ctx context.Context, userID int64, recoveryCodes []string) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		return s.replaceRecoveryCodes(ctx, tx, userID, recoveryCodes)
+	})
+}
+
+func (s *MFAStore) Disable(ctx context.Context, userID int64) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := withTimeout(ctx)
+		defer cancel()
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `DELETE FROM user_mfa WHERE user_id = $1`, userID)
+		return err
+	})
+}
+
+func (s *MFAStore) replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userID int64, recoveryCodes []string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	for _, code := range recoveryCodes {
+		query := `INSERT INTO mfa_recovery_codes (user_id, code) VALUES ($1, $2)`
+		if _, err := tx.ExecContext(ctx, query, userID, code); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -3,15 +3,22 @@ package store
 import (
 	"context"
 	"database/sql"
+	"strconv"
 	"time"
-
-	"github.com/samuel032khoury/gopherfeed/internal/auth"
 )
 
 func NewMockStore() Storage {
 	return Storage{
-		Posts: &MockPostStore{},
-		Users: &MockUserStore{},
+		Posts:       &MockPostStore{},
+		Users:       &MockUserStore{},
+		Comments:    &MockCommentStore{},
+		Roles:       &MockRoleStore{},
+		Sessions:    &MockSessionStore{},
+		MFA:         &MockMFAStore{},
+		Tokens:      &MockPersonalAccessTokenStore{},
+		Passkeys:    &MockPasskeyStore{},
+		Audit:       &MockAuditStore{},
+		Suspensions: &MockSuspensionStore{},
 	}
 }
 
@@ -21,17 +28,50 @@ func (m *MockPostStore) Create(ctx context.Context, post *Post) error {
 	return nil
 }
 func (m *MockPostStore) GetByID(ctx context.Context, id int64) (*Post, error) {
-	return &Post{}, nil
+	return &Post{Status: PostStatusPublished}, nil
 }
-func (m *MockPostStore) Delete(ctx context.Context, id int64) error {
+func (m *MockPostStore) Delete(ctx context.Context, id, deletedBy int64, audit AuditFunc) error {
 	return nil
 }
-func (m *MockPostStore) Update(ctx context.Context, post *Post) error {
+func (m *MockPostStore) ListDeleted(ctx context.Context, params *PaginationParams) ([]*Post, error) {
+	return []*Post{}, nil
+}
+func (m *MockPostStore) Restore(ctx context.Context, id int64, audit AuditFunc) error {
+	return nil
+}
+func (m *MockPostStore) PurgeDeleted(ctx context.Context, retention time.Duration) (int64, error) {
+	return 0, nil
+}
+func (m *MockPostStore) Update(ctx context.Context, post *Post, editorID int64, audit AuditFunc) error {
 	return nil
 }
 func (m *MockPostStore) GetFeed(ctx context.Context, userID int64, params *PaginationParams) ([]*FeedablePost, error) {
 	return []*FeedablePost{}, nil
 }
+func (m *MockPostStore) ListDrafts(ctx context.Context, userID int64, params *PaginationParams) ([]*Post, error) {
+	return []*Post{}, nil
+}
+func (m *MockPostStore) PublishDue(ctx context.Context, limit int) (int64, error) {
+	return 0, nil
+}
+func (m *MockPostStore) RerenderStale(ctx context.Context, limit int) (int64, error) {
+	return 0, nil
+}
+func (m *MockPostStore) ListRevisions(ctx context.Context, postID int64, filter *RevisionFilter) ([]*PostRevision, error) {
+	return []*PostRevision{}, nil
+}
+func (m *MockPostStore) GetRevision(ctx context.Context, postID int64, version int) (*PostRevision, error) {
+	if version < 1 || version > 2 {
+		return nil, nil
+	}
+	return &PostRevision{
+		PostID:  postID,
+		Version: version,
+		Title:   "Post Title",
+		Content: "first line\nversion " + strconv.Itoa(version),
+		Tags:    []string{"tag" + strconv.Itoa(version)},
+	}, nil
+}
 
 type MockUserStore struct{}
 
@@ -50,8 +90,8 @@ func (m *MockUserStore) GetByID(ctx context.Context, id int64) (*User, error) {
 func (m *MockUserStore) Register(ctx context.Context, user *User, token string, exp time.Duration) error {
 	return nil
 }
-func (m *MockUserStore) Authenticate(ctx context.Context, email string, password string, authenticator auth.Authenticator) (string, error) {
-	return "", nil
+func (m *MockUserStore) Authenticate(ctx context.Context, email string, password string) (*User, error) {
+	return &User{}, nil
 }
 func (m *MockUserStore) Activate(ctx context.Context, token string) error {
 	return nil
@@ -59,3 +99,241 @@ func (m *MockUserStore) Activate(ctx context.Context, token string) error {
 func (m *MockUserStore) Delete(ctx context.Context, id int64) error {
 	return nil
 }
+func (m *MockUserStore) GetByEmail(ctx context.Context, email string) (*User, error) {
+	return nil, nil
+}
+func (m *MockUserStore) CreatePasswordReset(ctx context.Context, userID int64, token string, exp time.Duration) error {
+	return nil
+}
+func (m *MockUserStore) GetByPasswordReset(ctx context.Context, token string) (*User, error) {
+	return &User{}, nil
+}
+func (m *MockUserStore) ResetPassword(ctx context.Context, token string, passwordHash string) (*User, error) {
+	return &User{}, nil
+}
+func (m *MockUserStore) ChangePassword(ctx context.Context, userID int64, passwordHash string) error {
+	return nil
+}
+func (m *MockUserStore) RotateInvitation(ctx context.Context, email string, token string, exp time.Duration) (*User, error) {
+	return nil, nil
+}
+func (m *MockUserStore) PurgeUnactivated(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+func (m *MockUserStore) CreateLoginToken(ctx context.Context, user *User, token string, exp time.Duration) error {
+	return nil
+}
+func (m *MockUserStore) RedeemLoginToken(ctx context.Context, token string) (*User, error) {
+	return nil, ErrInvalidToken
+}
+func (m *MockUserStore) CreateEmailChange(ctx context.Context, userID int64, newEmail, token string, exp time.Duration) error {
+	return nil
+}
+func (m *MockUserStore) ConfirmEmailChange(ctx context.Context, token string) (*User, error) {
+	return nil, ErrInvalidToken
+}
+func (m *MockUserStore) GetByIDIncludingInactive(ctx context.Context, id int64) (*User, error) {
+	return m.GetByID(ctx, id)
+}
+func (m *MockUserStore) List(ctx context.Context, filter *UserFilter) ([]*User, error) {
+	return []*User{}, nil
+}
+func (m *MockUserStore) SetRole(ctx context.Context, userID, roleID int64, audit AuditFunc) error {
+	return nil
+}
+func (m *MockUserStore) Deactivate(ctx context.Context, userID int64, audit AuditFunc) error {
+	return nil
+}
+func (m *MockUserStore) Reactivate(ctx context.Context, userID int64, audit AuditFunc) error {
+	return nil
+}
+
+type MockCommentStore struct{}
+
+func (m *MockCommentStore) GetByPostID(ctx context.Context, postID int64) ([]*Comment, error) {
+	return []*Comment{}, nil
+}
+func (m *MockCommentStore) GetByID(ctx context.Context, id int64) (*Comment, error) {
+	return &Comment{ID: id, UserID: 2}, nil
+}
+func (m *MockCommentStore) Create(ctx context.Context, comment *Comment) error {
+	return nil
+}
+func (m *MockCommentStore) Delete(ctx context.Context, id, deletedBy int64, audit AuditFunc) error {
+	return nil
+}
+func (m *MockCommentStore) ListDeleted(ctx context.Context, params *PaginationParams) ([]*Comment, error) {
+	return []*Comment{}, nil
+}
+func (m *MockCommentStore) Restore(ctx context.Context, id int64, audit AuditFunc) error {
+	return nil
+}
+func (m *MockCommentStore) PurgeDeleted(ctx context.Context, retention time.Duration) (int64, error) {
+	return 0, nil
+}
+
+type MockRoleStore struct{}
+
+func (m *MockRoleStore) GetByName(ctx context.Context, name string) (*Role, error) {
+	return &Role{Name: name}, nil
+}
+func (m *MockRoleStore) GetByID(ctx context.Context, id int64) (*Role, error) {
+	return &Role{ID: id, Name: "user", Level: 1}, nil
+}
+func (m *MockRoleStore) GetPermissions(ctx context.Context, roleID int64) ([]string, error) {
+	return []string{}, nil
+}
+func (m *MockRoleStore) List(ctx context.Context) ([]*Role, error) {
+	return []*Role{}, nil
+}
+func (m *MockRoleStore) Create(ctx context.Context, role *Role, audit AuditFunc) error {
+	return nil
+}
+func (m *MockRoleStore) Update(ctx context.Context, role *Role, audit AuditFunc) error {
+	return nil
+}
+
+type MockSessionStore struct{}
+
+func (m *MockSessionStore) Create(ctx context.Context, session *Session, token string, exp time.Duration, audit AuditFunc) error {
+	return nil
+}
+func (m *MockSessionStore) GetActive(ctx context.Context, id string) (*Session, error) {
+	if id == "" {
+		return nil, nil
+	}
+	return &Session{ID: id, AccessJTI: "test-jti"}, nil
+}
+func (m *MockSessionStore) ListActive(ctx context.Context, userID int64) ([]*Session, error) {
+	return []*Session{}, nil
+}
+func (m *MockSessionStore) IsNewDevice(ctx context.Context, userID int64, userAgent string) (bool, error) {
+	return false, nil
+}
+func (m *MockSessionStore) Rotate(ctx context.Context, token, newToken, accessJTI string, exp time.Duration) (*Session, error) {
+	return &Session{}, nil
+}
+func (m *MockSessionStore) Revoke(ctx context.Context, id string, audit AuditFunc) error {
+	return nil
+}
+func (m *MockSessionStore) RevokeForUser(ctx context.Context, id string, userID int64) error {
+	return nil
+}
+func (m *MockSessionStore) RevokeOthers(ctx context.Context, userID int64, keepID string) error {
+	return nil
+}
+func (m *MockSessionStore) RevokeImpersonation(ctx context.Context, id string, impersonatorID int64, audit AuditFunc) error {
+	return nil
+}
+func (m *MockSessionStore) RevokeAllForUser(ctx context.Context, userID int64) error {
+	return nil
+}
+func (m *MockSessionStore) RevokeByRefreshToken(ctx context.Context, token string) error {
+	return nil
+}
+
+type MockMFAStore struct{}
+
+func (m *MockMFAStore) Get(ctx context.Context, userID int64) (*MFA, error) {
+	return nil, nil
+}
+func (m *MockMFAStore) Enroll(ctx context.Context, userID int64, secret string) error {
+	return nil
+}
+func (m *MockMFAStore) Confirm(ctx context.Context, userID, counter int64, recoveryCodes []string) error {
+	return nil
+}
+func (m *MockMFAStore) UseCounter(ctx context.Context, userID, counter int64) error {
+	return nil
+}
+func (m *MockMFAStore) UseRecoveryCode(ctx context.Context, userID int64, code string) error {
+	return nil
+}
+func (m *MockMFAStore) ReplaceRecoveryCodes(ctx context.Context, userID int64, recoveryCodes []string) error {
+	return nil
+}
+func (m *MockMFAStore) Disable(ctx context.Context, userID int64) error {
+	return nil
+}
+
+type MockPasskeyStore struct{}
+
+func (m *MockPasskeyStore) Create(ctx context.Context, passkey *Passkey) error {
+	return nil
+}
+func (m *MockPasskeyStore) ListByUser(ctx context.Context, userID int64) ([]*Passkey, error) {
+	return []*Passkey{}, nil
+}
+func (m *MockPasskeyStore) RecordUse(ctx context.Context, id, signCount int64) error {
+	return nil
+}
+func (m *MockPasskeyStore) Delete(ctx context.Context, id, userID int64) error {
+	return nil
+}
+func (m *MockPasskeyStore) CreateCeremony(ctx context.Context, ceremony *PasskeyCeremony, exp time.Duration) error {
+	return nil
+}
+func (m *MockPasskeyStore) ConsumeCeremony(ctx context.Context, id, purpose string) (*PasskeyCeremony, error) {
+	return nil, nil
+}
+
+type MockPersonalAccessTokenStore struct{}
+
+func (m *MockPersonalAccessTokenStore) Create(ctx context.Context, pat *PersonalAccessToken, token string, exp time.Duration) error {
+	return nil
+}
+func (m *MockPersonalAccessTokenStore) GetByToken(ctx context.Context, token string) (*PersonalAccessToken, error) {
+	return &PersonalAccessToken{
+		ID:     1,
+		UserID: 1,
+		Name:   "test-token",
+		Scopes: []string{"posts:read"},
+	}, nil
+}
+func (m *MockPersonalAccessTokenStore) ListByUser(ctx context.Context, userID int64) ([]*PersonalAccessToken, error) {
+	return []*PersonalAccessToken{}, nil
+}
+func (m *MockPersonalAccessTokenStore) Delete(ctx context.Context, id, userID int64) error {
+	return nil
+}
+
+type MockAuditStore struct{}
+
+func (m *MockAuditStore) Record(ctx context.Context, entry *AuditEntry) error {
+	return nil
+}
+func (m *MockAuditStore) List(ctx context.Context, filter *AuditFilter) ([]*AuditEntry, error) {
+	return []*AuditEntry{}, nil
+}
+func (m *MockAuditStore) ListAfter(ctx context.Context, filter *AuditFilter, afterID int64) ([]*AuditEntry, error) {
+	return []*AuditEntry{}, nil
+}
+
+// MockSuspensionStore reports the suspensions in Active, keyed by user ID
+type MockSuspensionStore struct {
+	Active map[int64]*Suspension
+}
+
+func (m *MockSuspensionStore) Create(ctx context.Context, suspension *Suspension, duration time.Duration, audit AuditFunc) error {
+	return nil
+}
+func (m *MockSuspensionStore) GetActive(ctx context.Context, userID int64) (*Suspension, error) {
+	return m.Active[userID], nil
+}
+func (m *MockSuspensionStore) Lift(ctx context.Context, suspension *Suspension, audit AuditFunc) error {
+	active, ok := m.Active[suspension.UserID]
+	if !ok {
+		return ErrNotFound
+	}
+	*suspension = *active
+	return nil
+}
+func (m *MockSuspensionStore) Appeal(ctx context.Context, userID int64, note string, audit AuditFunc) error {
+	if _, ok := m.Active[userID]; !ok {
+		return ErrNotFound
+	}
+	return nil
+}
+func (m *MockSuspensionStore) LiftExpired(ctx context.Context) ([]*Suspension, error) {
+	return nil, nil
+}
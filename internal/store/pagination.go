@@ -1,6 +1,7 @@
 package store
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
@@ -63,6 +64,149 @@ func (params *PaginationParams) Parse(r *http.Request) (*PaginationParams, error
 	return params, nil
 }
 
+// RevisionFilter pages through the history of a post
+type RevisionFilter struct {
+	Limit  int    `json:"limit" validate:"min=1,max=100"`
+	Offset int    `json:"offset" validate:"min=0"`
+	Sort   string `json:"sort" validate:"oneof=asc desc"`
+}
+
+func (filter *RevisionFilter) Parse(r *http.Request) (*RevisionFilter, error) {
+	query := r.URL.Query()
+
+	limitStr := query.Get("limit")
+	if limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			return nil, err
+		}
+		filter.Limit = limit
+	}
+	offsetStr := query.Get("offset")
+	if offsetStr != "" {
+		offset, err := strconv.Atoi(offsetStr)
+		if err != nil {
+			return nil, err
+		}
+		filter.Offset = offset
+	}
+	sort := query.Get("sort")
+	if sort != "" {
+		filter.Sort = sort
+	}
+	return filter, nil
+}
+
+// UserFilter narrows down the user listing of the admin API
+type UserFilter struct {
+	Limit  int    `json:"limit" validate:"min=1,max=100"`
+	Offset int    `json:"offset" validate:"min=0"`
+	Role   string `json:"role" validate:"max=255"`
+	Active *bool  `json:"active"`
+	Search string `json:"search" validate:"max=100"`
+}
+
+func (filter *UserFilter) Parse(r *http.Request) (*UserFilter, error) {
+	query := r.URL.Query()
+
+	limitStr := query.Get("limit")
+	if limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			return nil, err
+		}
+		filter.Limit = limit
+	}
+	offsetStr := query.Get("offset")
+	if offsetStr != "" {
+		offset, err := strconv.Atoi(offsetStr)
+		if err != nil {
+			return nil, err
+		}
+		filter.Offset = offset
+	}
+	role := query.Get("role")
+	if role != "" {
+		filter.Role = role
+	}
+	activeStr := query.Get("active")
+	if activeStr != "" {
+		active, err := strconv.ParseBool(activeStr)
+		if err != nil {
+			return nil, err
+		}
+		filter.Active = &active
+	}
+	search := query.Get("search")
+	if search != "" {
+		filter.Search = search
+	}
+	return filter, nil
+}
+
+// AuditFilter narrows down the audit log of the admin API
+type AuditFilter struct {
+	Limit      int    `json:"limit" validate:"min=1,max=100"`
+	Offset     int    `json:"offset" validate:"min=0"`
+	ActorID    *int64 `json:"actor_id"`
+	Action     string `json:"action" validate:"max=100"`
+	TargetType string `json:"target_type" validate:"max=50"`
+	TargetID   string `json:"target_id" validate:"max=100"`
+	Since      string `json:"since"`
+	Until      string `json:"until"`
+}
+
+func (filter *AuditFilter) Parse(r *http.Request) (*AuditFilter, error) {
+	query := r.URL.Query()
+
+	limitStr := query.Get("limit")
+	if limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			return nil, err
+		}
+		filter.Limit = limit
+	}
+	offsetStr := query.Get("offset")
+	if offsetStr != "" {
+		offset, err := strconv.Atoi(offsetStr)
+		if err != nil {
+			return nil, err
+		}
+		filter.Offset = offset
+	}
+	actorStr := query.Get("actor_id")
+	if actorStr != "" {
+		actorID, err := strconv.ParseInt(actorStr, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		filter.ActorID = &actorID
+	}
+	filter.Action = query.Get("action")
+	filter.TargetType = query.Get("target_type")
+	filter.TargetID = query.Get("target_id")
+
+	since := query.Get("since")
+	if since != "" {
+		t, err := parseTimeFilter(since)
+		if err != nil {
+			return nil, err
+		}
+		filter.Since = t
+	}
+
+	until := query.Get("until")
+	if until != "" {
+		t, err := parseTimeFilter(until)
+		if err != nil {
+			return nil, err
+		}
+		filter.Until = t
+	}
+	return filter, nil
+}
+
 func parseTime(s string) string {
 	if t, err := time.Parse(time.RFC3339, s); err == nil {
 		return t.Format(time.DateTime)
@@ -72,3 +216,17 @@ func parseTime(s string) string {
 	}
 	return ""
 }
+
+// parseTimeFilter accepts RFC 3339 times and, taken as UTC, times without a
+// zone. It returns them in RFC 3339 for comparison with timestamptz columns.
+// Unlike parseTime it rejects anything else, so that an audit search never
+// silently drops a bound.
+func parseTimeFilter(s string) (string, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.UTC().Format(time.RFC3339), nil
+	}
+	if t, err := time.Parse(time.DateTime, s); err == nil {
+		return t.UTC().Format(time.RFC3339), nil
+	}
+	return "", fmt.Errorf("invalid time %q: expected RFC 3339", s)
+}
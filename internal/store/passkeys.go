@@ -0,0 +1,191 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Passkey represents a WebAuthn credential registered by a user
+//
+//	@Description	Registered passkey
+type Passkey struct {
+	ID     int64  `json:"id" example:"1"`
+	UserID int64  `json:"user_id" example:"1"`
+	Name   string `json:"name" example:"MacBook Touch ID"`
+	// CredentialID and Credential are the WebAuthn credential ID and the
+	// JSON-encoded credential record as produced by the ceremony.
+	CredentialID []byte  `json:"-"`
+	Credential   []byte  `json:"-"`
+	SignCount    int64   `json:"-"`
+	LastUsedAt   *string `json:"last_used_at" example:"2026-01-06T07:22:18Z"`
+	CreatedAt    string  `json:"created_at" example:"2026-01-06T07:22:18Z"`
+}
+
+// PasskeyCeremony is the state of a WebAuthn ceremony kept between its begin
+// and finish requests. UserID and Name are only set for registrations.
+type PasskeyCeremony struct {
+	ID          string
+	Purpose     string
+	UserID      *int64
+	Name        string
+	SessionData []byte
+}
+
+type PasskeyStore struct {
+	db *sql.DB
+}
+
+var ErrDuplicatePasskey = errors.New("passkey is already registered")
+
+func (s *PasskeyStore) Create(ctx context.Context, passkey *Passkey) error {
+	query := `
+		INSERT INTO passkeys (user_id, name, credential_id, credential, sign_count)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at
+	`
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	err := s.db.QueryRowContext(
+		ctx,
+		query,
+		passkey.UserID,
+		passkey.Name,
+		passkey.CredentialID,
+		passkey.Credential,
+		passkey.SignCount,
+	).Scan(&passkey.ID, &passkey.CreatedAt)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return ErrDuplicatePasskey
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *PasskeyStore) ListByUser(ctx context.Context, userID int64) ([]*Passkey, error) {
+	query := `
+		SELECT id, user_id, name, credential_id, credential, sign_count, last_used_at, created_at
+		FROM passkeys
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	passkeys := []*Passkey{}
+	for rows.Next() {
+		passkey := &Passkey{}
+		err := rows.Scan(
+			&passkey.ID,
+			&passkey.UserID,
+			&passkey.Name,
+			&passkey.CredentialID,
+			&passkey.Credential,
+			&passkey.SignCount,
+			&passkey.LastUsedAt,
+			&passkey.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		passkeys = append(passkeys, passkey)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return passkeys, nil
+}
+
+// RecordUse stores the sign counter reported by the latest assertion.
+func (s *PasskeyStore) RecordUse(ctx context.Context, id, signCount int64) error {
+	query := `UPDATE passkeys SET sign_count = $1, last_used_at = NOW() WHERE id = $2`
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, query, signCount, id)
+	return err
+}
+
+func (s *PasskeyStore) Delete(ctx context.Context, id, userID int64) error {
+	query := `DELETE FROM passkeys WHERE id = $1 AND user_id = $2`
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// CreateCeremony stores the state of a new ceremony and drops the ones that
+// were never finished.
+func (s *PasskeyStore) CreateCeremony(ctx context.Context, ceremony *PasskeyCeremony, exp time.Duration) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := withTimeout(ctx)
+		defer cancel()
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM passkey_ceremonies WHERE expires_at <= NOW()`); err != nil {
+			return err
+		}
+		query := `
+			INSERT INTO passkey_ceremonies (id, purpose, user_id, name, session_data, expires_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`
+		_, err := tx.ExecContext(
+			ctx,
+			query,
+			ceremony.ID,
+			ceremony.Purpose,
+			ceremony.UserID,
+			ceremony.Name,
+			ceremony.SessionData,
+			time.Now().Add(exp),
+		)
+		return err
+	})
+}
+
+// ConsumeCeremony deletes an unexpired ceremony and returns it, so its
+// challenge can be answered only once even by concurrent requests.
+func (s *PasskeyStore) ConsumeCeremony(ctx context.Context, id, purpose string) (*PasskeyCeremony, error) {
+	query := `
+		DELETE FROM passkey_ceremonies
+		WHERE id = $1 AND purpose = $2 AND expires_at > NOW()
+		RETURNING user_id, name, session_data
+	`
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	ceremony := &PasskeyCeremony{ID: id, Purpose: purpose}
+	err := s.db.QueryRowContext(ctx, query, id, purpose).Scan(
+		&ceremony.UserID,
+		&ceremony.Name,
+		&ceremony.SessionData,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return ceremony, nil
+}
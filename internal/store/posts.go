@@ -3,25 +3,44 @@ package store
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/lib/pq"
+	"github.com/samuel032khoury/gopherfeed/internal/markdown"
 )
 
-// Post represents a blog post
+// Post represents a blog post. Content is CommonMark source and ContentHTML
+// its sanitized rendering.
 //
 //	@Description	Blog post information
 type Post struct {
-	ID        int64      `json:"id" example:"1"`
-	Title     string     `json:"title" example:"My First Post"`
-	Content   string     `json:"content" example:"This is the content of my first post"`
-	UserID    int64      `json:"user_id" example:"1"`
-	Tags      []string   `json:"tags" example:"golang,api"`
-	CreatedAt string     `json:"created_at" example:"2026-01-06T07:22:18Z"`
-	UpdatedAt string     `json:"updated_at" example:"2026-01-06T07:22:18Z"`
-	Version   int        `json:"version" example:"1"`
+	ID          int64    `json:"id" example:"1"`
+	Title       string   `json:"title" example:"My First Post"`
+	Content     string   `json:"content" example:"This is the *content* of my first post"`
+	ContentHTML string   `json:"content_html" example:"<p>This is the <em>content</em> of my first post</p>"`
+	UserID      int64    `json:"user_id" example:"1"`
+	Tags        []string `json:"tags" example:"golang,api"`
+	CreatedAt   string   `json:"created_at" example:"2026-01-06T07:22:18Z"`
+	UpdatedAt   string   `json:"updated_at" example:"2026-01-06T07:22:18Z"`
+	Version     int      `json:"version" example:"1"`
+	Status      string   `json:"status" example:"published" enums:"draft,scheduled,published"`
+	// PublishAt is when a scheduled post goes live
+	PublishAt   *string `json:"publish_at" example:"2026-01-07T09:00:00Z"`
+	PublishedAt *string `json:"published_at" example:"2026-01-06T07:22:18Z"`
+	// DeletedAt and DeletedBy are only set on posts in the trash
+	DeletedAt *string    `json:"deleted_at,omitempty" example:"2026-01-08T10:00:00Z"`
+	DeletedBy *int64     `json:"deleted_by,omitempty" example:"3"`
 	Comments  []*Comment `json:"comments"`
+	// RenderVersion is the version of the renderer ContentHTML is from
+	RenderVersion int `json:"-"`
 }
 
+const (
+	PostStatusDraft     = "draft"
+	PostStatusScheduled = "scheduled"
+	PostStatusPublished = "published"
+)
+
 // FeedablePost represents a post with additional feed-specific data
 //
 //	@Description	Post with user and comment count information for feeds
@@ -36,27 +55,41 @@ type PostStore struct {
 }
 
 func (s *PostStore) Create(ctx context.Context, post *Post) error {
-	query := `
-		INSERT INTO posts (title, content, user_id, tags)
-		VALUES ($1, $2, $3, $4) RETURNING id, created_at, updated_at
-	`
-	ctx, cancel := withTimeout(ctx)
-	defer cancel()
-	return s.db.QueryRowContext(
-		ctx,
-		query,
-		post.Title,
-		post.Content,
-		post.UserID,
-		pq.Array(post.Tags),
-	).Scan(&post.ID, &post.CreatedAt, &post.UpdatedAt)
+	if err := renderContent(post); err != nil {
+		return err
+	}
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `
+			INSERT INTO posts (title, content, content_html, render_version, user_id, tags, status, publish_at, published_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, CASE WHEN $7 = 'published' THEN NOW() END)
+			RETURNING id, created_at, updated_at, version, published_at
+		`
+		ctx, cancel := withTimeout(ctx)
+		defer cancel()
+		err := tx.QueryRowContext(
+			ctx,
+			query,
+			post.Title,
+			post.Content,
+			post.ContentHTML,
+			markdown.Version,
+			post.UserID,
+			pq.Array(post.Tags),
+			post.Status,
+			post.PublishAt,
+		).Scan(&post.ID, &post.CreatedAt, &post.UpdatedAt, &post.Version, &post.PublishedAt)
+		if err != nil {
+			return err
+		}
+		return s.createRevision(ctx, tx, post, post.UserID)
+	})
 }
 
 func (s *PostStore) GetByID(ctx context.Context, id int64) (*Post, error) {
 	query := `
-		SELECT id, title, content, user_id, tags, created_at, updated_at, version
+		SELECT id, title, content, content_html, render_version, user_id, tags, created_at, updated_at, version, status, publish_at, published_at
 		FROM posts
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 	ctx, cancel := withTimeout(ctx)
 	defer cancel()
@@ -65,11 +98,16 @@ func (s *PostStore) GetByID(ctx context.Context, id int64) (*Post, error) {
 		&post.ID,
 		&post.Title,
 		&post.Content,
+		&post.ContentHTML,
+		&post.RenderVersion,
 		&post.UserID,
 		pq.Array(&post.Tags),
 		&post.CreatedAt,
 		&post.UpdatedAt,
 		&post.Version,
+		&post.Status,
+		&post.PublishAt,
+		&post.PublishedAt,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -77,53 +115,338 @@ func (s *PostStore) GetByID(ctx context.Context, id int64) (*Post, error) {
 		}
 		return nil, err
 	}
+	if err := renderStale(post); err != nil {
+		return nil, err
+	}
 	return post, nil
 }
 
-func (s *PostStore) Delete(ctx context.Context, id int64) error {
-	query := `DELETE FROM posts WHERE id = $1`
+// Delete moves the post to the trash, hiding it and its comments until it
+// is restored or purged.
+func (s *PostStore) Delete(ctx context.Context, id, deletedBy int64, audit AuditFunc) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `
+			UPDATE posts SET deleted_at = NOW(), deleted_by = $2
+			WHERE id = $1 AND deleted_at IS NULL
+		`
+		ctx, cancel := withTimeout(ctx)
+		defer cancel()
+		res, err := tx.ExecContext(ctx, query, id, deletedBy)
+		if err != nil {
+			return err
+		}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return ErrNotFound
+		}
+		return recordAudit(ctx, tx, audit)
+	})
+}
+
+// ListDeleted returns the posts in the trash by when they were deleted.
+func (s *PostStore) ListDeleted(ctx context.Context, params *PaginationParams) ([]*Post, error) {
+	query := `
+		SELECT id, title, content, content_html, render_version, user_id, tags, created_at, updated_at, version, status, publish_at, published_at, deleted_at, deleted_by
+		FROM posts
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at ` + params.Sort + `
+		LIMIT $1 OFFSET $2
+	`
 	ctx, cancel := withTimeout(ctx)
 	defer cancel()
-	_, err := s.db.ExecContext(ctx, query, id)
-	return err
+	rows, err := s.db.QueryContext(ctx, query, params.Limit, params.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	posts := []*Post{}
+	for rows.Next() {
+		post := &Post{}
+		err := rows.Scan(
+			&post.ID,
+			&post.Title,
+			&post.Content,
+			&post.ContentHTML,
+			&post.RenderVersion,
+			&post.UserID,
+			pq.Array(&post.Tags),
+			&post.CreatedAt,
+			&post.UpdatedAt,
+			&post.Version,
+			&post.Status,
+			&post.PublishAt,
+			&post.PublishedAt,
+			&post.DeletedAt,
+			&post.DeletedBy,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := renderStale(post); err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+// Restore takes the post out of the trash. It returns ErrNotFound if the
+// post is not in the trash.
+func (s *PostStore) Restore(ctx context.Context, id int64, audit AuditFunc) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `
+			UPDATE posts SET deleted_at = NULL, deleted_by = NULL
+			WHERE id = $1 AND deleted_at IS NOT NULL
+		`
+		ctx, cancel := withTimeout(ctx)
+		defer cancel()
+		res, err := tx.ExecContext(ctx, query, id)
+		if err != nil {
+			return err
+		}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return ErrNotFound
+		}
+		return recordAudit(ctx, tx, audit)
+	})
+}
+
+// PurgeDeleted permanently deletes the posts that have been in the trash
+// longer than retention, along with their comments and revisions.
+func (s *PostStore) PurgeDeleted(ctx context.Context, retention time.Duration) (int64, error) {
+	query := `DELETE FROM posts WHERE deleted_at < $1`
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	res, err := s.db.ExecContext(ctx, query, time.Now().Add(-retention))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
 }
 
-func (s *PostStore) Update(ctx context.Context, post *Post) error {
+// Update saves the post if it is still at post.Version and keeps the result
+// as a new revision by editorID. It returns sql.ErrNoRows on a version
+// conflict.
+func (s *PostStore) Update(ctx context.Context, post *Post, editorID int64, audit AuditFunc) error {
+	if err := renderContent(post); err != nil {
+		return err
+	}
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `
+			UPDATE posts
+			SET title = $1, content = $2, tags = $3, status = $6, publish_at = $7,
+				content_html = $8, render_version = $9,
+				published_at = CASE WHEN $6 = 'published' THEN COALESCE(published_at, NOW()) END,
+				updated_at = NOW(), version = version + 1
+			WHERE id = $4 AND version = $5 AND deleted_at IS NULL
+			RETURNING updated_at, version, published_at
+		`
+		ctx, cancel := withTimeout(ctx)
+		defer cancel()
+		err := tx.QueryRowContext(
+			ctx,
+			query,
+			post.Title,
+			post.Content,
+			pq.Array(post.Tags),
+			post.ID,
+			post.Version,
+			post.Status,
+			post.PublishAt,
+			post.ContentHTML,
+			markdown.Version,
+		).Scan(&post.UpdatedAt, &post.Version, &post.PublishedAt)
+		if err != nil {
+			return err
+		}
+		if err := s.createRevision(ctx, tx, post, editorID); err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, audit)
+	})
+}
+
+// RerenderStale renders again up to limit posts whose HTML was produced by
+// an older version of the renderer and returns how many it rendered. Rows
+// another instance is already rendering are skipped. Rendering does not
+// change the post, so the version is left alone.
+func (s *PostStore) RerenderStale(ctx context.Context, limit int) (int64, error) {
+	var count int64
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `
+			SELECT id, content FROM posts
+			WHERE render_version < $1
+			ORDER BY id
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		`
+		ctx, cancel := withTimeout(ctx)
+		defer cancel()
+		rows, err := tx.QueryContext(ctx, query, markdown.Version, limit)
+		if err != nil {
+			return err
+		}
+		posts := []*Post{}
+		for rows.Next() {
+			post := &Post{}
+			if err := rows.Scan(&post.ID, &post.Content); err != nil {
+				rows.Close()
+				return err
+			}
+			posts = append(posts, post)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, post := range posts {
+			if err := renderContent(post); err != nil {
+				return err
+			}
+			query := `UPDATE posts SET content_html = $1, render_version = $2 WHERE id = $3`
+			if _, err := tx.ExecContext(ctx, query, post.ContentHTML, markdown.Version, post.ID); err != nil {
+				return err
+			}
+		}
+		count = int64(len(posts))
+		return nil
+	})
+	return count, err
+}
+
+func renderContent(post *Post) error {
+	html, err := markdown.Render(post.Content)
+	if err != nil {
+		return err
+	}
+	post.ContentHTML = html
+	post.RenderVersion = markdown.Version
+	return nil
+}
+
+// renderStale renders a post that was read before RerenderStale got to it,
+// so a renderer upgrade never serves outdated or missing HTML. The stored
+// HTML is left for RerenderStale to replace.
+func renderStale(post *Post) error {
+	if post.RenderVersion >= markdown.Version {
+		return nil
+	}
+	return renderContent(post)
+}
+
+// ListDrafts returns the user's posts that are not published yet, drafts
+// and scheduled posts alike, by when they were last edited.
+func (s *PostStore) ListDrafts(ctx context.Context, userID int64, params *PaginationParams) ([]*Post, error) {
+	query := `
+		SELECT id, title, content, content_html, render_version, user_id, tags, created_at, updated_at, version, status, publish_at, published_at
+		FROM posts
+		WHERE user_id = $1 AND status <> 'published' AND deleted_at IS NULL
+		ORDER BY updated_at ` + params.Sort + `
+		LIMIT $2 OFFSET $3
+	`
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	rows, err := s.db.QueryContext(ctx, query, userID, params.Limit, params.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	posts := []*Post{}
+	for rows.Next() {
+		post := &Post{}
+		err := rows.Scan(
+			&post.ID,
+			&post.Title,
+			&post.Content,
+			&post.ContentHTML,
+			&post.RenderVersion,
+			&post.UserID,
+			pq.Array(&post.Tags),
+			&post.CreatedAt,
+			&post.UpdatedAt,
+			&post.Version,
+			&post.Status,
+			&post.PublishAt,
+			&post.PublishedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := renderStale(post); err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+// PublishDue publishes up to limit scheduled posts whose time has come and
+// returns how many it published. Rows another instance is already publishing
+// are skipped rather than waited on, and the status check makes publishing a
+// post happen exactly once. publish_at is cleared as on any other published
+// post, so the post can be written back as it is read. The version is bumped
+// so that an edit based on the scheduled post conflicts instead of
+// unpublishing it, and the new version gets a revision without an editor.
+func (s *PostStore) PublishDue(ctx context.Context, limit int) (int64, error) {
 	query := `
-		UPDATE posts
-		SET title = $1, content = $2, tags = $3, updated_at = NOW(), version = version + 1
-		WHERE id = $4 AND version = $5
-		RETURNING updated_at, version
+		WITH published AS (
+			UPDATE posts
+			SET status = 'published', published_at = NOW(), publish_at = NULL, version = version + 1
+			WHERE id IN (
+				SELECT id FROM posts
+				WHERE status = 'scheduled' AND publish_at <= NOW() AND deleted_at IS NULL
+				ORDER BY publish_at
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			) AND status = 'scheduled'
+			RETURNING id, version, title, content, tags
+		)
+		INSERT INTO post_revisions (post_id, version, title, content, tags)
+		SELECT id, version, title, content, tags FROM published
 	`
 	ctx, cancel := withTimeout(ctx)
 	defer cancel()
-	return s.db.QueryRowContext(
-		ctx,
-		query,
-		post.Title,
-		post.Content,
-		pq.Array(post.Tags),
-		post.ID,
-		post.Version,
-	).Scan(&post.UpdatedAt, &post.Version)
+	res, err := s.db.ExecContext(ctx, query, limit)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
 }
 
 func (s *PostStore) GetFeed(ctx context.Context, userID int64, params *PaginationParams) ([]*FeedablePost, error) {
 	query := `
-		SELECT p.id, p.title, p.content, p.user_id, p.tags, p.created_at, p.updated_at, p.version, u.username,
+		SELECT p.id, p.title, p.content, p.content_html, p.render_version, p.user_id, p.tags, p.created_at, p.updated_at, p.version,
+		       p.status, p.publish_at, p.published_at, u.username,
 		       COUNT(c.id) AS comments_count
 		FROM posts p
-		LEFT JOIN comments c ON p.id = c.post_id
+		LEFT JOIN comments c ON p.id = c.post_id AND c.deleted_at IS NULL
 		LEFT JOIN users u ON p.user_id = u.id
 		WHERE (p.user_id = $1 OR p.user_id IN (
 			SELECT followee_id FROM followers WHERE user_id = $1
 		))
+		AND p.status = 'published' AND p.deleted_at IS NULL
 		AND (p.title ILIKE $2 OR p.content ILIKE $2)
 		AND (p.tags @> $3 OR $3 = '{}')
-		AND ($4 = '' OR p.created_at >= $4::timestamp)
-		AND ($5 = '' OR p.created_at <= $5::timestamp)
+		AND ($4 = '' OR p.published_at >= $4::timestamp)
+		AND ($5 = '' OR p.published_at <= $5::timestamp)
 		GROUP BY p.id, u.username
-		ORDER BY p.created_at ` + params.Sort + `
+		ORDER BY p.published_at ` + params.Sort + `
 		LIMIT $6 OFFSET $7
 	`
 	ctx, cancel := withTimeout(ctx)
@@ -142,17 +465,25 @@ func (s *PostStore) GetFeed(ctx context.Context, userID int64, params *Paginatio
 			&post.ID,
 			&post.Title,
 			&post.Content,
+			&post.ContentHTML,
+			&post.RenderVersion,
 			&post.UserID,
 			pq.Array(&post.Tags),
 			&post.CreatedAt,
 			&post.UpdatedAt,
 			&post.Version,
+			&post.Status,
+			&post.PublishAt,
+			&post.PublishedAt,
 			&post.Username,
 			&post.CommentsCount,
 		)
 		if err != nil {
 			return nil, err
 		}
+		if err := renderStale(&post.Post); err != nil {
+			return nil, err
+		}
 		feed = append(feed, post)
 	}
 	if err = rows.Err(); err != nil {
@@ -0,0 +1,32 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/samuel032khoury/gopherfeed/internal/markdown"
+)
+
+func TestRenderStale(t *testing.T) {
+	t.Run("should render posts from an older renderer", func(t *testing.T) {
+		post := &Post{Content: "some *content*", RenderVersion: markdown.Version - 1}
+		if err := renderStale(post); err != nil {
+			t.Fatal(err)
+		}
+		if want := "<p>some <em>content</em></p>\n"; post.ContentHTML != want {
+			t.Errorf("expected content_html %q; got %q", want, post.ContentHTML)
+		}
+		if post.RenderVersion != markdown.Version {
+			t.Errorf("expected render version %d; got %d", markdown.Version, post.RenderVersion)
+		}
+	})
+
+	t.Run("should keep HTML from the current renderer", func(t *testing.T) {
+		post := &Post{Content: "some *content*", ContentHTML: "<p>cached</p>", RenderVersion: markdown.Version}
+		if err := renderStale(post); err != nil {
+			t.Fatal(err)
+		}
+		if post.ContentHTML != "<p>cached</p>" {
+			t.Errorf("expected the stored HTML to be kept; got %q", post.ContentHTML)
+		}
+	})
+}
@@ -0,0 +1,108 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+)
+
+// PostRevision is a post's title, content and tags as saved at one version
+//
+//	@Description	Saved version of a post
+type PostRevision struct {
+	PostID  int64    `json:"post_id" example:"1"`
+	Version int      `json:"version" example:"3"`
+	Title   string   `json:"title" example:"My First Post"`
+	Content string   `json:"content" example:"This is the content of my first post"`
+	Tags    []string `json:"tags" example:"golang,api"`
+	// EditorID is who saved the revision; nil when the scheduler published it
+	// or the revision was saved before history was kept
+	EditorID  *int64 `json:"editor_id" example:"1"`
+	CreatedAt string `json:"created_at" example:"2026-01-06T07:22:18Z"`
+}
+
+func (s *PostStore) createRevision(ctx context.Context, tx *sql.Tx, post *Post, editorID int64) error {
+	query := `
+		INSERT INTO post_revisions (post_id, version, title, content, tags, editor_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err := tx.ExecContext(
+		ctx,
+		query,
+		post.ID,
+		post.Version,
+		post.Title,
+		post.Content,
+		pq.Array(post.Tags),
+		editorID,
+	)
+	return err
+}
+
+// ListRevisions returns a page of the post's revisions by version.
+func (s *PostStore) ListRevisions(ctx context.Context, postID int64, filter *RevisionFilter) ([]*PostRevision, error) {
+	query := `
+		SELECT post_id, version, title, content, tags, editor_id, created_at
+		FROM post_revisions
+		WHERE post_id = $1
+		ORDER BY version ` + filter.Sort + `
+		LIMIT $2 OFFSET $3
+	`
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	rows, err := s.db.QueryContext(ctx, query, postID, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []*PostRevision{}
+	for rows.Next() {
+		revision := &PostRevision{}
+		err := rows.Scan(
+			&revision.PostID,
+			&revision.Version,
+			&revision.Title,
+			&revision.Content,
+			pq.Array(&revision.Tags),
+			&revision.EditorID,
+			&revision.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+func (s *PostStore) GetRevision(ctx context.Context, postID int64, version int) (*PostRevision, error) {
+	query := `
+		SELECT post_id, version, title, content, tags, editor_id, created_at
+		FROM post_revisions
+		WHERE post_id = $1 AND version = $2
+	`
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	revision := &PostRevision{}
+	err := s.db.QueryRowContext(ctx, query, postID, version).Scan(
+		&revision.PostID,
+		&revision.Version,
+		&revision.Title,
+		&revision.Content,
+		pq.Array(&revision.Tags),
+		&revision.EditorID,
+		&revision.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return revision, nil
+}
@@ -3,13 +3,25 @@ package store
 import (
 	"context"
 	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrDuplicateRole     = errors.New("role with that name already exists")
+	ErrUnknownPermission = errors.New("unknown permission")
 )
 
+// Role represents a named set of permissions
+//
+//	@Description	Role with its granted permissions
 type Role struct {
-	ID          int64  `json:"id"`
-	Name        string `json:"name"`
-	Level       int    `json:"level"`
-	Description string `json:"description"`
+	ID          int64    `json:"id" example:"2"`
+	Name        string   `json:"name" example:"moderator"`
+	Level       int      `json:"level" example:"4"`
+	Description string   `json:"description" example:"Moderator with elevated privileges"`
+	Permissions []string `json:"permissions,omitempty" example:"post.update.any,comment.delete.any"`
 }
 
 type RoleStore struct {
@@ -18,7 +30,7 @@ type RoleStore struct {
 
 func (s *RoleStore) GetByID(ctx context.Context, id int64) (*Role, error) {
 	query := `
-		SELECT id, name, level, description
+		SELECT id, name, level, COALESCE(description, '')
 		FROM roles
 		WHERE id = $1
 	`
@@ -33,6 +45,9 @@ func (s *RoleStore) GetByID(ctx context.Context, id int64) (*Role, error) {
 		&role.Description,
 	)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
 		return nil, err
 	}
 	return role, nil
@@ -40,7 +55,7 @@ func (s *RoleStore) GetByID(ctx context.Context, id int64) (*Role, error) {
 
 func (s *RoleStore) GetByName(ctx context.Context, name string) (*Role, error) {
 	query := `
-		SELECT id, name, level, description
+		SELECT id, name, level, COALESCE(description, '')
 		FROM roles
 		WHERE name = $1
 	`
@@ -55,7 +70,159 @@ func (s *RoleStore) GetByName(ctx context.Context, name string) (*Role, error) {
 		&role.Description,
 	)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
 		return nil, err
 	}
 	return role, nil
 }
+
+// GetPermissions returns the names of the permissions granted to a role.
+func (s *RoleStore) GetPermissions(ctx context.Context, roleID int64) ([]string, error) {
+	query := `
+		SELECT p.name
+		FROM permissions p
+		JOIN role_permissions rp ON rp.permission_id = p.id
+		WHERE rp.role_id = $1
+		ORDER BY p.name
+	`
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, roleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	permissions := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, name)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+// List returns every role with its permissions, least privileged first.
+func (s *RoleStore) List(ctx context.Context) ([]*Role, error) {
+	query := `
+		SELECT r.id, r.name, r.level, COALESCE(r.description, ''),
+		       COALESCE(array_agg(p.name ORDER BY p.name) FILTER (WHERE p.name IS NOT NULL), '{}')
+		FROM roles r
+		LEFT JOIN role_permissions rp ON rp.role_id = r.id
+		LEFT JOIN permissions p ON p.id = rp.permission_id
+		GROUP BY r.id
+		ORDER BY r.level, r.id
+	`
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []*Role{}
+	for rows.Next() {
+		role := &Role{}
+		err := rows.Scan(
+			&role.ID,
+			&role.Name,
+			&role.Level,
+			&role.Description,
+			pq.Array(&role.Permissions),
+		)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (s *RoleStore) Create(ctx context.Context, role *Role, audit AuditFunc) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `
+			INSERT INTO roles (name, level, description)
+			VALUES ($1, $2, $3) RETURNING id
+		`
+		ctx, cancel := withTimeout(ctx)
+		defer cancel()
+
+		err := tx.QueryRowContext(ctx, query, role.Name, role.Level, role.Description).Scan(&role.ID)
+		if err != nil {
+			return roleError(err)
+		}
+		if err := s.setPermissions(ctx, tx, role); err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, audit)
+	})
+}
+
+// Update replaces the role's attributes and its permissions, returning
+// ErrNotFound if the role does not exist.
+func (s *RoleStore) Update(ctx context.Context, role *Role, audit AuditFunc) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `UPDATE roles SET name = $1, level = $2, description = $3 WHERE id = $4`
+		ctx, cancel := withTimeout(ctx)
+		defer cancel()
+
+		res, err := tx.ExecContext(ctx, query, role.Name, role.Level, role.Description, role.ID)
+		if err != nil {
+			return roleError(err)
+		}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return ErrNotFound
+		}
+		if err := s.setPermissions(ctx, tx, role); err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, audit)
+	})
+}
+
+func (s *RoleStore) setPermissions(ctx context.Context, tx *sql.Tx, role *Role) error {
+	query := `DELETE FROM role_permissions WHERE role_id = $1`
+	if _, err := tx.ExecContext(ctx, query, role.ID); err != nil {
+		return err
+	}
+	query = `
+		INSERT INTO role_permissions (role_id, permission_id)
+		SELECT $1, id FROM permissions WHERE name = ANY($2)
+	`
+	res, err := tx.ExecContext(ctx, query, role.ID, pq.Array(role.Permissions))
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != int64(len(role.Permissions)) {
+		return ErrUnknownPermission
+	}
+	return nil
+}
+
+func roleError(err error) error {
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		return ErrDuplicateRole
+	}
+	return err
+}
@@ -0,0 +1,339 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Session represents a login session. All refresh tokens rotated from the
+// same login belong to one session, so revoking it logs out the whole family.
+//
+//	@Description	Login session on one device
+type Session struct {
+	ID         string `json:"id" example:"0b6f1b9e-4c2a-4f51-9d1c-3c5e6f7a8b9c"`
+	UserID     int64  `json:"user_id" example:"1"`
+	UserAgent  string `json:"user_agent" example:"Mozilla/5.0 (Macintosh; Intel Mac OS X 14_2) AppleWebKit/605.1.15"`
+	IP         string `json:"ip" example:"203.0.113.7"`
+	AccessJTI  string `json:"-"`
+	CreatedAt  string `json:"created_at" example:"2026-01-06T07:22:18Z"`
+	LastSeenAt string `json:"last_seen_at" example:"2026-01-06T09:41:02Z"`
+	ExpiresAt  string `json:"expires_at" example:"2026-01-13T07:22:18Z"`
+	// ImpersonatorID is the admin acting as the user, if any
+	ImpersonatorID *int64 `json:"impersonator_id,omitempty" example:"2"`
+}
+
+type SessionStore struct {
+	db *sql.DB
+}
+
+var ErrTokenReused = errors.New("refresh token has already been used")
+
+func (s *SessionStore) Create(ctx context.Context, session *Session, token string, exp time.Duration, audit AuditFunc) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `
+			INSERT INTO user_sessions (id, user_id, user_agent, ip, access_jti, impersonator_id, expires_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING created_at, last_seen_at, expires_at
+		`
+		ctx, cancel := withTimeout(ctx)
+		defer cancel()
+
+		err := tx.QueryRowContext(
+			ctx,
+			query,
+			session.ID,
+			session.UserID,
+			session.UserAgent,
+			session.IP,
+			session.AccessJTI,
+			session.ImpersonatorID,
+			time.Now().Add(exp),
+		).Scan(
+			&session.CreatedAt,
+			&session.LastSeenAt,
+			&session.ExpiresAt,
+		)
+		if err != nil {
+			return err
+		}
+		if err := s.createRefreshToken(ctx, tx, token, session.ID, exp); err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, audit)
+	})
+}
+
+// lastSeenInterval bounds how often GetActive writes last_seen_at, so an
+// authenticated request does not cost a row update every time.
+const lastSeenInterval = time.Minute
+
+// GetActive looks up an unrevoked, unexpired session and records that it was
+// seen, at most once per lastSeenInterval.
+func (s *SessionStore) GetActive(ctx context.Context, id string) (*Session, error) {
+	query := `
+		SELECT id, user_id, user_agent, ip, access_jti, created_at, last_seen_at, expires_at, impersonator_id,
+			last_seen_at < $2
+		FROM user_sessions
+		WHERE id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+	`
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	session := &Session{}
+	var stale bool
+	err := s.db.QueryRowContext(ctx, query, id, time.Now().Add(-lastSeenInterval)).Scan(
+		&session.ID,
+		&session.UserID,
+		&session.UserAgent,
+		&session.IP,
+		&session.AccessJTI,
+		&session.CreatedAt,
+		&session.LastSeenAt,
+		&session.ExpiresAt,
+		&session.ImpersonatorID,
+		&stale,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if stale {
+		query = `UPDATE user_sessions SET last_seen_at = NOW() WHERE id = $1 RETURNING last_seen_at`
+		if err := s.db.QueryRowContext(ctx, query, id).Scan(&session.LastSeenAt); err != nil {
+			return nil, err
+		}
+	}
+	return session, nil
+}
+
+func (s *SessionStore) ListActive(ctx context.Context, userID int64) ([]*Session, error) {
+	query := `
+		SELECT id, user_id, user_agent, ip, created_at, last_seen_at, expires_at, impersonator_id
+		FROM user_sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY last_seen_at DESC
+	`
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []*Session{}
+	for rows.Next() {
+		session := &Session{}
+		err := rows.Scan(
+			&session.ID,
+			&session.UserID,
+			&session.UserAgent,
+			&session.IP,
+			&session.CreatedAt,
+			&session.LastSeenAt,
+			&session.ExpiresAt,
+			&session.ImpersonatorID,
+		)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// IsNewDevice reports whether a user who has logged in before has never done
+// so with the given user agent.
+func (s *SessionStore) IsNewDevice(ctx context.Context, userID int64, userAgent string) (bool, error) {
+	query := `
+		SELECT EXISTS (SELECT 1 FROM user_sessions WHERE user_id = $1 AND impersonator_id IS NULL)
+		AND NOT EXISTS (
+			SELECT 1 FROM user_sessions WHERE user_id = $1 AND user_agent = $2 AND impersonator_id IS NULL
+		)
+	`
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var isNew bool
+	err := s.db.QueryRowContext(ctx, query, userID, userAgent).Scan(&isNew)
+	return isNew, err
+}
+
+// Rotate exchanges a refresh token for a new one within the same session and
+// records the ID of the access token issued with it. Presenting a token that
+// was already rotated revokes the whole session and returns ErrTokenReused,
+// since it means the token has leaked.
+func (s *SessionStore) Rotate(ctx context.Context, token, newToken, accessJTI string, exp time.Duration) (*Session, error) {
+	session := &Session{}
+	reused := false
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `
+			SELECT s.id, s.user_id, s.created_at, rt.used_at IS NOT NULL
+			FROM refresh_tokens rt
+			JOIN user_sessions s ON s.id = rt.session_id
+			WHERE rt.token = $1 AND rt.expires_at > NOW() AND s.revoked_at IS NULL
+			FOR UPDATE OF rt
+		`
+		ctx, cancel := withTimeout(ctx)
+		defer cancel()
+
+		var used bool
+		err := tx.QueryRowContext(ctx, query, token).Scan(
+			&session.ID,
+			&session.UserID,
+			&session.CreatedAt,
+			&used,
+		)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrInvalidToken
+			}
+			return err
+		}
+		if used {
+			// The revocation must be committed, so the reuse is reported
+			// after the transaction rather than by failing it.
+			reused = true
+			return s.revoke(ctx, tx, session.ID)
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE refresh_tokens SET used_at = NOW() WHERE token = $1`, token); err != nil {
+			return err
+		}
+		if err := s.createRefreshToken(ctx, tx, newToken, session.ID, exp); err != nil {
+			return err
+		}
+		query = `
+			UPDATE user_sessions SET expires_at = $1, access_jti = $2, last_seen_at = NOW()
+			WHERE id = $3
+			RETURNING user_agent, ip, last_seen_at, expires_at
+		`
+		return tx.QueryRowContext(ctx, query, time.Now().Add(exp), accessJTI, session.ID).Scan(
+			&session.UserAgent,
+			&session.IP,
+			&session.LastSeenAt,
+			&session.ExpiresAt,
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reused {
+		return nil, ErrTokenReused
+	}
+	return session, nil
+}
+
+func (s *SessionStore) Revoke(ctx context.Context, id string, audit AuditFunc) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if err := s.revoke(ctx, tx, id); err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, audit)
+	})
+}
+
+// RevokeForUser revokes one of the user's sessions, returning ErrNotFound if
+// the user has no such active session.
+func (s *SessionStore) RevokeForUser(ctx context.Context, id string, userID int64) error {
+	query := `UPDATE user_sessions SET revoked_at = NOW() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// RevokeImpersonation ends an impersonation session started by the given
+// admin, returning ErrNotFound if there is no such active session.
+func (s *SessionStore) RevokeImpersonation(ctx context.Context, id string, impersonatorID int64, audit AuditFunc) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `
+			UPDATE user_sessions SET revoked_at = NOW()
+			WHERE id = $1 AND impersonator_id = $2 AND revoked_at IS NULL
+		`
+		ctx, cancel := withTimeout(ctx)
+		defer cancel()
+
+		res, err := tx.ExecContext(ctx, query, id, impersonatorID)
+		if err != nil {
+			return err
+		}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return ErrNotFound
+		}
+		return recordAudit(ctx, tx, audit)
+	})
+}
+
+// RevokeOthers revokes every session of the user except keepID.
+func (s *SessionStore) RevokeOthers(ctx context.Context, userID int64, keepID string) error {
+	query := `UPDATE user_sessions SET revoked_at = NOW() WHERE user_id = $1 AND id <> $2 AND revoked_at IS NULL`
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, query, userID, keepID)
+	return err
+}
+
+func (s *SessionStore) RevokeAllForUser(ctx context.Context, userID int64) error {
+	query := `UPDATE user_sessions SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, query, userID)
+	return err
+}
+
+func (s *SessionStore) RevokeByRefreshToken(ctx context.Context, token string) error {
+	query := `
+		UPDATE user_sessions SET revoked_at = NOW()
+		WHERE revoked_at IS NULL AND id = (SELECT session_id FROM refresh_tokens WHERE token = $1)
+	`
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, query, token)
+	return err
+}
+
+func (s *SessionStore) revoke(ctx context.Context, tx *sql.Tx, id string) error {
+	query := `UPDATE user_sessions SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`
+	ctx, cancel, execer := prepareContext(ctx, s.db, tx)
+	defer cancel()
+
+	_, err := execer.ExecContext(ctx, query, id)
+	return err
+}
+
+func (s *SessionStore) createRefreshToken(ctx context.Context, tx *sql.Tx, token, sessionID string, exp time.Duration) error {
+	query := `
+		INSERT INTO refresh_tokens (token, session_id, expires_at)
+		VALUES ($1, $2, $3)
+	`
+	ctx, cancel, execer := prepareContext(ctx, s.db, tx)
+	defer cancel()
+
+	_, err := execer.ExecContext(ctx, query, token, sessionID, time.Now().Add(exp))
+	return err
+}
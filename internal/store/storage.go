@@ -3,15 +3,19 @@ package store
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"time"
 
-	"github.com/samuel032khoury/gopherfeed/internal/auth"
+	"github.com/samuel032khoury/gopherfeed/internal/password"
+	"go.uber.org/zap"
 )
 
 const (
 	QueryTimeoutDuration = 5 * time.Second
 )
 
+var ErrNotFound = errors.New("resource not found")
+
 // Common database execution interface
 type execer interface {
 	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
@@ -40,21 +44,50 @@ type Storage struct {
 	Posts interface {
 		Create(context.Context, *Post) error
 		GetByID(context.Context, int64) (*Post, error)
-		Delete(context.Context, int64) error
-		Update(context.Context, *Post) error
+		Delete(context.Context, int64, int64, AuditFunc) error
+		ListDeleted(context.Context, *PaginationParams) ([]*Post, error)
+		Restore(context.Context, int64, AuditFunc) error
+		PurgeDeleted(context.Context, time.Duration) (int64, error)
+		Update(context.Context, *Post, int64, AuditFunc) error
 		GetFeed(context.Context, int64, *PaginationParams) ([]*FeedablePost, error)
+		ListDrafts(context.Context, int64, *PaginationParams) ([]*Post, error)
+		PublishDue(context.Context, int) (int64, error)
+		RerenderStale(context.Context, int) (int64, error)
+		ListRevisions(context.Context, int64, *RevisionFilter) ([]*PostRevision, error)
+		GetRevision(context.Context, int64, int) (*PostRevision, error)
 	}
 	Users interface {
 		Create(context.Context, *sql.Tx, *User) error
 		GetByID(context.Context, int64) (*User, error)
 		Register(context.Context, *User, string, time.Duration) error
-		Authenticate(context.Context, string, string, auth.Authenticator) (string, error)
+		Authenticate(context.Context, string, string) (*User, error)
 		Activate(context.Context, string) error
 		Delete(context.Context, int64) error
+		GetByEmail(context.Context, string) (*User, error)
+		CreatePasswordReset(context.Context, int64, string, time.Duration) error
+		GetByPasswordReset(context.Context, string) (*User, error)
+		ResetPassword(context.Context, string, string) (*User, error)
+		ChangePassword(context.Context, int64, string) error
+		RotateInvitation(context.Context, string, string, time.Duration) (*User, error)
+		PurgeUnactivated(context.Context) (int64, error)
+		CreateLoginToken(context.Context, *User, string, time.Duration) error
+		RedeemLoginToken(context.Context, string) (*User, error)
+		CreateEmailChange(context.Context, int64, string, string, time.Duration) error
+		ConfirmEmailChange(context.Context, string) (*User, error)
+		GetByIDIncludingInactive(context.Context, int64) (*User, error)
+		List(context.Context, *UserFilter) ([]*User, error)
+		SetRole(context.Context, int64, int64, AuditFunc) error
+		Deactivate(context.Context, int64, AuditFunc) error
+		Reactivate(context.Context, int64, AuditFunc) error
 	}
 	Comments interface {
 		GetByPostID(context.Context, int64) ([]*Comment, error)
+		GetByID(context.Context, int64) (*Comment, error)
 		Create(context.Context, *Comment) error
+		Delete(context.Context, int64, int64, AuditFunc) error
+		ListDeleted(context.Context, *PaginationParams) ([]*Comment, error)
+		Restore(context.Context, int64, AuditFunc) error
+		PurgeDeleted(context.Context, time.Duration) (int64, error)
 	}
 	Followers interface {
 		Follow(context.Context, int64, int64) error
@@ -63,16 +96,80 @@ type Storage struct {
 	Roles interface {
 		GetByName(context.Context, string) (*Role, error)
 		GetByID(context.Context, int64) (*Role, error)
+		GetPermissions(context.Context, int64) ([]string, error)
+		List(context.Context) ([]*Role, error)
+		Create(context.Context, *Role, AuditFunc) error
+		Update(context.Context, *Role, AuditFunc) error
+	}
+	Sessions interface {
+		Create(context.Context, *Session, string, time.Duration, AuditFunc) error
+		GetActive(context.Context, string) (*Session, error)
+		ListActive(context.Context, int64) ([]*Session, error)
+		IsNewDevice(context.Context, int64, string) (bool, error)
+		Rotate(context.Context, string, string, string, time.Duration) (*Session, error)
+		Revoke(context.Context, string, AuditFunc) error
+		RevokeForUser(context.Context, string, int64) error
+		RevokeOthers(context.Context, int64, string) error
+		RevokeImpersonation(context.Context, string, int64, AuditFunc) error
+		RevokeAllForUser(context.Context, int64) error
+		RevokeByRefreshToken(context.Context, string) error
+	}
+	MFA interface {
+		Get(context.Context, int64) (*MFA, error)
+		Enroll(context.Context, int64, string) error
+		Confirm(context.Context, int64, int64, []string) error
+		UseCounter(context.Context, int64, int64) error
+		UseRecoveryCode(context.Context, int64, string) error
+		ReplaceRecoveryCodes(context.Context, int64, []string) error
+		Disable(context.Context, int64) error
+	}
+	Tokens interface {
+		Create(context.Context, *PersonalAccessToken, string, time.Duration) error
+		GetByToken(context.Context, string) (*PersonalAccessToken, error)
+		ListByUser(context.Context, int64) ([]*PersonalAccessToken, error)
+		Delete(context.Context, int64, int64) error
+	}
+	Identities interface {
+		GetUser(context.Context, string, string) (*User, error)
+		LinkByEmail(context.Context, *Identity) (*User, error)
+		CreateUser(context.Context, *User, *Identity) error
+	}
+	Passkeys interface {
+		Create(context.Context, *Passkey) error
+		ListByUser(context.Context, int64) ([]*Passkey, error)
+		RecordUse(context.Context, int64, int64) error
+		Delete(context.Context, int64, int64) error
+		CreateCeremony(context.Context, *PasskeyCeremony, time.Duration) error
+		ConsumeCeremony(context.Context, string, string) (*PasskeyCeremony, error)
+	}
+	Audit interface {
+		Record(context.Context, *AuditEntry) error
+		List(context.Context, *AuditFilter) ([]*AuditEntry, error)
+		ListAfter(context.Context, *AuditFilter, int64) ([]*AuditEntry, error)
+	}
+	Suspensions interface {
+		Create(context.Context, *Suspension, time.Duration, AuditFunc) error
+		GetActive(context.Context, int64) (*Suspension, error)
+		Lift(context.Context, *Suspension, AuditFunc) error
+		Appeal(context.Context, int64, string, AuditFunc) error
+		LiftExpired(context.Context) ([]*Suspension, error)
 	}
 }
 
-func NewPostgresStorage(db *sql.DB) *Storage {
+func NewPostgresStorage(db *sql.DB, hasher password.Hasher, logger *zap.SugaredLogger) *Storage {
 	return &Storage{
-		Posts:     &PostStore{db: db},
-		Users:     &UserStore{db: db},
-		Comments:  &CommentStore{db: db},
-		Followers: &FollowerStore{db: db},
-		Roles:     &RoleStore{db: db},
+		Posts:       &PostStore{db: db},
+		Users:       &UserStore{db: db, hasher: hasher, logger: logger},
+		Comments:    &CommentStore{db: db},
+		Followers:   &FollowerStore{db: db},
+		Roles:       &RoleStore{db: db},
+		Sessions:    &SessionStore{db: db},
+		MFA:         &MFAStore{db: db},
+		Tokens:      &PersonalAccessTokenStore{db: db},
+		Identities:  &IdentityStore{db: db},
+		Passkeys:    &PasskeyStore{db: db},
+		Audit:       &AuditStore{db: db},
+		Suspensions: &SuspensionStore{db: db},
 	}
 }
 
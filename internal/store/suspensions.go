@@ -0,0 +1,202 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const (
+	// SuspensionScopeReadOnly lets the user sign in and read but not write
+	SuspensionScopeReadOnly = "read_only"
+	// SuspensionScopeFull refuses every sign in and request
+	SuspensionScopeFull = "full"
+)
+
+// Suspension restricts a user's account until it expires or is lifted. A
+// suspension without an expiry is a ban.
+//
+//	@Description	Account suspension issued by a moderator
+type Suspension struct {
+	ID         int64   `json:"id" example:"1"`
+	UserID     int64   `json:"user_id" example:"42"`
+	IssuedBy   *int64  `json:"issued_by" example:"2"`
+	Scope      string  `json:"scope" example:"read_only" enums:"read_only,full"`
+	Reason     string  `json:"reason" example:"Repeated spam in comments"`
+	AppealNote string  `json:"appeal_note" example:"Those links were to my own blog"`
+	AppealedAt *string `json:"appealed_at" example:"2026-01-07T10:12:45Z"`
+	ExpiresAt  *string `json:"expires_at" example:"2026-01-13T07:22:18Z"`
+	LiftedAt   *string `json:"lifted_at" example:"2026-01-08T16:03:11Z"`
+	LiftedBy   *int64  `json:"lifted_by" example:"2"`
+	CreatedAt  string  `json:"created_at" example:"2026-01-06T07:22:18Z"`
+}
+
+type SuspensionStore struct {
+	db *sql.DB
+}
+
+const suspensionColumns = `id, user_id, issued_by, scope, reason, appeal_note, appealed_at, expires_at, lifted_at, lifted_by, created_at`
+
+// activeSuspension matches suspensions still in force. Expired suspensions
+// stop matching on their own, whether or not LiftExpired has run yet.
+const activeSuspension = `lifted_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())`
+
+// Create issues a suspension, replacing any suspension the user is already
+// under. A zero duration suspends the user indefinitely.
+func (s *SuspensionStore) Create(ctx context.Context, suspension *Suspension, duration time.Duration, audit AuditFunc) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := withTimeout(ctx)
+		defer cancel()
+
+		query := `
+			UPDATE user_suspensions SET lifted_at = NOW(), lifted_by = $2
+			WHERE user_id = $1 AND ` + activeSuspension
+		if _, err := tx.ExecContext(ctx, query, suspension.UserID, suspension.IssuedBy); err != nil {
+			return err
+		}
+
+		var expiresAt *time.Time
+		if duration > 0 {
+			exp := time.Now().Add(duration)
+			expiresAt = &exp
+		}
+		query = `
+			INSERT INTO user_suspensions (user_id, issued_by, scope, reason, expires_at)
+			VALUES ($1, $2, $3, $4, $5) RETURNING id, expires_at, created_at
+		`
+		err := tx.QueryRowContext(
+			ctx,
+			query,
+			suspension.UserID,
+			suspension.IssuedBy,
+			suspension.Scope,
+			suspension.Reason,
+			expiresAt,
+		).Scan(
+			&suspension.ID,
+			&suspension.ExpiresAt,
+			&suspension.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, audit)
+	})
+}
+
+// GetActive returns the suspension the user is currently under, or nil.
+func (s *SuspensionStore) GetActive(ctx context.Context, userID int64) (*Suspension, error) {
+	query := `
+		SELECT ` + suspensionColumns + `
+		FROM user_suspensions
+		WHERE user_id = $1 AND ` + activeSuspension + `
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	suspension, err := scanSuspension(s.db.QueryRowContext(ctx, query, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return suspension, nil
+}
+
+// Lift ends the active suspension of suspension.UserID early on behalf of
+// suspension.LiftedBy, filling in the rest of suspension from it.
+func (s *SuspensionStore) Lift(ctx context.Context, suspension *Suspension, audit AuditFunc) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `
+			UPDATE user_suspensions SET lifted_at = NOW(), lifted_by = $2
+			WHERE user_id = $1 AND ` + activeSuspension + `
+			RETURNING ` + suspensionColumns
+		ctx, cancel := withTimeout(ctx)
+		defer cancel()
+
+		lifted, err := scanSuspension(tx.QueryRowContext(ctx, query, suspension.UserID, suspension.LiftedBy))
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrNotFound
+			}
+			return err
+		}
+		*suspension = *lifted
+		return recordAudit(ctx, tx, audit)
+	})
+}
+
+// Appeal records the user's appeal against their active suspension.
+func (s *SuspensionStore) Appeal(ctx context.Context, userID int64, note string, audit AuditFunc) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `
+			UPDATE user_suspensions SET appeal_note = $2, appealed_at = NOW()
+			WHERE user_id = $1 AND ` + activeSuspension
+		ctx, cancel := withTimeout(ctx)
+		defer cancel()
+
+		res, err := tx.ExecContext(ctx, query, userID, note)
+		if err != nil {
+			return err
+		}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return ErrNotFound
+		}
+		return recordAudit(ctx, tx, audit)
+	})
+}
+
+// LiftExpired marks suspensions whose expiry has passed as lifted and
+// returns them, so the users can be told they have been reinstated.
+func (s *SuspensionStore) LiftExpired(ctx context.Context) ([]*Suspension, error) {
+	query := `
+		UPDATE user_suspensions SET lifted_at = expires_at
+		WHERE lifted_at IS NULL AND expires_at <= NOW()
+		RETURNING ` + suspensionColumns
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var suspensions []*Suspension
+	for rows.Next() {
+		suspension, err := scanSuspension(rows)
+		if err != nil {
+			return nil, err
+		}
+		suspensions = append(suspensions, suspension)
+	}
+	return suspensions, rows.Err()
+}
+
+func scanSuspension(row interface{ Scan(...any) error }) (*Suspension, error) {
+	suspension := &Suspension{}
+	err := row.Scan(
+		&suspension.ID,
+		&suspension.UserID,
+		&suspension.IssuedBy,
+		&suspension.Scope,
+		&suspension.Reason,
+		&suspension.AppealNote,
+		&suspension.AppealedAt,
+		&suspension.ExpiresAt,
+		&suspension.LiftedAt,
+		&suspension.LiftedBy,
+		&suspension.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return suspension, nil
+}
@@ -0,0 +1,132 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// PersonalAccessToken represents a named, scoped API token
+//
+//	@Description	Personal access token metadata; the secret is only returned on creation
+type PersonalAccessToken struct {
+	ID         int64    `json:"id" example:"1"`
+	UserID     int64    `json:"user_id" example:"1"`
+	Name       string   `json:"name" example:"ci-bot"`
+	Scopes     []string `json:"scopes" example:"posts:read,feed:read"`
+	ExpiresAt  string   `json:"expires_at" example:"2026-04-06T07:22:18Z"`
+	LastUsedAt *string  `json:"last_used_at" example:"2026-01-06T07:22:18Z"`
+	CreatedAt  string   `json:"created_at" example:"2026-01-06T07:22:18Z"`
+}
+
+type PersonalAccessTokenStore struct {
+	db *sql.DB
+}
+
+func (s *PersonalAccessTokenStore) Create(ctx context.Context, pat *PersonalAccessToken, token string, exp time.Duration) error {
+	query := `
+		INSERT INTO personal_access_tokens (user_id, name, token, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id, expires_at, created_at
+	`
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	return s.db.QueryRowContext(
+		ctx,
+		query,
+		pat.UserID,
+		pat.Name,
+		token,
+		pq.Array(pat.Scopes),
+		time.Now().Add(exp),
+	).Scan(&pat.ID, &pat.ExpiresAt, &pat.CreatedAt)
+}
+
+// GetByToken looks up an unexpired token by its hash and records its use.
+func (s *PersonalAccessTokenStore) GetByToken(ctx context.Context, token string) (*PersonalAccessToken, error) {
+	query := `
+		UPDATE personal_access_tokens SET last_used_at = NOW()
+		WHERE token = $1 AND expires_at > NOW()
+		RETURNING id, user_id, name, scopes, expires_at, last_used_at, created_at
+	`
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	pat := &PersonalAccessToken{}
+	err := s.db.QueryRowContext(ctx, query, token).Scan(
+		&pat.ID,
+		&pat.UserID,
+		&pat.Name,
+		pq.Array(&pat.Scopes),
+		&pat.ExpiresAt,
+		&pat.LastUsedAt,
+		&pat.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return pat, nil
+}
+
+func (s *PersonalAccessTokenStore) ListByUser(ctx context.Context, userID int64) ([]*PersonalAccessToken, error) {
+	query := `
+		SELECT id, user_id, name, scopes, expires_at, last_used_at, created_at
+		FROM personal_access_tokens
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := []*PersonalAccessToken{}
+	for rows.Next() {
+		pat := &PersonalAccessToken{}
+		err := rows.Scan(
+			&pat.ID,
+			&pat.UserID,
+			&pat.Name,
+			pq.Array(&pat.Scopes),
+			&pat.ExpiresAt,
+			&pat.LastUsedAt,
+			&pat.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, pat)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (s *PersonalAccessTokenStore) Delete(ctx context.Context, id, userID int64) error {
+	query := `DELETE FROM personal_access_tokens WHERE id = $1 AND user_id = $2`
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -6,10 +6,10 @@ import (
 	"errors"
 	"time"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/lib/pq"
-	"github.com/samuel032khoury/gopherfeed/internal/auth"
+	"github.com/samuel032khoury/gopherfeed/internal/password"
 	"github.com/samuel032khoury/gopherfeed/internal/utils"
+	"go.uber.org/zap"
 )
 
 // User represents a user in the system
@@ -23,10 +23,14 @@ type User struct {
 	CreatedAt string `json:"created_at" example:"2026-01-06T07:22:18Z"`
 	IsActive  bool   `json:"is_active" example:"false"`
 	RoleID    int64  `json:"role_id" example:"1"`
+	// DeactivatedAt is set while an administrator has deactivated the account
+	DeactivatedAt *string `json:"deactivated_at" example:"2026-01-08T10:00:00Z"`
 }
 
 type UserStore struct {
-	db *sql.DB
+	db     *sql.DB
+	hasher password.Hasher
+	logger *zap.SugaredLogger
 }
 
 var (
@@ -34,6 +38,7 @@ var (
 	ErrDuplicateUsername  = errors.New("user with that username already exists")
 	ErrInvalidCredentials = errors.New("invalid email or password")
 	ErrInvalidToken       = errors.New("invalid or expired token")
+	ErrUserDeactivated    = errors.New("account deactivated")
 )
 
 func (s *UserStore) getUserFromInvitation(ctx context.Context, token string) (*User, error) {
@@ -41,7 +46,7 @@ func (s *UserStore) getUserFromInvitation(ctx context.Context, token string) (*U
 		SELECT u.id, u.username, u.email, u.created_at, u.is_active
 		FROM users u
 		JOIN user_invitations ui ON u.id = ui.user_id
-		WHERE ui.token = $1 AND ui.expires_at > NOW()
+		WHERE ui.token = $1 AND ui.expires_at > NOW() AND u.deactivated_at IS NULL
 	`
 	ctx, cancel := withTimeout(ctx)
 	defer cancel()
@@ -133,7 +138,7 @@ func (s *UserStore) GetByID(ctx context.Context, id int64) (*User, error) {
 	query := `
 		SELECT id, username, email, password_hash, created_at, is_active, role_id
 		FROM users
-		WHERE id = $1 AND is_active = TRUE
+		WHERE id = $1 AND is_active = TRUE AND deactivated_at IS NULL
 	`
 	ctx, cancel := withTimeout(ctx)
 	defer cancel()
@@ -157,6 +162,128 @@ func (s *UserStore) GetByID(ctx context.Context, id int64) (*User, error) {
 	return user, nil
 }
 
+// GetByIDIncludingInactive is GetByID for administration, where accounts
+// that are not active must be reachable as well.
+func (s *UserStore) GetByIDIncludingInactive(ctx context.Context, id int64) (*User, error) {
+	query := `
+		SELECT id, username, email, password_hash, created_at, is_active, role_id, deactivated_at
+		FROM users
+		WHERE id = $1
+	`
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	user := &User{}
+	err := s.db.QueryRowContext(ctx, query, id).Scan(
+		&user.ID,
+		&user.Username,
+		&user.Email,
+		&user.Password,
+		&user.CreatedAt,
+		&user.IsActive,
+		&user.RoleID,
+		&user.DeactivatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// List returns users matching the filter, oldest first.
+func (s *UserStore) List(ctx context.Context, filter *UserFilter) ([]*User, error) {
+	query := `
+		SELECT u.id, u.username, u.email, u.created_at, u.is_active, u.role_id, u.deactivated_at
+		FROM users u
+		JOIN roles r ON r.id = u.role_id
+		WHERE ($1 = '' OR r.name = $1)
+		AND ($2::boolean IS NULL OR (u.is_active AND u.deactivated_at IS NULL) = $2)
+		AND (u.username ILIKE $3 OR u.email ILIKE $3)
+		ORDER BY u.id
+		LIMIT $4 OFFSET $5
+	`
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	searchTerm := "%" + filter.Search + "%"
+	rows, err := s.db.QueryContext(ctx, query, filter.Role, filter.Active, searchTerm, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []*User{}
+	for rows.Next() {
+		user := &User{}
+		err := rows.Scan(
+			&user.ID,
+			&user.Username,
+			&user.Email,
+			&user.CreatedAt,
+			&user.IsActive,
+			&user.RoleID,
+			&user.DeactivatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (s *UserStore) SetRole(ctx context.Context, userID, roleID int64, audit AuditFunc) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `UPDATE users SET role_id = $1 WHERE id = $2`
+		ctx, cancel := withTimeout(ctx)
+		defer cancel()
+
+		if _, err := tx.ExecContext(ctx, query, roleID, userID); err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, audit)
+	})
+}
+
+// Deactivate blocks the user from signing in until Reactivate is called.
+// Unlike an account waiting for activation, the user cannot lift it.
+func (s *UserStore) Deactivate(ctx context.Context, userID int64, audit AuditFunc) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `UPDATE users SET deactivated_at = NOW() WHERE id = $1 AND deactivated_at IS NULL`
+		ctx, cancel := withTimeout(ctx)
+		defer cancel()
+
+		if _, err := tx.ExecContext(ctx, query, userID); err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, audit)
+	})
+}
+
+// Reactivate lifts a deactivation. An account still waiting for activation
+// is activated as well, vouching for its email on the user's behalf.
+func (s *UserStore) Reactivate(ctx context.Context, userID int64, audit AuditFunc) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `UPDATE users SET is_active = TRUE, deactivated_at = NULL WHERE id = $1`
+		ctx, cancel := withTimeout(ctx)
+		defer cancel()
+
+		if _, err := tx.ExecContext(ctx, query, userID); err != nil {
+			return err
+		}
+		if err := s.deleteUserInvitation(ctx, tx, userID); err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, audit)
+	})
+}
+
 func (s *UserStore) Register(ctx context.Context, user *User, token string, exp time.Duration) error {
 	return withTx(s.db, ctx, func(tx *sql.Tx) error {
 		if err := s.Create(ctx, tx, user); err != nil {
@@ -169,41 +296,63 @@ func (s *UserStore) Register(ctx context.Context, user *User, token string, exp
 	})
 }
 
-func (s *UserStore) Authenticate(ctx context.Context, email, password string, authenticator auth.Authenticator) (string, error) {
+func (s *UserStore) Authenticate(ctx context.Context, email, password string) (*User, error) {
 	query := `
-		SELECT id, username, password_hash
+		SELECT id, username, email, password_hash, created_at, is_active, role_id
 		FROM users
-		WHERE email = $1 AND is_active = TRUE
+		WHERE email = $1 AND is_active = TRUE AND deactivated_at IS NULL
 	`
-	ctx, cancel := withTimeout(ctx)
+	// The lookup gets its own timeout so that a slow hash check does not
+	// eat into the rehash below
+	queryCtx, cancel := withTimeout(ctx)
 	defer cancel()
 
-	var id int64
-	var username, passwordHash string
-	err := s.db.QueryRowContext(ctx, query, email).Scan(&id, &username, &passwordHash)
+	user := &User{}
+	err := s.db.QueryRowContext(queryCtx, query, email).Scan(
+		&user.ID,
+		&user.Username,
+		&user.Email,
+		&user.Password,
+		&user.CreatedAt,
+		&user.IsActive,
+		&user.RoleID,
+	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return "", ErrInvalidCredentials
+			return nil, ErrInvalidCredentials
 		}
-		return "", err
+		return nil, err
 	}
-	if !utils.CheckPasswordHash(password, passwordHash) {
-		return "", ErrInvalidCredentials
+	match, needsRehash, err := s.hasher.Verify(password, user.Password)
+	if err != nil {
+		return nil, err
 	}
-	exp, iss, aud := authenticator.GetMetadata()
-	claims := jwt.MapClaims{
-		"sub": id,
-		"exp": time.Now().Add(exp).Unix(),
-		"iat": time.Now().Unix(),
-		"nbf": time.Now().Unix(),
-		"iss": iss,
-		"aud": aud,
+	if !match {
+		return nil, ErrInvalidCredentials
 	}
-	token, err := authenticator.GenerateToken(claims)
-	if err != nil {
-		return "", err
+	if needsRehash {
+		// Upgrade legacy hashes while the plaintext is at hand. A failure
+		// here must not fail the login, it is retried on the next one.
+		hash, err := s.hasher.Hash(password)
+		if err == nil {
+			err = s.updatePasswordHash(ctx, user.ID, user.Password, hash)
+		}
+		if err != nil {
+			s.logger.Warnw("failed to upgrade password hash", "user_id", user.ID, "error", err)
+		}
 	}
-	return token, nil
+	return user, nil
+}
+
+// updatePasswordHash replaces the hash only if it is unchanged, so a
+// concurrent password reset is never overwritten.
+func (s *UserStore) updatePasswordHash(ctx context.Context, userID int64, oldHash, newHash string) error {
+	query := `UPDATE users SET password_hash = $1 WHERE id = $2 AND password_hash = $3`
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, query, newHash, userID, oldHash)
+	return err
 }
 
 func (s *UserStore) Activate(ctx context.Context, token string) error {
@@ -223,6 +372,348 @@ func (s *UserStore) Activate(ctx context.Context, token string) error {
 	})
 }
 
+func (s *UserStore) GetByEmail(ctx context.Context, email string) (*User, error) {
+	query := `
+		SELECT id, username, email, password_hash, created_at, is_active, role_id
+		FROM users
+		WHERE email = $1 AND is_active = TRUE AND deactivated_at IS NULL
+	`
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	user := &User{}
+	err := s.db.QueryRowContext(ctx, query, email).Scan(
+		&user.ID,
+		&user.Username,
+		&user.Email,
+		&user.Password,
+		&user.CreatedAt,
+		&user.IsActive,
+		&user.RoleID,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// CreatePasswordReset stores a reset token for the user, replacing any
+// outstanding one so only the most recently mailed link works.
+func (s *UserStore) CreatePasswordReset(ctx context.Context, userID int64, token string, exp time.Duration) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if err := s.deletePasswordResets(ctx, tx, userID); err != nil {
+			return err
+		}
+		query := `
+			INSERT INTO password_resets (token, user_id, expires_at)
+			VALUES ($1, $2, $3)
+		`
+		ctx, cancel := withTimeout(ctx)
+		defer cancel()
+
+		_, err := tx.ExecContext(ctx, query, token, userID, time.Now().Add(exp))
+		return err
+	})
+}
+
+func (s *UserStore) ResetPassword(ctx context.Context, token, passwordHash string) (*User, error) {
+	user := &User{}
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		// Deleting the token first means that of two concurrent resets with
+		// it, only one finds it
+		query := `DELETE FROM password_resets WHERE token = $1 AND expires_at > NOW() RETURNING user_id`
+		ctx, cancel := withTimeout(ctx)
+		defer cancel()
+
+		if err := tx.QueryRowContext(ctx, query, utils.Hash(token)).Scan(&user.ID); err != nil {
+			if err == sql.ErrNoRows {
+				return ErrInvalidToken
+			}
+			return err
+		}
+		query = `
+			SELECT username, email, created_at, is_active, role_id
+			FROM users
+			WHERE id = $1 AND is_active = TRUE AND deactivated_at IS NULL
+		`
+		err := tx.QueryRowContext(ctx, query, user.ID).Scan(
+			&user.Username,
+			&user.Email,
+			&user.CreatedAt,
+			&user.IsActive,
+			&user.RoleID,
+		)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrInvalidToken
+			}
+			return err
+		}
+		user.Password = passwordHash
+		if err := s.updatePassword(ctx, tx, user); err != nil {
+			return err
+		}
+		return s.deletePasswordResets(ctx, tx, user.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetByPasswordReset returns the active user an unexpired reset token was
+// issued to, or ErrInvalidToken.
+func (s *UserStore) GetByPasswordReset(ctx context.Context, token string) (*User, error) {
+	query := `
+		SELECT u.id, u.username, u.email, u.created_at, u.is_active, u.role_id
+		FROM users u
+		JOIN password_resets pr ON u.id = pr.user_id
+		WHERE pr.token = $1 AND pr.expires_at > NOW() AND u.is_active = TRUE AND u.deactivated_at IS NULL
+	`
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tokenHash := utils.Hash(token)
+	user := &User{}
+	err := s.db.QueryRowContext(ctx, query, tokenHash).Scan(
+		&user.ID,
+		&user.Username,
+		&user.Email,
+		&user.CreatedAt,
+		&user.IsActive,
+		&user.RoleID,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// ChangePassword sets a new password hash and voids outstanding reset links.
+func (s *UserStore) ChangePassword(ctx context.Context, userID int64, passwordHash string) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		user := &User{ID: userID, Password: passwordHash}
+		if err := s.updatePassword(ctx, tx, user); err != nil {
+			return err
+		}
+		return s.deletePasswordResets(ctx, tx, userID)
+	})
+}
+
+func (s *UserStore) updatePassword(ctx context.Context, tx *sql.Tx, user *User) error {
+	query := `UPDATE users SET password_hash = $1 WHERE id = $2`
+	ctx, cancel, execer := prepareContext(ctx, s.db, tx)
+	defer cancel()
+
+	_, err := execer.ExecContext(ctx, query, user.Password, user.ID)
+	return err
+}
+
+func (s *UserStore) deletePasswordResets(ctx context.Context, tx *sql.Tx, userID int64) error {
+	query := `DELETE FROM password_resets WHERE user_id = $1`
+	ctx, cancel, execer := prepareContext(ctx, s.db, tx)
+	defer cancel()
+
+	_, err := execer.ExecContext(ctx, query, userID)
+	return err
+}
+
+// CreateLoginToken stores a single-use login token bound to the user's
+// current email, replacing any outstanding one.
+func (s *UserStore) CreateLoginToken(ctx context.Context, user *User, token string, exp time.Duration) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if err := s.deleteLoginTokens(ctx, tx, user.ID); err != nil {
+			return err
+		}
+		query := `
+			INSERT INTO login_tokens (token, user_id, email, expires_at)
+			VALUES ($1, $2, $3, $4)
+		`
+		ctx, cancel := withTimeout(ctx)
+		defer cancel()
+
+		_, err := tx.ExecContext(ctx, query, token, user.ID, user.Email, time.Now().Add(exp))
+		return err
+	})
+}
+
+// RedeemLoginToken consumes a login token and returns its user. The token is
+// rejected if the user's email has changed since it was issued.
+func (s *UserStore) RedeemLoginToken(ctx context.Context, token string) (*User, error) {
+	query := `
+		WITH redeemed AS (
+			DELETE FROM login_tokens WHERE token = $1 RETURNING user_id, email, expires_at
+		)
+		SELECT u.id, u.username, u.email, u.password_hash, u.created_at, u.is_active, u.role_id
+		FROM users u
+		JOIN redeemed r ON u.id = r.user_id
+		WHERE r.email = u.email AND r.expires_at > NOW() AND u.is_active = TRUE AND u.deactivated_at IS NULL
+	`
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	user := &User{}
+	err := s.db.QueryRowContext(ctx, query, token).Scan(
+		&user.ID,
+		&user.Username,
+		&user.Email,
+		&user.Password,
+		&user.CreatedAt,
+		&user.IsActive,
+		&user.RoleID,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *UserStore) deleteLoginTokens(ctx context.Context, tx *sql.Tx, userID int64) error {
+	query := `DELETE FROM login_tokens WHERE user_id = $1`
+	ctx, cancel, execer := prepareContext(ctx, s.db, tx)
+	defer cancel()
+
+	_, err := execer.ExecContext(ctx, query, userID)
+	return err
+}
+
+// CreateEmailChange stores a pending change to newEmail, replacing any
+// outstanding one. The address is only swapped by ConfirmEmailChange.
+func (s *UserStore) CreateEmailChange(ctx context.Context, userID int64, newEmail, token string, exp time.Duration) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `DELETE FROM email_changes WHERE user_id = $1`
+		ctx, cancel := withTimeout(ctx)
+		defer cancel()
+
+		if _, err := tx.ExecContext(ctx, query, userID); err != nil {
+			return err
+		}
+		query = `
+			INSERT INTO email_changes (token, user_id, new_email, expires_at)
+			VALUES ($1, $2, $3, $4)
+		`
+		_, err := tx.ExecContext(ctx, query, token, userID, newEmail, time.Now().Add(exp))
+		return err
+	})
+}
+
+// ConfirmEmailChange consumes a pending email change and swaps the user's
+// address. Password reset and login links sent to the old address are voided.
+// ErrDuplicateEmail is returned if the address was taken in the meantime.
+func (s *UserStore) ConfirmEmailChange(ctx context.Context, token string) (*User, error) {
+	user := &User{}
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `
+			WITH confirmed AS (
+				DELETE FROM email_changes
+				WHERE token = $1 AND expires_at > NOW()
+				RETURNING user_id, new_email
+			)
+			UPDATE users u SET email = c.new_email
+			FROM confirmed c
+			WHERE u.id = c.user_id AND u.is_active = TRUE AND u.deactivated_at IS NULL
+			RETURNING u.id, u.username, u.email, u.password_hash, u.created_at, u.is_active, u.role_id
+		`
+		ctx, cancel := withTimeout(ctx)
+		defer cancel()
+
+		err := tx.QueryRowContext(ctx, query, token).Scan(
+			&user.ID,
+			&user.Username,
+			&user.Email,
+			&user.Password,
+			&user.CreatedAt,
+			&user.IsActive,
+			&user.RoleID,
+		)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrInvalidToken
+			}
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+				return ErrDuplicateEmail
+			}
+			return err
+		}
+		if err := s.deletePasswordResets(ctx, tx, user.ID); err != nil {
+			return err
+		}
+		return s.deleteLoginTokens(ctx, tx, user.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// RotateInvitation replaces the invitation of an inactive user with a fresh
+// token. It returns nil when no inactive user has the given email.
+func (s *UserStore) RotateInvitation(ctx context.Context, email, token string, exp time.Duration) (*User, error) {
+	query := `
+		SELECT id, username, email, created_at, is_active, role_id
+		FROM users
+		WHERE email = $1 AND is_active = FALSE AND deactivated_at IS NULL
+	`
+	user := &User{}
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := withTimeout(ctx)
+		defer cancel()
+
+		err := tx.QueryRowContext(ctx, query, email).Scan(
+			&user.ID,
+			&user.Username,
+			&user.Email,
+			&user.CreatedAt,
+			&user.IsActive,
+			&user.RoleID,
+		)
+		if err != nil {
+			return err
+		}
+		if err := s.deleteUserInvitation(ctx, tx, user.ID); err != nil {
+			return err
+		}
+		return s.createUserInvitation(ctx, tx, token, user.ID, exp)
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// PurgeUnactivated deletes inactive users whose invitations have all expired,
+// freeing their email and username for a new registration.
+func (s *UserStore) PurgeUnactivated(ctx context.Context) (int64, error) {
+	query := `
+		DELETE FROM users u
+		WHERE u.is_active = FALSE AND u.deactivated_at IS NULL
+		AND EXISTS (SELECT 1 FROM user_invitations ui WHERE ui.user_id = u.id)
+		AND NOT EXISTS (
+			SELECT 1 FROM user_invitations ui WHERE ui.user_id = u.id AND ui.expires_at > NOW()
+		)
+	`
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
 func (s *UserStore) Delete(ctx context.Context, id int64) error {
 	return withTx(s.db, ctx, func(tx *sql.Tx) error {
 		if err := s.deleteUser(ctx, tx, id); err != nil {
@@ -0,0 +1,107 @@
+// Package textdiff computes line-based differences between two texts.
+package textdiff
+
+import "strings"
+
+// Op says what happened to a line going from the old text to the new one
+type Op string
+
+const (
+	Equal  Op = "equal"
+	Insert Op = "insert"
+	Delete Op = "delete"
+)
+
+// Line is one line of a diff
+type Line struct {
+	Op   Op     `json:"op" example:"insert"`
+	Text string `json:"text" example:"A line that was added"`
+}
+
+// maxCells bounds the size of the longest common subsequence table. Texts
+// whose changed region is larger are diffed as a wholesale replacement.
+const maxCells = 1 << 22
+
+// Lines returns the lines of a and b as a minimal sequence of kept, deleted
+// and inserted lines.
+func Lines(a, b string) []Line {
+	x, y := split(a), split(b)
+
+	// Lines shared at both ends need no table
+	prefix := 0
+	for prefix < len(x) && prefix < len(y) && x[prefix] == y[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(x)-prefix && suffix < len(y)-prefix && x[len(x)-1-suffix] == y[len(y)-1-suffix] {
+		suffix++
+	}
+
+	diff := make([]Line, 0, len(x)+len(y))
+	for _, text := range x[:prefix] {
+		diff = append(diff, Line{Op: Equal, Text: text})
+	}
+	diff = append(diff, middle(x[prefix:len(x)-suffix], y[prefix:len(y)-suffix])...)
+	for _, text := range x[len(x)-suffix:] {
+		diff = append(diff, Line{Op: Equal, Text: text})
+	}
+	return diff
+}
+
+// middle diffs two texts with no common first or last line
+func middle(x, y []string) []Line {
+	n, m := len(x), len(y)
+	diff := make([]Line, 0, n+m)
+	if (n+1)*(m+1) > maxCells {
+		for _, text := range x {
+			diff = append(diff, Line{Op: Delete, Text: text})
+		}
+		for _, text := range y {
+			diff = append(diff, Line{Op: Insert, Text: text})
+		}
+		return diff
+	}
+
+	// lcs[i*(m+1)+j] is the length of the longest common subsequence of
+	// x[i:] and y[j:]. maxCells keeps every length within a uint16.
+	lcs := make([]uint16, (n+1)*(m+1))
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i*(m+1)+j] = lcs[(i+1)*(m+1)+j+1] + 1
+			} else {
+				lcs[i*(m+1)+j] = max(lcs[(i+1)*(m+1)+j], lcs[i*(m+1)+j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case x[i] == y[j]:
+			diff = append(diff, Line{Op: Equal, Text: x[i]})
+			i++
+			j++
+		case lcs[(i+1)*(m+1)+j] >= lcs[i*(m+1)+j+1]:
+			diff = append(diff, Line{Op: Delete, Text: x[i]})
+			i++
+		default:
+			diff = append(diff, Line{Op: Insert, Text: y[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		diff = append(diff, Line{Op: Delete, Text: x[i]})
+	}
+	for ; j < m; j++ {
+		diff = append(diff, Line{Op: Insert, Text: y[j]})
+	}
+	return diff
+}
+
+func split(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+}
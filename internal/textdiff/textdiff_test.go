@@ -0,0 +1,59 @@
+package textdiff
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []Line
+	}{
+		{"identical", "one\ntwo", "one\ntwo", []Line{{Equal, "one"}, {Equal, "two"}}},
+		{"from empty", "", "one", []Line{{Insert, "one"}}},
+		{"to empty", "one", "", []Line{{Delete, "one"}}},
+		{
+			"changed line",
+			"one\ntwo\nthree",
+			"one\n2\nthree",
+			[]Line{{Equal, "one"}, {Delete, "two"}, {Insert, "2"}, {Equal, "three"}},
+		},
+		{
+			"moved line",
+			"a\nb\nc\nd",
+			"b\nc\na\nd",
+			[]Line{{Delete, "a"}, {Equal, "b"}, {Equal, "c"}, {Insert, "a"}, {Equal, "d"}},
+		},
+		{"windows line endings", "one\r\ntwo", "one\ntwo", []Line{{Equal, "one"}, {Equal, "two"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Lines(tt.a, tt.b); !slices.Equal(got, tt.want) {
+				t.Errorf("Lines(%q, %q) = %v; want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinesReconstructsBothTexts(t *testing.T) {
+	a := strings.Repeat("keep\nold\n", 50)
+	b := strings.Repeat("new\nkeep\n", 50)
+	var before, after []string
+	for _, line := range Lines(a, b) {
+		if line.Op != Insert {
+			before = append(before, line.Text)
+		}
+		if line.Op != Delete {
+			after = append(after, line.Text)
+		}
+	}
+	if got := strings.Join(before, "\n"); got != a {
+		t.Errorf("old text not reconstructed: %q", got)
+	}
+	if got := strings.Join(after, "\n"); got != b {
+		t.Errorf("new text not reconstructed: %q", got)
+	}
+}
@@ -4,34 +4,44 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"time"
+)
 
-	"golang.org/x/crypto/bcrypt"
+const (
+	InvitationTokenExpiry = 24 * time.Hour // 24 hours in seconds
+	RefreshTokenExpiry    = 7 * 24 * time.Hour
+	PasswordResetExpiry   = time.Hour
+	MFAChallengeExpiry    = 5 * time.Minute
+	OIDCFlowExpiry        = 10 * time.Minute
+	MagicLinkExpiry       = 15 * time.Minute
+	ImpersonationExpiry   = 30 * time.Minute
+	EmailChangeExpiry     = 24 * time.Hour
 )
 
-const InvitationTokenExpiry = 24 * time.Hour // 24 hours in seconds
+func Hash(input string) string {
+	hash := sha256.Sum256([]byte(input))
+	return hex.EncodeToString(hash[:])
+}
 
-func EncryptPassword(password string) (string, error) {
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
-		return "", err
-	}
-	return string(hash), nil
+func GenerateActivationURL(frontendBaseURL, token string, isProdEnv bool) string {
+	return generateFrontendURL(frontendBaseURL, "/activate", token, isProdEnv)
 }
 
-func CheckPasswordHash(password, hash string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err == nil
+func GeneratePasswordResetURL(frontendBaseURL, token string, isProdEnv bool) string {
+	return generateFrontendURL(frontendBaseURL, "/reset-password", token, isProdEnv)
 }
 
-func Hash(input string) string {
-	hash := sha256.Sum256([]byte(input))
-	return hex.EncodeToString(hash[:])
+func GenerateMagicLinkURL(frontendBaseURL, token string, isProdEnv bool) string {
+	return generateFrontendURL(frontendBaseURL, "/magic-link", token, isProdEnv)
 }
 
-func GenerateActivationURL(frontendBaseURL, token string, isProdEnv bool) string {
+func GenerateEmailChangeURL(frontendBaseURL, token string, isProdEnv bool) string {
+	return generateFrontendURL(frontendBaseURL, "/confirm-email", token, isProdEnv)
+}
+
+func generateFrontendURL(frontendBaseURL, path, token string, isProdEnv bool) string {
 	scheme := "http"
 	if isProdEnv {
 		scheme = "https"
 	}
-	return scheme + "://" + frontendBaseURL + "/activate?token=" + token
-}
\ No newline at end of file
+	return scheme + "://" + frontendBaseURL + path + "?token=" + token
+}